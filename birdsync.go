@@ -4,16 +4,25 @@
 package main
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"log"
+	"maps"
 	"os"
+	"path/filepath"
 	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Sajmani/birdsync/ebird"
 	"github.com/Sajmani/birdsync/inat"
+	"github.com/Sajmani/birdsync/reconcile"
 	"github.com/google/uuid"
 )
 
@@ -26,12 +35,45 @@ var (
 	fuzzy              bool
 	before             dateTimeFlag
 	after              dateTimeFlag
+	timeZone           timeZoneFlag
 	positionalAccuracy int
+	locationAccuracy   bool
+	reportFilename     string
+	unresolvedFilename string
+	retryFilename      string
+	submissions        string
+	tripReport         string
+	dedupeShared       bool
+	inatLogin          bool
+	cacheFilename      string
+	protocolFieldID    int
+	externalIDFieldID  int
+	presenceFieldID    int
+	project            string
+	observerName       string
+	sourceNote         string
+	mediaOrder         string
+	subspecies         string
+	maxMedia           int
+	mlAttribution      bool
+	mlWorkers          int
+	validateExport     bool
+
+	includeObservationDetails bool
+	includeChecklistLink      bool
+	includeChecklistComments  bool
+	includeCompleteness       bool
+	skipIncomplete            bool
+	checkNames                bool
+	checkIDs                  bool
 )
 
 func init() {
 	flag.BoolVar(&debug, "debug", false,
 		"Log verbosely")
+	flag.BoolVar(&inatLogin, "inat_login", false,
+		"Sign in to iNaturalist in your browser instead of pasting an API token, using the iNaturalist OAuth application "+
+			"whose ID is in INAT_CLIENT_ID. The sign-in is saved, and birdsync gets new API tokens as needed.")
 	flag.BoolVar(&dryRun, "dryrun", false,
 		"Don't actually sync any observations, just log what birdsync would do")
 	flag.BoolVar(&verifiable, "verifiable", true,
@@ -44,8 +86,132 @@ func init() {
 		"Sync only observations observed before the provided DateTime (2006-01-02 15:04:05). The time can be omitted (2006-01-02).")
 	flag.Var(&after, "after",
 		"Sync only observations observed after the provided DateTime (2006-01-02 15:04:05). The time can be omitted (2006-01-02).")
+	flag.BoolVar(&dedupeShared, "shared_checklists", true,
+		"Skip observations on copies of shared eBird checklists when the same species was already synced "+
+			"from another observer's copy, so group outings don't create duplicate iNaturalist observations.")
+	flag.BoolVar(&checkNames, "check_names", false,
+		"Before creating each iNaturalist observation, look up the eBird scientific name on iNaturalist "+
+			"and warn if the eBird common name doesn't match the taxon's common name. "+
+			"This catches corrupted exports but makes an extra API call per species.")
+	flag.BoolVar(&checkIDs, "check_ids", false,
+		"Report previously synced iNaturalist observations whose community ID differs from the observation's own taxon.")
+	flag.Var(&timeZone, "time_zone",
+		"Time zone of all eBird observation times, like America/New_York. "+
+			"By default, birdsync uses the time zone of the checklist's state or province if it's all in one zone; "+
+			"otherwise, iNaturalist chooses each observation's time zone from its location.")
 	flag.IntVar(&positionalAccuracy, "positional_accuracy_meters", ebird.PositionalAccuracy,
-		"Positional accuracy in meters of the iNaturalist observations created by birdsync.")
+		"Positional accuracy in meters of the iNaturalist observations created by birdsync. "+
+			"The distance traveled is added to this for traveling checklists.")
+	flag.BoolVar(&locationAccuracy, "location_accuracy", false,
+		"Look up each checklist location with the eBird API (which needs EBIRD_API_KEY) "+
+			"and use --positional_accuracy_meters only for hotspots, "+
+			fmt.Sprintf("and %d meters for personal locations, which are usually where the birds were seen.", ebird.PersonalLocationAccuracy))
+	flag.BoolVar(&includeObservationDetails, "observation_details", true,
+		"Include eBird observation details in iNaturalist observation descriptions.")
+	flag.StringVar(&observerName, "observer", "",
+		"Your name as it appears in per-observer notes on shared checklists (\"Name: note\"). "+
+			"If set, birdsync includes only your notes from the eBird observation details when it can tell them apart.")
+	flag.BoolVar(&includeChecklistLink, "checklist_link", true,
+		"Include a link to the eBird checklist in iNaturalist observation descriptions.")
+	flag.BoolVar(&includeChecklistComments, "checklist_comments", true,
+		"Include eBird checklist comments in iNaturalist observation descriptions.")
+	flag.BoolVar(&includeCompleteness, "completeness", true,
+		"Note in iNaturalist observation descriptions whether the eBird checklist was complete (all species reported).")
+	flag.BoolVar(&skipIncomplete, "skip_incomplete", false,
+		"Sync only observations from complete eBird checklists (all species reported), skipping incidental and other partial lists.")
+	flag.StringVar(&sourceNote, "source_note", defaultSourceNote,
+		"Attribution line at the end of iNaturalist observation descriptions. If empty, it's omitted.")
+	flag.IntVar(&protocolFieldID, "protocol_field_id", 0,
+		"iNaturalist observation field ID in which to record the eBird protocol. If zero, the protocol is only included in the description.")
+	flag.IntVar(&presenceFieldID, "presence_field_id", 0,
+		"iNaturalist observation field ID in which to record \"yes\" for birds that were present but not counted (eBird count \"X\"). "+
+			"If set, such observations don't get a count; if zero, their count is \"X\".")
+	flag.StringVar(&subspecies, "subspecies", subspeciesSpecies,
+		"Taxon for species-level eBird observations where iNaturalist has subspecies. "+
+			"One of \"species\" (leave the choice to iNaturalist, which uses the species) or "+
+			"\"subspecies\" (look up the species and use its subspecies if there's only one).")
+	flag.StringVar(&mediaOrder, "media_order", mediaOrderEBird,
+		"Order in which to upload photos and sounds; iNaturalist uses the first photo as the cover photo. "+
+			"One of \"ebird\" (the order in eBird), \"reverse\", or \"id\" (ascending Macaulay Library asset ID).")
+	flag.IntVar(&maxMedia, "max_media", defaultMaxMedia,
+		"Maximum number of photos and sounds per iNaturalist observation, including any it already has. "+
+			"Extra Macaulay Library assets are listed in the description but not uploaded. If zero, there's no limit.")
+	flag.BoolVar(&mlAttribution, "ml_attribution", false,
+		"Credit the photographer or recordist and license of each uploaded photo and sound in iNaturalist observation descriptions. "+
+			"This makes an extra Macaulay Library request per photo or sound.")
+	flag.IntVar(&mlWorkers, "ml_workers", defaultMLWorkers,
+		"Maximum number of photos and sounds to download from the Macaulay Library at once for each observation.")
+	flag.StringVar(&ebird.MLTempDir, "tmpdir", "",
+		"Directory for photos and sounds downloaded from the Macaulay Library. Defaults to the system temporary directory.")
+	flag.IntVar(&ebird.MLMaxAttempts, "ml_attempts", ebird.MLMaxAttempts,
+		"Maximum number of times to try downloading each photo or sound from the Macaulay Library "+
+			"when it fails with a server error or a broken connection.")
+	flag.StringVar(&ebird.MLCacheDir, "ml_cache", "",
+		"Directory in which to keep photos and sounds downloaded from the Macaulay Library between runs, "+
+			"so repeated and resumed runs don't download them again. If empty, there's no cache.")
+	flag.IntVar(&inat.DailyRequestLimit, "inat_daily_limit", inat.DailyRequestLimit,
+		"Maximum number of iNaturalist API requests to make in a day (UTC), as iNaturalist asks; 0 means no limit. "+
+			"Observations that would exceed it fail, so you can finish with --retry_failed the next day.")
+	flag.IntVar(&inat.DownloadWorkers, "inat_download_workers", inat.DownloadWorkers,
+		"Maximum number of pages of iNaturalist observations to download at once, within the rate limit.")
+	flag.IntVar(&inat.MaxAttempts, "inat_attempts", inat.MaxAttempts,
+		"Maximum number of times to try each iNaturalist API request when iNaturalist throttles it "+
+			"or fails with a server error or a broken connection.")
+	flag.DurationVar(&ebird.MLDownloadTimeout, "ml_timeout", ebird.MLDownloadTimeout,
+		"Maximum time to spend downloading each photo or sound from the Macaulay Library.")
+	flag.IntVar(&externalIDFieldID, "external_id_field_id", 0,
+		"iNaturalist observation field ID in which to record an external reference ID for each observation. "+
+			"The ID is the eBird submission ID and scientific name, like S123[Turdus migratorius].")
+	flag.StringVar(&project, "project", "",
+		"iNaturalist project, by ID or by the last part of its URL, to which to add each created observation.")
+	flag.StringVar(&reportFilename, "report", "",
+		"Write a JSON report of the sync results to the provided file.")
+	flag.StringVar(&cacheFilename, "cache", "",
+		"Cache iNaturalist taxon lookups (made by --check_names and --subspecies) in the provided file between runs.")
+	flag.BoolVar(&validateExport, "validate", false,
+		"Check every eBird observation for problems such as missing IDs, bad dates or coordinates, and impossible counts "+
+			"before syncing, and stop without syncing if there are any.")
+	flag.BoolVar(&ebird.StrictHeader, "strict_header", false,
+		"Stop if the eBird export has columns birdsync doesn't know or lacks columns it expects, "+
+			"as when eBird changes its format. Otherwise unknown columns are logged and missing ones are read as empty.")
+	flag.StringVar(&submissions, "submissions", "",
+		"Sync only the observations from these comma-separated eBird checklist submission IDs, like S123,S456.")
+	flag.StringVar(&tripReport, "trip_report", "",
+		"Sync only the checklists in this eBird trip report, like https://ebird.org/tripreport/123456, "+
+			"reading them with the eBird API (which needs EBIRD_API_KEY) instead of an export. "+
+			"The API doesn't include photos or sounds, so this turns off --verifiable.")
+	flag.StringVar(&retryFilename, "retry_failed", "",
+		"Sync only the eBird observations that failed in the --report written by a previous run.")
+	flag.StringVar(&unresolvedFilename, "unresolved", "",
+		"Write the eBird scientific names of synced observations that iNaturalist couldn't match to a taxon to the provided file. "+
+			"The file extension (.csv, .json, or .txt) selects the format.")
+}
+
+// externalID returns the external reference ID recorded in the
+// --external_id_field_id observation field of each created observation.
+// Programs that link iNaturalist observations to their own databases
+// can replace it.
+var externalID = func(rec ebird.Record) string {
+	return rec.ObservationID().String()
+}
+
+// retryIDs, if non-nil, restricts syncing to these eBird observations.
+// It's set from the --retry_failed report.
+var retryIDs map[ebird.ObservationID]bool
+
+// now returns the current time. Tests may replace it for reproducible results.
+var now = time.Now
+
+// atExit, if set, runs before fatalf exits, such as to save the --cache
+// with the lookups made so far.
+var atExit func()
+
+// fatalf is like log.Fatalf, but runs atExit first.
+func fatalf(format string, args ...any) {
+	if atExit != nil {
+		atExit()
+	}
+	log.Fatalf(format, args...)
 }
 
 func debugf(format string, args ...any) {
@@ -62,16 +228,38 @@ func prettyPrintln(v any) {
 	fmt.Println(string(b))
 }
 
-type stats struct {
-	afterSkips, beforeSkips, verifiableSkips, previouslySkips, fuzzySkips int
-	totalRecords, createdObservations, updatedObservations                int
-	uploadedPhotos, uploadedSounds                                        int
+// tripReportDefaults adjusts the flags for --trip_report. Trip report
+// records have no photos or sounds, so --verifiable, which is on by
+// default, would skip all of them; it's turned off unless it was set
+// explicitly, which is an error.
+func tripReportDefaults() error {
+	explicit := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "verifiable" {
+			explicit = true
+		}
+	})
+	if explicit && verifiable {
+		return errors.New("--trip_report records have no photos or sounds, so --verifiable would skip all of them")
+	}
+	verifiable = false
+	return nil
 }
 
 func main() {
 	flag.Parse()
-	if len(flag.Args()) != 1 {
-		log.Println("usage: birdsync MyEBirdData.csv")
+	if tripReport != "" {
+		if len(flag.Args()) != 0 {
+			log.Fatal("--trip_report replaces the eBird export; don't pass both")
+		}
+		if _, err := ebird.ParseTripReportID(tripReport); err != nil {
+			log.Fatalf("Bad --trip_report: %v", err)
+		}
+		if err := tripReportDefaults(); err != nil {
+			log.Fatal(err)
+		}
+	} else if len(flag.Args()) != 1 {
+		log.Println("usage: birdsync MyEBirdData.csv, or birdsync --trip_report URL")
 		flag.Usage()
 		os.Exit(1)
 	}
@@ -80,43 +268,166 @@ func main() {
 			after.Time(), before.Time())
 	}
 
+	if !validMediaOrder(mediaOrder) {
+		log.Fatalf("Unknown --media_order %q", mediaOrder)
+	}
+
+	if strings.Contains(sourceNote, "macaulaylibrary.org/asset/") || strings.Contains(sourceNote, inat.DescriptionMarker) {
+		log.Fatalf("--source_note can't include Macaulay Library asset URLs or %q", inat.DescriptionMarker)
+	}
+
+	if !validSubspecies(subspecies) {
+		log.Fatalf("Unknown --subspecies %q", subspecies)
+	}
+
+	if unresolvedFilename != "" && !slices.Contains([]string{"csv", "json", "txt"}, unresolvedFormat()) {
+		log.Fatalf("--unresolved file must end in .csv, .json, or .txt: %s", unresolvedFilename)
+	}
+	if retryFilename != "" {
+		ids, err := failedIDs(retryFilename)
+		if err != nil {
+			log.Fatalf("Can't read --retry_failed report: %v", err)
+		}
+		log.Printf("Retrying %d failed eBird observations from %s", len(ids), retryFilename)
+		retryIDs = ids
+	}
+	if !dryRun {
+		if err := ebird.CheckMLTempDir(); err != nil {
+			log.Fatalf("%v; use --tmpdir to choose another directory", err)
+		}
+	}
 	eBirdCSVFilename := flag.Arg(0)
-	if f, err := os.Open(eBirdCSVFilename); err != nil {
+	if tripReport != "" {
+		eBirdCSVFilename = tripReport
+	} else if f, err := os.Open(eBirdCSVFilename); err != nil {
 		log.Fatalf("Can't open %s: %v", eBirdCSVFilename, err)
 	} else {
 		f.Close()
 	}
 
-	inatAPIClient := inatClientImpl{
-		client: inat.NewClient(inat.BaseURL, inat.GetAPIToken(), UserAgent),
+	var inatAPIClient inatClientImpl
+	if inatLogin {
+		inatAPIClient.client = oauthClient()
+	} else {
+		inatAPIClient.client = inat.NewClient(inat.BaseURL, inat.GetAPIToken(), UserAgent)
 	}
 	ebirdAPIClient := ebirdClientImpl{}
+	if locationAccuracy || tripReport != "" {
+		key := ebird.GetAPIKey()
+		if key == "" {
+			log.Fatal("--location_accuracy and --trip_report need an eBird API key in EBIRD_API_KEY")
+		}
+		ebirdAPIClient.api = ebird.NewAPIClient(ebird.APIBaseURL, key, UserAgent)
+	}
+	if cacheFilename != "" {
+		if err := inatAPIClient.client.LoadCache(cacheFilename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Ignoring cache: %v", err)
+		}
+		atExit = func() {
+			if err := inatAPIClient.client.SaveCache(cacheFilename); err != nil {
+				log.Printf("Can't save cache: %v", err)
+			}
+		}
+	}
 
 	stats := birdsync(eBirdCSVFilename, ebirdAPIClient, inat.GetUserID(), inatAPIClient)
 
-	log.Printf("Finished processing %d eBird observations", stats.totalRecords)
-	log.Printf("Skipped %d previously uploaded by birdsync", stats.previouslySkips)
-	if fuzzy {
-		log.Printf("Skipped %d eBird observations with --fuzzy matching", stats.fuzzySkips)
+	log.Print("Finished syncing\n" + stats.report())
+	log.Printf("Made %d iNaturalist API requests today", inatAPIClient.client.RequestsToday())
+	if atExit != nil {
+		atExit()
+	}
+	if unresolvedFilename != "" {
+		f, err := os.Create(unresolvedFilename)
+		if err != nil {
+			log.Fatalf("Can't write unresolved taxa: %v", err)
+		}
+		err = ebird.WriteUnresolved(f, unresolvedFormat(), stats.unresolved)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			log.Fatalf("Can't write unresolved taxa: %v", err)
+		}
 	}
-	if !after.Time().IsZero() {
-		log.Printf("Skipped %d eBird observations before --after", stats.afterSkips)
+	if reportFilename != "" {
+		b, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(reportFilename, b, 0644); err != nil {
+			log.Fatalf("Can't write report: %v", err)
+		}
 	}
-	if !before.Time().IsZero() {
-		log.Printf("Skipped %d eBird observations after --before", stats.beforeSkips)
+}
+
+// oauthClient returns the iNaturalist client for --inat_login. It uses the
+// saved access token, or if there isn't one, signs in with the browser
+// and saves the new token.
+func oauthClient() *inat.Client {
+	path, err := inat.DefaultAccessTokenFile()
+	if err != nil {
+		log.Fatalf("Can't find where to save the iNaturalist sign-in: %v", err)
 	}
-	if verifiable {
-		log.Printf("Skipped %d unverifiable eBird observations", stats.verifiableSkips)
+	token, err := inat.LoadAccessToken(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Signing in to iNaturalist again: %v", err)
+		}
+		cfg := inat.OAuthConfig{
+			ClientID:     os.Getenv("INAT_CLIENT_ID"),
+			ClientSecret: os.Getenv("INAT_CLIENT_SECRET"),
+		}
+		if cfg.ClientID == "" {
+			log.Fatal("--inat_login needs the ID of your iNaturalist OAuth application in INAT_CLIENT_ID")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		token, err = cfg.Authorize(ctx, func(authURL string) error {
+			log.Printf("Sign in to iNaturalist and approve birdsync at\n%s", authURL)
+			return nil
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := inat.SaveAccessToken(path, token); err != nil {
+			log.Printf("Couldn't save the iNaturalist sign-in; you'll need to sign in again next time: %v", err)
+		}
 	}
-	log.Printf("Created %d new iNaturalist observations", stats.createdObservations)
-	log.Printf("Updated %d iNaturalist observations", stats.updatedObservations)
-	log.Printf("Uploaded %d photos to iNaturalist", stats.uploadedPhotos)
-	log.Printf("Uploaded %d sounds to iNaturalist", stats.uploadedSounds)
+	return inat.NewOAuthClient(inat.BaseURL, token, UserAgent)
+}
+
+// unresolvedFormat returns the ebird.WriteUnresolved format for --unresolved.
+func unresolvedFormat() string {
+	return strings.TrimPrefix(filepath.Ext(unresolvedFilename), ".")
 }
 
 func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID string, inatClient inatClient) stats {
-	results := inatClient.DownloadObservations(inatUserID, after.Time(), before.Time(),
-		"description", "observed_on", "photos.all", "sounds.all", "taxon.all", "ofvs.all")
+	ctx := context.Background()
+	if !dryRun {
+		// Check the API token before downloading observations or
+		// reading the export, both of which can take a while.
+		if err := inatClient.Ping(ctx); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	var projectID int
+	if project != "" {
+		p, err := inatClient.LookupProject(project)
+		if err != nil {
+			fatalf("Bad --project: %v", err)
+		}
+		log.Printf("Adding created observations to %s (%s)", p.Title, p.URL())
+		projectID = p.ID
+	}
+	fields := append(slices.Clone(inat.DedupFields), "photos.all", "sounds.all")
+	if checkIDs {
+		fields = append(fields, inat.CommunityFields...)
+	}
+	results, err := inatClient.DownloadObservations(inatUserID, after.Time(), before.Time(), fields...)
+	if err != nil {
+		fatalf("Can't download iNaturalist observations: %v", err)
+	}
 
 	previouslySynced := map[ebird.ObservationID]inat.Result{}
 	type fuzzyKey struct {
@@ -124,13 +435,22 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 		name         string
 	}
 	fuzzyMatch := map[fuzzyKey][]string{}
+	sharedSynced := map[ebird.SharedKey]inat.Result{} // by observations on shared checklists
+	unresolved := map[string]bool{}                   // eBird scientific names without iNaturalist taxa
+	var diverged []divergedID
 	for _, r := range results {
-		key := ebird.ObservationID{
-			SubmissionID:   r.ObservationFieldValue(inat.EBirdField),
-			ScientificName: r.ObservationFieldValue(inat.EBirdScientificNameField),
-		}
+		key := reconcile.ResultObservationID(r)
 		if key.Valid() {
 			previouslySynced[key] = r
+			if k, ok := reconcile.FromINatResult(r).SharedKey(); ok {
+				sharedSynced[k] = r
+			}
+			if r.Taxon.ID == 0 {
+				unresolved[key.ScientificName] = true
+			}
+			if checkIDs && r.CommunityDisagrees() {
+				diverged = append(diverged, divergedID{key, r.URL(), r.Taxon.Name, r.CommunityTaxon.Name})
+			}
 		} else {
 			// This iNaturalist observation was not created by birdsync.
 			// Record its date and common name for fuzzy matching.
@@ -150,16 +470,61 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 	debugf("Previously synced %d observations\n", len(previouslySynced))
 
 	log.Printf("Reading eBird observations from %s", eBirdCSVFilename)
-	records, err := ebirdClient.Records(eBirdCSVFilename)
+	records, err := ebirdClient.Records(ctx, eBirdCSVFilename)
 	if err != nil {
-		log.Fatal(err)
+		fatalf("%v", err)
+	}
+	// Read the records once and keep them in memory, since the checks
+	// below and the sync each go over them, and the sync needs them
+	// in a known order, regardless of how eBird ordered the export.
+	recs := slices.Collect(records)
+	if ascending, ok := ebird.DetectOrder(slices.Values(recs)); !ascending || !ok {
+		ebird.SortForSync(recs)
+	}
+	records = slices.Values(recs)
+	if validateExport {
+		summary := ebird.ValidateRecords(records)
+		if len(summary.Issues) > 0 {
+			fatalf("%sFix these records in eBird and export again, or sync without --validate", summary)
+		}
+		log.Printf("Validated %d eBird observations and found no issues", summary.Records)
+	}
+	if warning := sanityCheckExport(records, results); warning != "" {
+		log.Printf("WARNING: %s", warning)
+	}
+	var sharedCopies map[ebird.ObservationID]ebird.ObservationID
+	if dedupeShared {
+		sharedCopies = ebird.SharedDuplicates(records)
+	}
+	if submissions != "" {
+		records = ebird.RecordsForSubmissions(records, strings.Split(submissions, ",")...)
+	}
+	if retryIDs != nil {
+		records = onlyRecords(records, retryIDs)
+	}
+	if dryRun {
+		log.Printf("DRYRUN: eBird observations reference %d Macaulay Library assets",
+			ebird.CountMLAssets(records))
+		days, streak := ebird.BirdingDays(records)
+		log.Printf("DRYRUN: eBird observations are from %d days, including a streak of %d consecutive days",
+			days, streak)
 	}
 	var s stats
+	s.unresolved = slices.Sorted(maps.Keys(unresolved))
+	s.divergedIDs = diverged
 	for rec := range records {
 		s.totalRecords++
 		observed, err := rec.Observed()
 		if err != nil {
-			log.Fatalf("line %d: bad date/time: %v", rec.Line, err)
+			fatalf("line %d: bad date/time: %v", rec.Line, err)
+		}
+		if !rec.PlausibleDateAt(now()) {
+			log.Printf("line %d: WARNING: %s was observed on implausible date %s; check for a typo in eBird",
+				rec.Line, rec.URLWithSpecies(), rec.Date)
+		}
+		if rec.SuspiciousCoordinates() {
+			log.Printf("line %d: WARNING: %s has suspicious coordinates (%s, %s); check its location in eBird",
+				rec.Line, rec.URLWithSpecies(), rec.Latitude, rec.Longitude)
 		}
 		// Skip records that were not observed between --after and --before.
 		if !after.Time().IsZero() && observed.Before(after.Time()) {
@@ -175,11 +540,56 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 			continue
 		}
 
+		if skipIncomplete && !rec.AllObsCompleted() {
+			debugf("line %d: SKIPPING record from incomplete checklist (--skip_incomplete=true)", rec.Line)
+			s.incompleteSkips++
+			continue
+		}
+
+		key := rec.ObservationID()
+
+		// updateDescription replaces the description of observation obs.UUID
+		// with obs.Description.
+		updateDescription := func(obs inat.Observation) error {
+			if dryRun {
+				log.Printf("DRYRUN: Updating observation %s\n", obs.URLWithSpecies())
+				prettyPrintln(obs)
+				return nil
+			}
+			if err := inatClient.UpdateObservation(obs); err != nil {
+				log.Printf("UpdateObservation %s: %v", obs.URLWithSpecies(), err)
+				return err
+			}
+			return nil
+		}
+
 		// addMedia uploads the Maculay Library assets in assetIDs to iNaturalist
-		// then appends the asset URLs to the description of observation u.
-		addMedia := func(u uuid.UUID, desc string, assetIDs mlAssetSet) {
+		// then appends the asset URLs to desc and makes it the description of
+		// observation u, which already has existing photos and sounds.
+		// If there are no assets to upload, addMedia updates the description
+		// only if descChanged. It reports whether it updated the observation.
+		// If an asset fails, addMedia stops uploading but still lists the
+		// assets it uploaded in the description, so that a later sync doesn't
+		// upload them again, and returns the first error.
+		addMedia := func(u uuid.UUID, desc string, descChanged bool, existing int, assetIDs mlAssetSet) (updated bool, err error) {
+			assetIDs = orderMLAssets(assetIDs, mediaOrder)
+			var omitted mlAssetSet
+			if maxMedia > 0 {
+				assetIDs, omitted = capMLAssets(assetIDs, maxMedia-existing)
+			}
+			if omitted.Len() > 0 {
+				log.Printf("line %d: Not uploading %d media assets over --max_media=%d: %s",
+					rec.Line, omitted.Len(), maxMedia, omitted)
+				s.skippedMedia += omitted.Len()
+			}
 			if assetIDs.Len() == 0 {
-				return
+				if !descChanged {
+					return false, nil
+				}
+				if err := updateDescription(inat.Observation{UUID: u, Description: desc}); err != nil {
+					return false, err
+				}
+				return true, nil
 			}
 			debugf("Adding %d media assets to %s\n",
 				assetIDs.Len(), inat.ObservationURL(u))
@@ -188,20 +598,83 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 				UUID:        u,
 				Description: desc,
 			}
-			// Upload the media
-			for _, id := range assetIDs.ids {
-				obs.Description += "Macaulay Library Asset: " + mlAssetURL(id) + "\n"
+			// Download the media concurrently, since the Macaulay Library
+			// can be slow, but upload them one at a time, in order.
+			// iNaturalist orders photos by upload, and the first is the
+			// cover photo, so don't upload these concurrently.
+			var downloads []ebird.MLDownload
+			if !dryRun {
+				downloads = ebirdClient.DownloadMLAssets(ctx, assetIDs.ids, mlWorkers, func(d ebird.MLDownload, done, total int) {
+					if total > 1 {
+						log.Printf("line %d: Downloaded ML asset %s (%d of %d)", rec.Line, d.ID, done, total)
+					}
+				})
+				// Remove the downloads once they're uploaded, or not,
+				// if an asset fails; the cache keeps its own copies.
+				defer ebirdClient.RemoveMLDownloads(downloads)
+			}
+			var mediaErr error // the first asset that failed
+			for i, id := range assetIDs.ids {
 				if dryRun {
 					log.Printf("DRYRUN: Download ML Asset %s and upload to iNaturalist", id)
+					obs.Description += "Macaulay Library Asset: " + mlAssetURL(id) + "\n"
 					s.uploadedPhotos++
 				} else {
-					filename, isPhoto, err := ebirdClient.DownloadMLAsset(id)
+					filename, expected, err := downloads[i].Filename, downloads[i].Kind, downloads[i].Err
+					if err != nil {
+						log.Printf("Couldn't download ML asset %s from eBird: %v", id, err)
+						mediaErr = err
+						break
+					}
+					if expected == ebird.Video {
+						// iNaturalist doesn't accept videos, so link to
+						// them instead, without downloading them. Listing
+						// them in the description also keeps later syncs
+						// from trying again.
+						log.Printf("line %d: iNaturalist doesn't accept videos; linking ML asset %s in the description", rec.Line, id)
+						obs.Description += "Macaulay Library Asset: " + mlAssetURL(id) + "\n"
+						s.linkedVideos++
+						continue
+					}
+					// Check the download before uploading it, and route it
+					// by its detected kind.
+					kind, err := ebirdClient.ValidateMediaFile(filename)
 					if err != nil {
-						log.Fatalf("Couldn't download ML asset %s from eBird: %v", id, err)
+						log.Printf("Downloaded ML asset %s is invalid: %v", id, err)
+						mediaErr = err
+						break
+					}
+					// The download's kind comes from the Macaulay Library's
+					// metadata, or, if that lookup failed, from the URL that
+					// answered. If the file's contents don't match it, such
+					// as a spectrogram image for a sound, don't upload it as
+					// the wrong kind of media.
+					// Skip it, leaving it out of the description so that
+					// a later sync tries again.
+					if kind != expected {
+						err := fmt.Errorf("ML asset %s was downloaded as a %s but contains a %s; skipped it", id, expected, kind)
+						log.Print(err)
+						if mediaErr == nil {
+							mediaErr = err
+						}
+						continue
 					}
+					isPhoto := kind == ebird.Photo
 					err = inatClient.UploadMedia(filename, isPhoto, id, obs.UUID.String())
 					if err != nil {
-						log.Fatalf("Couldn't upload ML asset %s to iNaturalist: %v", id, err)
+						log.Printf("Couldn't upload ML asset %s to iNaturalist: %v", id, err)
+						mediaErr = err
+						break
+					}
+					obs.Description += "Macaulay Library Asset: " + mlAssetURL(id) + "\n"
+					if mlAttribution {
+						// The credit goes on its own line so that it doesn't
+						// change how the asset URL lines are parsed.
+						if info, err := ebirdClient.MLAssetInfo(ctx, id); err != nil {
+							log.Printf("Couldn't get the attribution of ML asset %s: %v", id, err)
+						} else {
+							obs.Description += info.Attribution() + "\n"
+						}
 					}
 					if isPhoto {
 						s.uploadedPhotos++
@@ -210,22 +683,18 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 					}
 				}
 			}
-			// Update the description
-			if dryRun {
-				log.Printf("DRYRUN: Updating observation %s with %d added media assets\n",
-					obs.URLWithSpecies(), assetIDs.Len())
-				prettyPrintln(obs)
-			} else {
-				err = inatClient.UpdateObservation(obs)
-				if err != nil {
-					log.Fatalf("UpdateObservation %s: %v", obs.URLWithSpecies(), err)
-				}
+			if omitted.Len() > 0 {
+				obs.Description += omittedNote(omitted)
 			}
-			s.updatedObservations++
+			// Update the description, even if an asset failed, to record
+			// the assets that were uploaded.
+			if err := updateDescription(obs); err != nil {
+				return false, cmp.Or(mediaErr, err)
+			}
+			return true, mediaErr
 		}
 
 		// Skip records that have previously been uploaded by birdsync.
-		key := rec.ObservationID()
 		if r, ok := previouslySynced[key]; ok {
 			debugf("line %d: Already synced %s to iNaturalist as %s\n",
 				rec.Line, key, r.URLWithSpecies())
@@ -234,14 +703,42 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 				log.Printf("Media assets differ between eBird %s and iNaturalist %s: %s",
 					rec.URLWithSpecies(), r.URLWithSpecies(), summary)
 			}
-			if addedMediaIDs.Len() == 0 {
+			// Append checklist comments added in eBird since the last sync,
+			// preserving any edits made to the description on iNaturalist.
+			desc := r.Description
+			if includeChecklistComments && rec.ChecklistComments != "" && !strings.Contains(desc, rec.ChecklistComments) {
+				desc = inat.AppendDescription(desc, "eBird checklist comments:\n"+rec.ChecklistComments+"\n")
+			}
+			if addedMediaIDs.Len() == 0 && desc == r.Description {
 				s.previouslySkips++
 				continue
 			}
-			addMedia(r.UUID, r.Description, addedMediaIDs)
+			if updated, err := addMedia(r.UUID, desc, desc != r.Description, len(r.Photos)+len(r.Sounds), addedMediaIDs); err != nil {
+				s.fail(key, err)
+			} else if updated {
+				s.updatedObservations++
+			}
 			continue
 		}
 
+		if dedupeShared {
+			// Skip records for the same bird as another observer's copy
+			// of a shared checklist, whether it's in this export or
+			// was synced before.
+			if orig, ok := sharedCopies[key]; ok {
+				log.Printf("line %d: SKIPPING %s: shared checklist copy of %s", rec.Line, key, orig)
+				s.sharedSkips++
+				continue
+			}
+			if k, ok := rec.SharedKey(); ok {
+				if r, ok := sharedSynced[k]; ok {
+					log.Printf("line %d: SKIPPING %s: shared checklist copy already synced as %s", rec.Line, key, r.URLWithSpecies())
+					s.sharedSkips++
+					continue
+				}
+			}
+		}
+
 		if fuzzy {
 			// Skip records for the same bird and date as an existing non-birdsync observation.
 			checkFuzzy := func(name string) bool {
@@ -269,7 +766,7 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 			}
 			f, err := strconv.ParseFloat(s, 64)
 			if err != nil {
-				log.Fatalf("line %d: Invalid float64 %q: %v", line, s, err)
+				fatalf("line %d: Invalid float64 %q: %v", line, s, err)
 			}
 			return f
 		}
@@ -279,17 +776,20 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 				Value:              s,
 			}
 		}
+		countField := keyField(inat.CountField, rec.Count)
+		if _, counted := rec.CountInt(); !counted && presenceFieldID != 0 {
+			countField = keyField(presenceFieldID, "yes")
+		}
 		obs := inat.Observation{
-			UUID:               uuid.New(),
-			CaptiveFlag:        false, // eBird checklists should only include wild birds
-			Latitude:           floatField(rec.Line, rec.Latitude),
-			Longitude:          floatField(rec.Line, rec.Longitude),
-			LocationIsExact:    false,
-			PositionalAccuracy: float64(positionalAccuracy),
-			SpeciesGuess:       rec.ScientificName,
-			ObservedOnString:   rec.Date + " " + rec.Time,
+			UUID:             uuid.New(),
+			CaptiveFlag:      rec.Captive(), // eBird only includes wild birds and escapees
+			Latitude:         floatField(rec.Line, rec.Latitude),
+			Longitude:        floatField(rec.Line, rec.Longitude),
+			LocationIsExact:  false,
+			SpeciesGuess:     rec.ScientificName,
+			ObservedOnString: rec.Date + " " + rec.Time,
 			ObservationFieldValuesAttributes: []inat.ObservationFieldValue{
-				keyField(inat.CountField, rec.Count),
+				countField,
 				keyField(inat.CommonNameField, rec.CommonName),
 				keyField(inat.LocationField, rec.Location),
 				keyField(inat.CountyField, rec.County),
@@ -298,20 +798,38 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 				// EBirdField and EBirdScientificNameField are used to match iNaturalist observations
 				// to the corresponding eBird checklist and species entry. We cannot rely on the taxon
 				// in the iNaturalist observation because it may be changed after upload.
-				keyField(inat.EBirdField, rec.SubmissionID),
+				keyField(inat.EBirdField, key.SubmissionID),
 				keyField(inat.EBirdScientificNameField, rec.ScientificName),
 			},
 		}
-		obs.Description = "Observation created using github.com/Sajmani/birdsync \n"
-		if len(rec.ObservationDetails) > 0 {
-			obs.Description += "eBird observation details:\n" +
-				rec.ObservationDetails + "\n"
+		if protocolFieldID != 0 && rec.Protocol != "" {
+			obs.ObservationFieldValuesAttributes = append(obs.ObservationFieldValuesAttributes,
+				keyField(protocolFieldID, rec.Protocol))
 		}
-		obs.Description += "Checklist: " + rec.URL() + "\n"
-		obs.Description += "Protocol: " + rec.Protocol + "\n"
-		if len(rec.ChecklistComments) > 0 {
-			obs.Description += "eBird checklist comments:\n" +
-				rec.ChecklistComments + "\n"
+		if externalIDFieldID != 0 && externalID != nil {
+			if id := externalID(rec); id != "" {
+				obs.ObservationFieldValuesAttributes = append(obs.ObservationFieldValuesAttributes,
+					keyField(externalIDFieldID, id))
+			}
+		}
+		// Without --time_zone, use the record's time zone if its region
+		// has only one. Otherwise, iNaturalist chooses from the location.
+		loc := timeZone.Location()
+		if loc == nil {
+			loc, _ = rec.RegionTimeZone()
+		}
+		if loc != nil {
+			obs.TimeZone = loc.String()
+			if rec.Time != "" {
+				// Include the offset so iNaturalist doesn't reinterpret the time.
+				if t, err := rec.ObservedIn(loc); err == nil {
+					obs.ObservedOnString = t.Format(time.RFC3339)
+				}
+			}
+		}
+		obs.Description = description(rec)
+		if _, err := rec.MLAssetIDs(); err != nil {
+			log.Printf("WARNING: %v; skipping them", err)
 		}
 		assetIDs := eBirdMLAssets(rec.MLCatalogNumbers)
 		// Skip records without media assets if --verifiable is set.
@@ -320,6 +838,29 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 			s.verifiableSkips++
 			continue
 		}
+		if checkNames {
+			taxon, err := inatClient.LookupTaxon(rec.ScientificName)
+			if err != nil {
+				log.Printf("line %d: Couldn't look up %s on iNaturalist: %v", rec.Line, rec.ScientificName, err)
+			} else if namesDiffer(rec, taxon) {
+				log.Printf("line %d: WARNING: %s has common name %q, but iNaturalist calls %s %q",
+					rec.Line, rec.URL(), rec.CommonName, taxon.Name, taxon.PreferredCommonName)
+				s.nameMismatches = append(s.nameMismatches, nameMismatch{key, rec.CommonName, taxon.PreferredCommonName})
+			}
+		}
+		if subspecies != subspeciesSpecies {
+			matches, err := inatClient.MatchTaxon(rec.ScientificName)
+			if err != nil {
+				log.Printf("line %d: Couldn't look up %s on iNaturalist: %v", rec.Line, rec.ScientificName, err)
+			} else if taxon, ok := chooseTaxon(matches, subspecies); ok {
+				debugf("line %d: using taxon %s for %s (--subspecies=%s)", rec.Line, taxon.Name, rec.ScientificName, subspecies)
+				obs.TaxonID = float64(taxon.ID)
+				obs.SpeciesGuess = taxon.Name
+			}
+		}
+		// Look up the location's accuracy only for records that are synced,
+		// since it may take a request to the eBird API.
+		obs.PositionalAccuracy = float64(rec.Accuracy(ebirdClient.LocationAccuracy(ctx, rec.LocationID, positionalAccuracy)))
 		if dryRun {
 			log.Printf("DRYRUN: Syncing eBird observation %s to iNaturalist (%d media assets)\n",
 				key, assetIDs.Len())
@@ -329,11 +870,88 @@ func birdsync(eBirdCSVFilename string, ebirdClient ebirdClient, inatUserID strin
 				key, assetIDs.Len())
 			err = inatClient.CreateObservation(obs)
 			if err != nil {
-				log.Fatalf("CreateObservation: %v", err)
+				log.Printf("CreateObservation %s: %v", key, err)
+				s.fail(key, err)
+				continue
+			}
+			if projectID != 0 {
+				// The observation exists, so don't fail it; it'd be created again.
+				if err := inatClient.AddObservationToProject(projectID, obs.UUID); err != nil {
+					log.Printf("Couldn't add %s to --project: %v", obs.URL(), err)
+				}
 			}
 		}
+		// A record whose media failed counts only as a failure, since it
+		// needs another sync, which adds the missing media.
+		updated, err := addMedia(obs.UUID, obs.Description, false, 0, assetIDs)
+		if err != nil {
+			s.fail(key, err)
+			continue
+		}
 		s.createdObservations++
-		addMedia(obs.UUID, obs.Description, assetIDs)
+		if updated {
+			s.updatedObservations++
+		}
 	}
 	return s
 }
+
+// namesDiffer reports whether rec's common name differs from the
+// iNaturalist common name of taxon, which should be the taxon for
+// rec's scientific name. Names are compared ignoring case and hyphens,
+// so "Black-capped Chickadee" matches "Black-Capped Chickadee".
+// Unknown taxa and taxa without common names don't differ.
+func namesDiffer(rec ebird.Record, taxon inat.Taxon) bool {
+	if taxon.ID == 0 || taxon.PreferredCommonName == "" || rec.CommonName == "" {
+		return false
+	}
+	norm := func(s string) string {
+		return strings.ReplaceAll(s, "-", " ")
+	}
+	return !strings.EqualFold(norm(rec.CommonName), norm(taxon.PreferredCommonName))
+}
+
+// defaultSourceNote is the default --source_note.
+const defaultSourceNote = "Imported from eBird via birdsync"
+
+// description returns the iNaturalist observation description for rec.
+// The --observation_details, --checklist_link, and --checklist_comments flags
+// control which parts of the eBird record are included.
+// The --source_note ends the description; birdsync appends
+// Macaulay Library asset URLs after it when it uploads media.
+func description(rec ebird.Record) string {
+	desc := "Observation created using github.com/Sajmani/birdsync \n"
+	details := rec.ObservationDetails
+	if observerName != "" {
+		details = ebird.ObserverNotes(details, observerName)
+	}
+	if includeObservationDetails && len(details) > 0 {
+		desc += "eBird observation details:\n" +
+			details + "\n"
+	}
+	if includeChecklistLink {
+		desc += "Checklist: " + rec.URL() + "\n"
+	}
+	desc += "Protocol: " + rec.Protocol + "\n"
+	if code, ok := rec.Breeding(); ok {
+		desc += "Breeding code: " + code.String() + "\n"
+	}
+	if includeCompleteness {
+		if rec.AllObsCompleted() {
+			desc += "Complete checklist: all species reported\n"
+		} else {
+			desc += "Incomplete checklist: not all species reported\n"
+		}
+	}
+	if rec.SpansMidnight() {
+		desc += "Checklist continued past midnight; observed on the start date (" + rec.Date + ").\n"
+	}
+	if includeChecklistComments && len(rec.ChecklistComments) > 0 {
+		desc += "eBird checklist comments:\n" +
+			rec.ChecklistComments + "\n"
+	}
+	if sourceNote != "" {
+		desc += sourceNote + "\n"
+	}
+	return desc
+}
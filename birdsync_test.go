@@ -1,20 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"iter"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Sajmani/birdsync/ebird"
 	"github.com/Sajmani/birdsync/inat"
+	"github.com/Sajmani/birdsync/inat/inattest"
 	"github.com/google/uuid"
 )
 
 type mockEBirdClient struct {
-	records []ebird.Record
+	records    []ebird.Record
+	kinds      map[string]ebird.MediaKind // detected kinds by ML asset ID; default Sound
+	served     map[string]ebird.MediaKind // downloaded kinds by ML asset ID; default Sound
+	accuracies map[string]int             // positional accuracies by location ID; default the hotspot accuracy
+	located    []string                   // LocationAccuracy lookups by location ID
+	removed    []string                   // removed downloads by ML asset ID
 }
 
-func (m *mockEBirdClient) Records(path string) (iter.Seq[ebird.Record], error) {
+func (m *mockEBirdClient) Records(ctx context.Context, path string) (iter.Seq[ebird.Record], error) {
 	return func(yield func(ebird.Record) bool) {
 		for _, r := range m.records {
 			if !yield(r) {
@@ -24,8 +35,49 @@ func (m *mockEBirdClient) Records(path string) (iter.Seq[ebird.Record], error) {
 	}, nil
 }
 
-func (m *mockEBirdClient) DownloadMLAsset(id string) (string, bool, error) {
-	return "", false, nil // isPhoto is false (media are sounds)
+func (m *mockEBirdClient) DownloadMLAsset(id string) (string, ebird.MediaKind, error) {
+	if kind, ok := m.served[id]; ok {
+		return id, kind, nil
+	}
+	return id, ebird.Sound, nil // the filename is the ID
+}
+
+func (m *mockEBirdClient) DownloadMLAssets(ctx context.Context, ids []string, workers int, progress func(ebird.MLDownload, int, int)) []ebird.MLDownload {
+	var downloads []ebird.MLDownload
+	for i, id := range ids {
+		d := ebird.MLDownload{ID: id}
+		d.Filename, d.Kind, d.Err = m.DownloadMLAsset(id)
+		downloads = append(downloads, d)
+		if progress != nil {
+			progress(d, i+1, len(ids))
+		}
+	}
+	return downloads
+}
+
+func (m *mockEBirdClient) RemoveMLDownloads(downloads []ebird.MLDownload) {
+	for _, d := range downloads {
+		m.removed = append(m.removed, d.ID)
+	}
+}
+
+func (m *mockEBirdClient) MLAssetInfo(ctx context.Context, id string) (ebird.MLAssetInfo, error) {
+	return ebird.MLAssetInfo{ID: ebird.MLAssetID(id), Kind: ebird.Sound, Recordist: "Test Recordist", License: "CC BY"}, nil
+}
+
+func (m *mockEBirdClient) LocationAccuracy(ctx context.Context, locationID string, hotspotAccuracy int) int {
+	m.located = append(m.located, locationID)
+	if accuracy, ok := m.accuracies[locationID]; ok {
+		return accuracy
+	}
+	return hotspotAccuracy
+}
+
+func (m *mockEBirdClient) ValidateMediaFile(path string) (ebird.MediaKind, error) {
+	if kind, ok := m.kinds[path]; ok {
+		return kind, nil
+	}
+	return ebird.Sound, nil
 }
 
 type mockINatClient struct {
@@ -35,6 +87,13 @@ type mockINatClient struct {
 	createObsErr   error
 	updateObsErr   error
 	uploadMediaErr error
+	uploadFails    map[string]error // by ML asset ID
+	created        []inat.Observation
+	updated        []inat.Observation
+	taxa           map[string]inat.Taxon   // for LookupTaxon
+	uploaded       []string                // ML asset IDs, in upload order
+	projects       map[string]inat.Project // by ID or slug, for LookupProject
+	projectAdds    []uuid.UUID             // observations added to projects
 }
 
 func (m *mockINatClient) GetUserID() string {
@@ -45,22 +104,66 @@ func (m *mockINatClient) GetAPIToken() string {
 	return m.apitoken
 }
 
-func (m *mockINatClient) DownloadObservations(userID string, after, before time.Time, fields ...string) []inat.Result {
-	return m.observations
+func (m *mockINatClient) DownloadObservations(userID string, after, before time.Time, fields ...string) ([]inat.Result, error) {
+	return m.observations, nil
 }
 
 func (m *mockINatClient) CreateObservation(obs inat.Observation) error {
+	if m.createObsErr == nil {
+		m.created = append(m.created, obs)
+	}
 	return m.createObsErr
 }
 
 func (m *mockINatClient) UpdateObservation(obs inat.Observation) error {
+	if m.updateObsErr == nil {
+		m.updated = append(m.updated, obs)
+	}
 	return m.updateObsErr
 }
 
 func (m *mockINatClient) UploadMedia(filename string, isPhoto bool, assetID, obsUUID string) error {
+	if err := m.uploadFails[assetID]; err != nil {
+		return err
+	}
+	if m.uploadMediaErr == nil {
+		m.uploaded = append(m.uploaded, assetID)
+	}
 	return m.uploadMediaErr
 }
 
+func (m *mockINatClient) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockINatClient) LookupProject(idOrSlug string) (inat.Project, error) {
+	if p, ok := m.projects[idOrSlug]; ok {
+		return p, nil
+	}
+	return inat.Project{}, inat.ErrNotFound
+}
+
+func (m *mockINatClient) AddObservationToProject(projectID int, obsUUID uuid.UUID) error {
+	m.projectAdds = append(m.projectAdds, obsUUID)
+	return nil
+}
+
+func (m *mockINatClient) LookupTaxon(name string) (inat.Taxon, error) {
+	return m.taxa[name], nil
+}
+
+func (m *mockINatClient) MatchTaxon(name string) ([]inat.Taxon, error) {
+	var exact, infra []inat.Taxon
+	for _, t := range m.taxa {
+		if t.Name == name {
+			exact = append(exact, t)
+		} else if strings.HasPrefix(t.Name, name+" ") {
+			infra = append(infra, t)
+		}
+	}
+	return append(exact, infra...), nil
+}
+
 func TestBirdsync(t *testing.T) {
 	origDebug := debug
 	debug = true
@@ -244,8 +347,7 @@ func TestUpdateMedia(t *testing.T) {
 	mockInat := &mockINatClient{userID: "testuser", observations: inatObservations}
 
 	// Reset flags to default
-	after.Set("")
-	before.Set("")
+	after, before = dateTimeFlag{}, dateTimeFlag{}
 	verifiable = false
 	fuzzy = false
 
@@ -269,4 +371,952 @@ func TestUpdateMedia(t *testing.T) {
 	if stats.uploadedSounds != 1 {
 		t.Errorf("Expected 1 uploaded sound, got %d", stats.uploadedSounds)
 	}
-}
\ No newline at end of file
+}
+func TestBirdsyncFailures(t *testing.T) {
+	ebirdRecords := []ebird.Record{
+		{
+			SubmissionID:     "S128",
+			ScientificName:   "Corvus brachyrhynchos",
+			CommonName:       "American Crow",
+			Date:             "2023-01-03",
+			Time:             "03:00 PM",
+			MLCatalogNumbers: "67890",
+		},
+	}
+	mockEbird := &mockEBirdClient{records: ebirdRecords}
+	mockInat := &mockINatClient{userID: "testuser", createObsErr: errors.New("bad HTTP status: 500")}
+
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	stats := birdsync("MyEBirdData.csv", mockEbird, "myUserID", mockInat)
+
+	if stats.createdObservations != 0 {
+		t.Errorf("Expected 0 created observations, got %d", stats.createdObservations)
+	}
+	if len(stats.failures) != 1 {
+		t.Fatalf("Expected 1 failure, got %d", len(stats.failures))
+	}
+	if got := stats.failures[0].id.SubmissionID; got != "S128" {
+		t.Errorf("Expected failure for S128, got %s", got)
+	}
+}
+
+func TestUploadMediaFails(t *testing.T) {
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S128", ScientificName: "Corvus brachyrhynchos", Date: "2023-01-03", MLCatalogNumbers: "100 200 300"},
+	}
+	mockInat := &mockINatClient{userID: "testuser", uploadFails: map[string]error{"200": errors.New("bad HTTP status: 500")}}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	mockEbird := &mockEBirdClient{records: ebirdRecords}
+	stats := birdsync("MyEBirdData.csv", mockEbird, "myUserID", mockInat)
+	if !slices.Equal(mockInat.uploaded, []string{"100"}) {
+		t.Errorf("Uploaded %v, want [100] before the failure", mockInat.uploaded)
+	}
+	// The description lists the uploaded asset so that the next sync
+	// doesn't upload it again.
+	if len(mockInat.updated) != 1 {
+		t.Fatalf("Expected 1 updated description, got %d", len(mockInat.updated))
+	}
+	if desc := mockInat.updated[0].Description; !strings.Contains(desc, mlAssetURL("100")) || strings.Contains(desc, mlAssetURL("200")) {
+		t.Errorf("Description should list asset 100 only:\n%s", desc)
+	}
+	// Every download is removed, including 300, which wasn't uploaded.
+	if !slices.Equal(mockEbird.removed, []string{"100", "200", "300"}) {
+		t.Errorf("Removed downloads %v, want [100 200 300]", mockEbird.removed)
+	}
+	// The record counts once, as a failure.
+	if len(stats.failures) != 1 || stats.createdObservations != 0 || stats.updatedObservations != 0 {
+		t.Errorf("Got %d failures, %d created, %d updated; want 1 failure only",
+			len(stats.failures), stats.createdObservations, stats.updatedObservations)
+	}
+}
+
+func TestDescription(t *testing.T) {
+	defer func() {
+		includeObservationDetails = true
+		includeChecklistLink = true
+		includeChecklistComments = true
+	}()
+	rec := ebird.Record{
+		SubmissionID:       "S123",
+		Protocol:           "Stationary",
+		ObservationDetails: "Perched on a wire",
+		ChecklistComments:  "Windy",
+	}
+	testCases := []struct {
+		name                    string
+		details, link, comments bool
+		wantDetails, wantLink   bool
+		wantComments            bool
+	}{
+		{"all", true, true, true, true, true, true},
+		{"no details", false, true, true, false, true, true},
+		{"no link", true, false, true, true, false, true},
+		{"no comments", true, true, false, true, true, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			includeObservationDetails = tc.details
+			includeChecklistLink = tc.link
+			includeChecklistComments = tc.comments
+			desc := description(rec)
+			if got := strings.Contains(desc, "Perched on a wire"); got != tc.wantDetails {
+				t.Errorf("description has details = %v, want %v:\n%s", got, tc.wantDetails, desc)
+			}
+			if got := strings.Contains(desc, rec.URL()); got != tc.wantLink {
+				t.Errorf("description has link = %v, want %v:\n%s", got, tc.wantLink, desc)
+			}
+			if got := strings.Contains(desc, "Windy"); got != tc.wantComments {
+				t.Errorf("description has comments = %v, want %v:\n%s", got, tc.wantComments, desc)
+			}
+		})
+	}
+}
+
+func TestSourceNote(t *testing.T) {
+	defer func() { sourceNote = defaultSourceNote }()
+	rec := ebird.Record{SubmissionID: "S123", Protocol: "Stationary"}
+	desc := description(rec)
+	if !strings.HasSuffix(desc, defaultSourceNote+"\n") {
+		t.Errorf("description doesn't end with the default source note:\n%s", desc)
+	}
+	sourceNote = "Copied from my eBird checklists"
+	if desc := description(rec); !strings.HasSuffix(desc, "Copied from my eBird checklists\n") {
+		t.Errorf("description doesn't end with the custom source note:\n%s", desc)
+	}
+	sourceNote = ""
+	if desc := description(rec); strings.Contains(desc, defaultSourceNote) {
+		t.Errorf("description includes an empty source note:\n%s", desc)
+	}
+
+	// The note doesn't confuse the parsing of uploaded ML assets.
+	sourceNote = defaultSourceNote
+	r := inat.Result{Description: description(rec) + "Macaulay Library Asset: " + mlAssetURL("123") + "\n"}
+	if got := iNatMLAssets(r).String(); got != "123" {
+		t.Errorf("iNatMLAssets() = %q, want 123", got)
+	}
+}
+
+func TestDescriptionSpansMidnight(t *testing.T) {
+	rec := ebird.Record{
+		SubmissionID: "S123",
+		Protocol:     "Traveling",
+		Date:         "2023-06-10",
+		Time:         "10:30 PM",
+		DurationMin:  "180",
+	}
+	desc := description(rec)
+	if !strings.Contains(desc, "past midnight; observed on the start date (2023-06-10)") {
+		t.Errorf("description doesn't note the overnight checklist:\n%s", desc)
+	}
+	rec.DurationMin = "60"
+	if desc := description(rec); strings.Contains(desc, "midnight") {
+		t.Errorf("description notes midnight for a checklist that ended before it:\n%s", desc)
+	}
+}
+
+func TestDescriptionBreedingCode(t *testing.T) {
+	rec := ebird.Record{SubmissionID: "S123", Protocol: "Stationary", BreedingCode: "FY Feeding Young"}
+	if desc := description(rec); !strings.Contains(desc, "Breeding code: FY Feeding Young (Confirmed)\n") {
+		t.Errorf("description doesn't include the breeding code:\n%s", desc)
+	}
+	rec.BreedingCode = ""
+	if desc := description(rec); strings.Contains(desc, "Breeding code") {
+		t.Errorf("description includes a missing breeding code:\n%s", desc)
+	}
+}
+
+func TestIncompleteChecklists(t *testing.T) {
+	defer func() { skipIncomplete = false }()
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03", AllObsReported: "1"},
+		{SubmissionID: "S2", ScientificName: "Turdus migratorius", Date: "2023-01-04", AllObsReported: "0"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	mockInat := &mockINatClient{userID: "testuser"}
+	stats := birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if stats.createdObservations != 2 {
+		t.Fatalf("Expected 2 created observations, got %d", stats.createdObservations)
+	}
+	if desc := mockInat.created[0].Description; !strings.Contains(desc, "Complete checklist") {
+		t.Errorf("description doesn't note the complete checklist:\n%s", desc)
+	}
+	if desc := mockInat.created[1].Description; !strings.Contains(desc, "Incomplete checklist") {
+		t.Errorf("description doesn't note the incomplete checklist:\n%s", desc)
+	}
+
+	skipIncomplete = true
+	mockInat = &mockINatClient{userID: "testuser"}
+	stats = birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if stats.createdObservations != 1 || stats.incompleteSkips != 1 {
+		t.Errorf("Expected 1 created and 1 skipped observation, got %d and %d",
+			stats.createdObservations, stats.incompleteSkips)
+	}
+}
+
+func TestAppendChecklistComments(t *testing.T) {
+	ebirdRecords := []ebird.Record{
+		{
+			SubmissionID:      "S132",
+			ScientificName:    "Turdus migratorius",
+			Date:              "2023-01-03",
+			ChecklistComments: "Windy",
+		},
+	}
+	inatObservations := []inat.Result{
+		{
+			UUID:        uuid.New(),
+			Description: "Observation created using github.com/Sajmani/birdsync \nMy own notes\n",
+			Ofvs: []inat.Ofv{
+				{FieldID: inat.EBirdField, Value: "S132"},
+				{FieldID: inat.EBirdScientificNameField, Value: "Turdus migratorius"},
+			},
+		},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	mockInat := &mockINatClient{userID: "testuser", observations: inatObservations}
+	stats := birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if stats.updatedObservations != 1 || len(mockInat.updated) != 1 {
+		t.Fatalf("Expected 1 updated observation, got %d", stats.updatedObservations)
+	}
+	want := inatObservations[0].Description + inat.DescriptionMarker + "\neBird checklist comments:\nWindy\n"
+	if got := mockInat.updated[0].Description; got != want {
+		t.Errorf("Description = %q, want %q", got, want)
+	}
+
+	// Resyncing the updated observation changes nothing.
+	inatObservations[0].Description = want
+	mockInat = &mockINatClient{userID: "testuser", observations: inatObservations}
+	stats = birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if stats.updatedObservations != 0 || stats.previouslySkips != 1 {
+		t.Errorf("Expected 0 updated and 1 skipped observation, got %d and %d",
+			stats.updatedObservations, stats.previouslySkips)
+	}
+}
+
+func TestProtocolField(t *testing.T) {
+	defer func() { protocolFieldID = 0 }()
+	ebirdRecords := []ebird.Record{
+		{
+			SubmissionID:   "S128",
+			ScientificName: "Corvus brachyrhynchos",
+			CommonName:     "American Crow",
+			Date:           "2023-01-03",
+			Time:           "03:00 PM",
+			Protocol:       "Traveling",
+		},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	for _, fieldID := range []int{0, 12345} {
+		protocolFieldID = fieldID
+		mockInat := &mockINatClient{userID: "testuser"}
+		birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+		if len(mockInat.created) != 1 {
+			t.Fatalf("Expected 1 created observation, got %d", len(mockInat.created))
+		}
+		gotID := 0
+		for _, ofv := range mockInat.created[0].ObservationFieldValuesAttributes {
+			if ofv.Value == "Traveling" {
+				gotID = ofv.ObservationFieldID
+			}
+		}
+		if gotID != fieldID {
+			t.Errorf("protocol recorded in field %d, want %d", gotID, fieldID)
+		}
+	}
+}
+
+func TestLocationAccuracy(t *testing.T) {
+	ebirdRecords := []ebird.Record{
+		{
+			SubmissionID:       "S129",
+			ScientificName:     "Corvus brachyrhynchos",
+			CommonName:         "American Crow",
+			Date:               "2023-01-03",
+			Time:               "03:00 PM",
+			LocationID:         "L1", // hotspot
+			Protocol:           "Traveling",
+			DistanceTraveledKm: "1.5",
+		},
+		{
+			SubmissionID:   "S130",
+			ScientificName: "Corvus brachyrhynchos",
+			CommonName:     "American Crow",
+			Date:           "2023-01-04",
+			Time:           "03:00 PM",
+			LocationID:     "L2", // personal location
+			Protocol:       "Stationary",
+		},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	mockEbird := &mockEBirdClient{records: ebirdRecords, accuracies: map[string]int{"L2": 100}}
+	mockInat := &mockINatClient{userID: "testuser"}
+	birdsync("MyEBirdData.csv", mockEbird, "myUserID", mockInat)
+	if len(mockInat.created) != 2 {
+		t.Fatalf("Expected 2 created observations, got %d", len(mockInat.created))
+	}
+	for i, want := range []float64{float64(positionalAccuracy) + 1500, 100} {
+		if got := mockInat.created[i].PositionalAccuracy; got != want {
+			t.Errorf("observation %d has positional accuracy %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestLocationAccuracySkipped(t *testing.T) {
+	ebirdRecords := []ebird.Record{
+		{
+			SubmissionID:   "S129",
+			ScientificName: "Corvus brachyrhynchos",
+			CommonName:     "American Crow",
+			Date:           "2023-01-03",
+			Time:           "03:00 PM",
+			LocationID:     "L1",
+		},
+		{
+			SubmissionID:     "S130",
+			ScientificName:   "Corvus brachyrhynchos",
+			CommonName:       "American Crow",
+			Date:             "2023-01-04",
+			Time:             "03:00 PM",
+			LocationID:       "L2",
+			MLCatalogNumbers: "123",
+		},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = true
+	fuzzy = false
+	defer func() { verifiable = false }()
+
+	// The record without media is skipped by --verifiable before its
+	// location is looked up.
+	mockEbird := &mockEBirdClient{records: ebirdRecords}
+	mockInat := &mockINatClient{userID: "testuser"}
+	birdsync("MyEBirdData.csv", mockEbird, "myUserID", mockInat)
+	if len(mockInat.created) != 1 {
+		t.Fatalf("Expected 1 created observation, got %d", len(mockInat.created))
+	}
+	if want := []string{"L2"}; !slices.Equal(mockEbird.located, want) {
+		t.Errorf("LocationAccuracy looked up %q, want %q", mockEbird.located, want)
+	}
+}
+
+func TestEscapeeCaptive(t *testing.T) {
+	ebirdRecords := []ebird.Record{
+		{
+			SubmissionID:   "S131",
+			ScientificName: "Pavo cristatus",
+			CommonName:     "Indian Peafowl",
+			Date:           "2023-01-03",
+			Time:           "03:00 PM",
+			ExoticCode:     "X",
+		},
+		{
+			SubmissionID:   "S131",
+			ScientificName: "Cygnus olor",
+			CommonName:     "Mute Swan",
+			Date:           "2023-01-03",
+			Time:           "03:00 PM",
+			ExoticCode:     "N",
+		},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	mockInat := &mockINatClient{userID: "testuser"}
+	birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if len(mockInat.created) != 2 {
+		t.Fatalf("Expected 2 created observations, got %d", len(mockInat.created))
+	}
+	for i, want := range []bool{true, false} {
+		if got := mockInat.created[i].CaptiveFlag; got != want {
+			t.Errorf("%s has CaptiveFlag %v, want %v", ebirdRecords[i].CommonName, got, want)
+		}
+	}
+}
+
+func TestSharedChecklists(t *testing.T) {
+	defer func() { dedupeShared = true }()
+	shared := func(id, name string) ebird.Record {
+		return ebird.Record{
+			SubmissionID:      id,
+			ScientificName:    name,
+			Date:              "2023-01-05",
+			Time:              "08:00 AM",
+			LocationID:        "L1",
+			Latitude:          "38.5",
+			Longitude:         "-77.25",
+			NumberOfObservers: "2",
+		}
+	}
+	ebirdRecords := []ebird.Record{
+		shared("S140", "Corvus brachyrhynchos"),
+		shared("S141", "Corvus brachyrhynchos"), // another observer's copy of S140
+		shared("S141", "Sitta carolinensis"),    // only on the copy
+		shared("S142", "Turdus migratorius"),    // synced before from another copy
+	}
+	inatObservations := []inat.Result{
+		{
+			UUID:           uuid.New(),
+			ObservedOn:     "2023-01-05",
+			TimeObservedAt: "2023-01-05T08:00:00-05:00",
+			Location:       "38.5,-77.25",
+			Ofvs: []inat.Ofv{
+				{FieldID: inat.EBirdField, Value: "S143"},
+				{FieldID: inat.EBirdScientificNameField, Value: "Turdus migratorius"},
+				{FieldID: inat.NumObserversField, Value: "2"},
+			},
+		},
+		{ // from a later group checklist at the same place, so not a copy
+			UUID:           uuid.New(),
+			ObservedOn:     "2023-01-05",
+			TimeObservedAt: "2023-01-05T15:00:00-05:00",
+			Location:       "38.5,-77.25",
+			Ofvs: []inat.Ofv{
+				{FieldID: inat.EBirdField, Value: "S145"},
+				{FieldID: inat.EBirdScientificNameField, Value: "Sitta carolinensis"},
+				{FieldID: inat.NumObserversField, Value: "2"},
+			},
+		},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	for _, tc := range []struct {
+		dedupe      bool
+		wantCreated int
+	}{
+		{true, 2},
+		{false, 4},
+	} {
+		dedupeShared = tc.dedupe
+		mockInat := &mockINatClient{userID: "testuser", observations: inatObservations}
+		stats := birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+		if len(mockInat.created) != tc.wantCreated {
+			t.Errorf("--shared_checklists=%v created %d observations, want %d", tc.dedupe, len(mockInat.created), tc.wantCreated)
+		}
+		if want := 4 - tc.wantCreated; stats.sharedSkips != want {
+			t.Errorf("--shared_checklists=%v skipped %d shared observations, want %d", tc.dedupe, stats.sharedSkips, want)
+		}
+	}
+}
+
+func TestPresenceField(t *testing.T) {
+	defer func() { presenceFieldID = 0 }()
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03", Count: "3"},
+		{SubmissionID: "S1", ScientificName: "Corvus brachyrhynchos", Date: "2023-01-03", Count: "X"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	fields := func(obs inat.Observation) map[int]any {
+		m := map[int]any{}
+		for _, ofv := range obs.ObservationFieldValuesAttributes {
+			m[ofv.ObservationFieldID] = ofv.Value
+		}
+		return m
+	}
+	for _, tc := range []struct {
+		fieldID   int
+		wantCount any // for the X record
+		wantYes   bool
+	}{
+		{0, "X", false},
+		{1234, nil, true},
+	} {
+		presenceFieldID = tc.fieldID
+		mockInat := &mockINatClient{userID: "testuser"}
+		birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+		if len(mockInat.created) != 2 {
+			t.Fatalf("Expected 2 created observations, got %d", len(mockInat.created))
+		}
+		if got := fields(mockInat.created[0])[inat.CountField]; got != "3" {
+			t.Errorf("presence field %d: count = %q, want 3", tc.fieldID, got)
+		}
+		f := fields(mockInat.created[1])
+		if got := f[inat.CountField]; got != tc.wantCount {
+			t.Errorf("presence field %d: X count = %v, want %v", tc.fieldID, got, tc.wantCount)
+		}
+		if got := f[1234] == "yes"; got != tc.wantYes {
+			t.Errorf("presence field %d: presence = %v, want %v", tc.fieldID, got, tc.wantYes)
+		}
+	}
+}
+
+func TestTimeZone(t *testing.T) {
+	defer timeZone.Set("")
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-07-04", Time: "07:30 AM"},
+		{SubmissionID: "S2", ScientificName: "Turdus migratorius", Date: "2023-07-05"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	mockInat := &mockINatClient{userID: "testuser"}
+	birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if obs := mockInat.created[0]; obs.ObservedOnString != "2023-07-04 07:30 AM" || obs.TimeZone != "" {
+		t.Errorf("Without --time_zone, observed = %q in %q; want 2023-07-04 07:30 AM with no zone",
+			obs.ObservedOnString, obs.TimeZone)
+	}
+
+	if err := timeZone.Set("America/New_York"); err != nil {
+		t.Fatal(err)
+	}
+	mockInat = &mockINatClient{userID: "testuser"}
+	birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if obs := mockInat.created[0]; obs.ObservedOnString != "2023-07-04T07:30:00-04:00" || obs.TimeZone != "America/New_York" {
+		t.Errorf("With --time_zone, observed = %q in %q; want 2023-07-04T07:30:00-04:00 in America/New_York",
+			obs.ObservedOnString, obs.TimeZone)
+	}
+	if obs := mockInat.created[1]; obs.ObservedOnString != "2023-07-05 " {
+		t.Errorf("With --time_zone, date-only observed = %q, want the date", obs.ObservedOnString)
+	}
+
+	// Without --time_zone, records from single-zone regions use that zone.
+	timeZone.Set("")
+	ebirdRecords = []ebird.Record{
+		{SubmissionID: "S3", ScientificName: "Turdus migratorius", StateProvince: "US-CA", Date: "2023-07-04", Time: "07:30 AM"},
+		{SubmissionID: "S4", ScientificName: "Turdus migratorius", StateProvince: "US-TX", Date: "2023-07-04", Time: "07:30 AM"},
+	}
+	mockInat = &mockINatClient{userID: "testuser"}
+	birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if obs := mockInat.created[0]; obs.ObservedOnString != "2023-07-04T07:30:00-07:00" || obs.TimeZone != "America/Los_Angeles" {
+		t.Errorf("In US-CA, observed = %q in %q; want 2023-07-04T07:30:00-07:00 in America/Los_Angeles",
+			obs.ObservedOnString, obs.TimeZone)
+	}
+	if obs := mockInat.created[1]; obs.ObservedOnString != "2023-07-04 07:30 AM" || obs.TimeZone != "" {
+		t.Errorf("In US-TX (several zones), observed = %q in %q; want 2023-07-04 07:30 AM with no zone",
+			obs.ObservedOnString, obs.TimeZone)
+	}
+}
+
+func TestExternalIDField(t *testing.T) {
+	defer func(f func(ebird.Record) string) {
+		externalIDFieldID = 0
+		externalID = f
+	}(externalID)
+	ebirdRecords := []ebird.Record{
+		{
+			Line:           7,
+			SubmissionID:   "S128",
+			ScientificName: "Corvus brachyrhynchos",
+			CommonName:     "American Crow",
+			Date:           "2023-01-03",
+		},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	externalIDFieldID = 12345
+	externalID = func(rec ebird.Record) string {
+		return fmt.Sprintf("mydb-%d", rec.Line)
+	}
+	mockInat := &mockINatClient{userID: "testuser"}
+	birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if len(mockInat.created) != 1 {
+		t.Fatalf("Expected 1 created observation, got %d", len(mockInat.created))
+	}
+	var got any
+	for _, ofv := range mockInat.created[0].ObservationFieldValuesAttributes {
+		if ofv.ObservationFieldID == externalIDFieldID {
+			got = ofv.Value
+		}
+	}
+	if got != "mydb-7" {
+		t.Errorf("external ID field = %v, want mydb-7", got)
+	}
+}
+
+func TestMaxMedia(t *testing.T) {
+	defer func(orig int) { maxMedia = orig }(maxMedia)
+	maxMedia = 2
+
+	ebirdRecords := []ebird.Record{
+		{ // new observation with too many assets
+			SubmissionID:     "S130",
+			ScientificName:   "Turdus migratorius",
+			Date:             "2023-01-03",
+			MLCatalogNumbers: "1 2 3",
+		},
+		{ // existing observation that's already at the limit
+			SubmissionID:     "S131",
+			ScientificName:   "Corvus brachyrhynchos",
+			Date:             "2023-01-03",
+			MLCatalogNumbers: "4 5 6",
+		},
+	}
+	inatObservations := []inat.Result{
+		{
+			UUID:        uuid.New(),
+			Description: mlAssetURL("4") + "\n" + mlAssetURL("5"),
+			Sounds:      make([]inat.Sound, 2),
+			Ofvs: []inat.Ofv{
+				{FieldID: inat.EBirdField, Value: "S131"},
+				{FieldID: inat.EBirdScientificNameField, Value: "Corvus brachyrhynchos"},
+			},
+		},
+	}
+	mockEbird := &mockEBirdClient{records: ebirdRecords}
+	mockInat := &mockINatClient{userID: "testuser", observations: inatObservations}
+
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	stats := birdsync("MyEBirdData.csv", mockEbird, "myUserID", mockInat)
+
+	if stats.uploadedSounds != 2 {
+		t.Errorf("Expected 2 uploaded sounds, got %d", stats.uploadedSounds)
+	}
+	if stats.skippedMedia != 2 {
+		t.Errorf("Expected 2 skipped media, got %d", stats.skippedMedia)
+	}
+	if len(mockInat.updated) != 1 {
+		t.Fatalf("Expected 1 updated observation, got %d", len(mockInat.updated))
+	}
+	desc := mockInat.updated[0].Description
+	if !strings.Contains(desc, mlAssetURL("2")) || strings.Contains(desc, mlAssetURL("3")) {
+		t.Errorf("Description should list uploaded assets 1 and 2 only:\n%s", desc)
+	}
+	if !strings.Contains(desc, "not uploaded (too many media): ML3") {
+		t.Errorf("Description should note omitted asset 3:\n%s", desc)
+	}
+}
+
+func TestCheckNames(t *testing.T) {
+	defer func() { checkNames = false }()
+	checkNames = true
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", CommonName: "American Robin", Date: "2023-01-03"},
+		{SubmissionID: "S1", ScientificName: "Poecile atricapillus", CommonName: "Black-capped Chickadee", Date: "2023-01-03"},
+		{SubmissionID: "S1", ScientificName: "Corvus brachyrhynchos", CommonName: "Common Raven", Date: "2023-01-03"},
+		{SubmissionID: "S1", ScientificName: "Aythya marila/affinis", CommonName: "Greater/Lesser Scaup", Date: "2023-01-03"},
+	}
+	mockInat := &mockINatClient{
+		userID: "testuser",
+		taxa: map[string]inat.Taxon{
+			"Turdus migratorius":    {ID: 12727, Name: "Turdus migratorius", PreferredCommonName: "American Robin"},
+			"Poecile atricapillus":  {ID: 144815, Name: "Poecile atricapillus", PreferredCommonName: "Black-Capped Chickadee"},
+			"Corvus brachyrhynchos": {ID: 8021, Name: "Corvus brachyrhynchos", PreferredCommonName: "American Crow"},
+		},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	stats := birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if stats.createdObservations != 4 {
+		t.Errorf("Expected 4 created observations, got %d", stats.createdObservations)
+	}
+	if len(stats.nameMismatches) != 1 {
+		t.Fatalf("Expected 1 name mismatch, got %+v", stats.nameMismatches)
+	}
+	if m := stats.nameMismatches[0]; m.eBirdName != "Common Raven" || m.iNatName != "American Crow" {
+		t.Errorf("Mismatch = %+v, want Common Raven vs American Crow", m)
+	}
+}
+
+func TestCheckIDs(t *testing.T) {
+	defer func() { checkIDs = false }()
+	checkIDs = true
+	synced := func(submission, name string, community inat.Taxon) inat.Result {
+		return inat.Result{
+			UUID:           uuid.New(),
+			ObservedOn:     "2023-01-03",
+			Taxon:          inat.Taxon{ID: 1, Name: name},
+			CommunityTaxon: community,
+			Ofvs: []inat.Ofv{
+				{FieldID: inat.EBirdField, Value: submission},
+				{FieldID: inat.EBirdScientificNameField, Value: name},
+			},
+		}
+	}
+	raven := synced("S1", "Corvus brachyrhynchos", inat.Taxon{ID: 8229, Name: "Corvus corax"})
+	// The observer already agreed with the community on iNaturalist.
+	corrected := synced("S2", "Corvus brachyrhynchos", inat.Taxon{ID: 8229, Name: "Corvus corax"})
+	corrected.Taxon = inat.Taxon{ID: 8229, Name: "Corvus corax"}
+	mockInat := &mockINatClient{
+		userID: "testuser",
+		observations: []inat.Result{
+			raven,
+			synced("S1", "Turdus migratorius", inat.Taxon{ID: 12727, Name: "Turdus migratorius"}),
+			synced("S1", "Junco hyemalis", inat.Taxon{ID: 2, Name: "Junco hyemalis hyemalis"}),
+			synced("S1", "Poecile atricapillus", inat.Taxon{}), // not enough identifications yet
+			corrected,
+		},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	stats := birdsync("MyEBirdData.csv", &mockEBirdClient{}, "myUserID", mockInat)
+	if len(stats.divergedIDs) != 1 {
+		t.Fatalf("Expected 1 diverged ID, got %+v", stats.divergedIDs)
+	}
+	if d := stats.divergedIDs[0]; d.id.ScientificName != "Corvus brachyrhynchos" || d.taxonName != "Corvus brachyrhynchos" || d.communityName != "Corvus corax" || d.url != raven.URL() {
+		t.Errorf("Diverged ID = %+v, want Corvus brachyrhynchos vs Corvus corax at %s", d, raven.URL())
+	}
+	if report := stats.report(); !strings.Contains(report, "1 iNaturalist observations whose community ID differs") {
+		t.Errorf("Report doesn't mention the diverged ID:\n%s", report)
+	}
+}
+
+func TestUploadOrder(t *testing.T) {
+	defer func(orig string) { mediaOrder = orig }(mediaOrder)
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03", MLCatalogNumbers: "30 10 20"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	for _, tc := range []struct {
+		order string
+		want  string
+	}{
+		{mediaOrderEBird, "30 10 20"},
+		{mediaOrderReverse, "20 10 30"},
+		{mediaOrderID, "10 20 30"},
+	} {
+		mediaOrder = tc.order
+		mockInat := &mockINatClient{userID: "testuser"}
+		birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+		if got := strings.Join(mockInat.uploaded, " "); got != tc.want {
+			t.Errorf("--media_order=%s: uploaded %s, want %s", tc.order, got, tc.want)
+		}
+	}
+}
+
+func TestSubspecies(t *testing.T) {
+	defer func() { subspecies = subspeciesSpecies }()
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Junco hyemalis", Date: "2023-01-03"},
+	}
+	taxa := map[string]inat.Taxon{
+		"Junco hyemalis":          {ID: 1, Name: "Junco hyemalis", Rank: "species"},
+		"Junco hyemalis oreganus": {ID: 2, Name: "Junco hyemalis oreganus", Rank: "subspecies"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	for _, tc := range []struct {
+		strategy    string
+		wantTaxonID float64
+		wantGuess   string
+	}{
+		{subspeciesSpecies, 0, "Junco hyemalis"},
+		{subspeciesSubspecies, 2, "Junco hyemalis oreganus"},
+	} {
+		subspecies = tc.strategy
+		mockInat := &mockINatClient{userID: "testuser", taxa: taxa}
+		birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+		if len(mockInat.created) != 1 {
+			t.Fatalf("--subspecies=%s: expected 1 created observation, got %d", tc.strategy, len(mockInat.created))
+		}
+		if obs := mockInat.created[0]; obs.TaxonID != tc.wantTaxonID || obs.SpeciesGuess != tc.wantGuess {
+			t.Errorf("--subspecies=%s: taxon = %v %q, want %v %q",
+				tc.strategy, obs.TaxonID, obs.SpeciesGuess, tc.wantTaxonID, tc.wantGuess)
+		}
+	}
+}
+
+// TestDryRunTestServer runs a dry-run sync against a fake iNaturalist API
+// and checks that it reports what it would do without changing anything.
+func TestDryRunTestServer(t *testing.T) {
+	defer func() { dryRun = false }()
+	dryRun = true
+	server := inattest.NewServer([]inat.Result{
+		{
+			ID:   1,
+			UUID: uuid.New(),
+			Ofvs: []inat.Ofv{
+				{FieldID: inat.EBirdField, Value: "S1"},
+				{FieldID: inat.EBirdScientificNameField, Value: "Turdus migratorius"},
+			},
+		},
+	})
+	defer server.Close()
+	inatClient := inat.NewClient(server.URL, "test-token", UserAgent)
+	inatClient.SetRequestLimits(0, 0) // don't slow down the test
+	client := inatClientImpl{client: inatClient}
+
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03"},
+		{SubmissionID: "S1", ScientificName: "Corvus brachyrhynchos", Date: "2023-01-03", MLCatalogNumbers: "100 200"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	stats := birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "testuser", client)
+	if stats.totalRecords != 2 || stats.previouslySkips != 1 || stats.createdObservations != 1 {
+		t.Errorf("Got %d records, %d previously synced, %d created; want 2, 1, 1",
+			stats.totalRecords, stats.previouslySkips, stats.createdObservations)
+	}
+	if stats.uploadedPhotos+stats.uploadedSounds != 2 {
+		t.Errorf("Got %d uploaded media, want 2", stats.uploadedPhotos+stats.uploadedSounds)
+	}
+	if got := server.Mutations(); len(got) != 0 {
+		t.Errorf("Dry run changed iNaturalist: %v", got)
+	}
+}
+
+func TestMediaKindMismatch(t *testing.T) {
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03", MLCatalogNumbers: "100 200"},
+	}
+	// Asset 100 was served as a sound but is an image, like a spectrogram.
+	mockEbird := &mockEBirdClient{records: ebirdRecords, kinds: map[string]ebird.MediaKind{"100": ebird.Photo}}
+	mockInat := &mockINatClient{userID: "testuser"}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	stats := birdsync("MyEBirdData.csv", mockEbird, "myUserID", mockInat)
+	if got := strings.Join(mockInat.uploaded, " "); got != "200" {
+		t.Errorf("Uploaded %q, want only 200", got)
+	}
+	if len(stats.failures) != 1 || !strings.Contains(stats.failures[0].err.Error(), "downloaded as a sound but contains a photo") {
+		t.Errorf("Failures = %v, want one mismatch for 100", stats.failures)
+	}
+	if len(mockInat.updated) != 1 {
+		t.Fatalf("Expected 1 updated observation, got %d", len(mockInat.updated))
+	}
+	if desc := mockInat.updated[0].Description; strings.Contains(desc, mlAssetURL("100")) || !strings.Contains(desc, mlAssetURL("200")) {
+		t.Errorf("Description should list only the uploaded asset 200:\n%s", desc)
+	}
+}
+
+func TestVideoAssets(t *testing.T) {
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03", MLCatalogNumbers: "100 200"},
+	}
+	video := map[string]ebird.MediaKind{"100": ebird.Video}
+	mockEbird := &mockEBirdClient{records: ebirdRecords, kinds: video, served: video}
+	mockInat := &mockINatClient{userID: "testuser"}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	stats := birdsync("MyEBirdData.csv", mockEbird, "myUserID", mockInat)
+	if got := strings.Join(mockInat.uploaded, " "); got != "200" {
+		t.Errorf("Uploaded %q, want only 200", got)
+	}
+	if len(stats.failures) != 0 || stats.linkedVideos != 1 || stats.uploadedSounds != 1 {
+		t.Errorf("stats = %+v, want 1 linked video and 1 uploaded sound", stats)
+	}
+	if len(mockInat.updated) != 1 {
+		t.Fatalf("Expected 1 updated observation, got %d", len(mockInat.updated))
+	}
+	// The video is linked, so later syncs don't try to upload it again.
+	if got := iNatMLAssets(inat.Result{Description: mockInat.updated[0].Description}).String(); got != "100 200" {
+		t.Errorf("iNatMLAssets() = %q, want 100 200", got)
+	}
+}
+
+func TestMLAttribution(t *testing.T) {
+	defer func() { mlAttribution = false }()
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03", MLCatalogNumbers: "100"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	for _, attribution := range []bool{false, true} {
+		mlAttribution = attribution
+		mockInat := &mockINatClient{userID: "testuser"}
+		birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+		if len(mockInat.updated) != 1 {
+			t.Fatalf("Expected 1 updated observation, got %d", len(mockInat.updated))
+		}
+		desc := mockInat.updated[0].Description
+		credit := "© Test Recordist; CC BY; Macaulay Library ML100\n"
+		if got := strings.Contains(desc, credit); got != attribution {
+			t.Errorf("With --ml_attribution=%v, description has credit = %v:\n%s", attribution, got, desc)
+		}
+		// The credit doesn't confuse the parsing of uploaded ML assets.
+		if got := iNatMLAssets(inat.Result{Description: desc}).String(); got != "100" {
+			t.Errorf("iNatMLAssets() = %q, want 100", got)
+		}
+	}
+}
+
+func TestProject(t *testing.T) {
+	defer func() { project = "" }()
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03"},
+		{SubmissionID: "S1", ScientificName: "Cardinalis cardinalis", Date: "2023-01-03"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	project = "ebird-imports"
+	mockInat := &mockINatClient{
+		userID:   "testuser",
+		projects: map[string]inat.Project{"ebird-imports": {ID: 42, Slug: "ebird-imports"}},
+	}
+	birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if len(mockInat.created) != 2 || len(mockInat.projectAdds) != 2 {
+		t.Fatalf("Created %d observations and added %d to the project, want 2 and 2", len(mockInat.created), len(mockInat.projectAdds))
+	}
+	for i, obs := range mockInat.created {
+		if mockInat.projectAdds[i] != obs.UUID {
+			t.Errorf("Added %s to the project, want %s", mockInat.projectAdds[i], obs.UUID)
+		}
+	}
+
+	// Without --project, nothing is added.
+	project = ""
+	mockInat = &mockINatClient{userID: "testuser"}
+	birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if len(mockInat.projectAdds) != 0 {
+		t.Errorf("Added %d observations to a project without --project, want 0", len(mockInat.projectAdds))
+	}
+}
+
+func TestTripReportDefaults(t *testing.T) {
+	defer func() { verifiable = false }()
+	// Trip report records, from the eBird API, have no media.
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03", Location: "Some Park"},
+		{SubmissionID: "S2", ScientificName: "Corvus brachyrhynchos", Date: "2023-01-04", Location: "Other Park"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = true // the default
+	fuzzy = false
+	if err := tripReportDefaults(); err != nil {
+		t.Fatalf("tripReportDefaults() error = %v", err)
+	}
+
+	mockInat := &mockINatClient{userID: "testuser"}
+	stats := birdsync("https://ebird.org/tripreport/123456", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if stats.createdObservations != 2 || stats.verifiableSkips != 0 {
+		t.Errorf("Created %d observations and skipped %d unverifiable; want 2 and 0",
+			stats.createdObservations, stats.verifiableSkips)
+	}
+}
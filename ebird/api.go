@@ -0,0 +1,304 @@
+package ebird
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIBaseURL is the standard base URL for the eBird API 2.0.
+const APIBaseURL = "https://api.ebird.org/v2"
+
+// GetAPIKey returns the user's eBird API key from the EBIRD_API_KEY
+// environment variable, or the empty string if it's not set.
+// Request a key at https://ebird.org/api/keygen.
+func GetAPIKey() string {
+	return os.Getenv("EBIRD_API_KEY")
+}
+
+// APIClient reads checklists from the eBird API 2.0, as an alternative
+// to downloading MyEBirdData.csv. It lists the recent checklists in a
+// region (RecentChecklists) and reads a checklist by its submission ID
+// (Checklist). The API is more limited than the export:
+//
+//   - It has no way to list one user's checklists, so APIClient can't
+//     either. Checklists only identify their submitters by display name,
+//     which isn't unique, and only the most recent checklists in a region
+//     are listed.
+//   - Its records have no Macaulay Library catalog numbers, Line numbers,
+//     or breeding codes.
+//   - Its locations come from the checklist list, so records read with
+//     Checklist have no location name or coordinates.
+type APIClient struct {
+	baseURL    string
+	apiKey     string
+	userAgent  string
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	taxa     map[string]apiTaxon // species code to taxon
+	hotspots map[string]*Hotspot // location ID to hotspot, or nil if it's not one
+}
+
+// NewAPIClient returns a client for the eBird API at baseURL,
+// usually APIBaseURL, that authenticates with apiKey.
+func NewAPIClient(baseURL, apiKey, userAgent string) *APIClient {
+	return &APIClient{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		userAgent:  userAgent,
+		httpClient: &http.Client{Timeout: time.Minute},
+	}
+}
+
+// get fetches path from the API and decodes the JSON response into v.
+func (c *APIClient) get(ctx context.Context, path string, query url.Values, v any) error {
+	b, err := c.fetch(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// fetch returns the body of the API response for path.
+func (c *APIClient) fetch(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return c.fetchURL(ctx, u)
+}
+
+// fetchURL returns the body of the response for u, which may be outside
+// the API, authenticating with the client's API key.
+func (c *APIClient) fetchURL(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("X-eBirdApiToken", c.apiKey)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%s: eBird rejected the API key; check EBIRD_API_KEY", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError{resp.StatusCode, resp.Status}
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading HTTP response: %w", err)
+	}
+	return b, nil
+}
+
+// statusError is an unsuccessful HTTP status from the API.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e statusError) Error() string {
+	return "bad HTTP status: " + e.status
+}
+
+// ChecklistSummary describes a checklist in a list of recent checklists.
+type ChecklistSummary struct {
+	SubmissionID    string `json:"subId"`
+	UserDisplayName string `json:"userDisplayName"`
+	NumSpecies      int    `json:"numSpecies"`
+	ISOObsDate      string `json:"isoObsDate"` // "2006-01-02 15:04" or "2006-01-02"
+	Loc             struct {
+		LocationID       string  `json:"locId"`
+		Name             string  `json:"name"`
+		Latitude         float64 `json:"latitude"`
+		Longitude        float64 `json:"longitude"`
+		Subnational1Code string  `json:"subnational1Code"`
+		Subnational2Name string  `json:"subnational2Name"`
+	} `json:"loc"`
+}
+
+// setLocation sets the location of rec from the summary of its checklist.
+func (l ChecklistSummary) setLocation(rec *Record) {
+	rec.Location = l.Loc.Name
+	rec.County = l.Loc.Subnational2Name
+	rec.Latitude = strconv.FormatFloat(l.Loc.Latitude, 'f', -1, 64)
+	rec.Longitude = strconv.FormatFloat(l.Loc.Longitude, 'f', -1, 64)
+}
+
+// RecentChecklists returns up to maxResults of the checklists most
+// recently submitted in the region with the provided code, such as
+// "US-CA" or "US-CA-085". If maxResults is zero, eBird's default applies.
+func (c *APIClient) RecentChecklists(ctx context.Context, regionCode string, maxResults int) ([]ChecklistSummary, error) {
+	q := url.Values{}
+	if maxResults > 0 {
+		q.Set("maxResults", strconv.Itoa(maxResults))
+	}
+	var lists []ChecklistSummary
+	if err := c.get(ctx, "/product/lists/"+url.PathEscape(regionCode), q, &lists); err != nil {
+		return nil, fmt.Errorf("RecentChecklists(%s): %w", regionCode, err)
+	}
+	return lists, nil
+}
+
+// apiChecklist is returned by https://api.ebird.org/v2/product/checklist/view/{subId}
+type apiChecklist struct {
+	SubmissionID     string   `json:"subId"`
+	ProtocolID       string   `json:"protocolId"`
+	LocationID       string   `json:"locId"`
+	DurationHrs      *float64 `json:"durationHrs"`
+	AllObsReported   bool     `json:"allObsReported"`
+	ObsDt            string   `json:"obsDt"`
+	ObsTimeValid     bool     `json:"obsTimeValid"`
+	NumObservers     int      `json:"numObservers"`
+	EffortDistanceKm *float64 `json:"effortDistanceKm"`
+	EffortAreaHa     *float64 `json:"effortAreaHa"`
+	Subnational1Code string   `json:"subnational1Code"`
+	Comments         string   `json:"comments"`
+	Obs              []struct {
+		SpeciesCode string `json:"speciesCode"`
+		HowManyStr  string `json:"howManyStr"`
+		Present     bool   `json:"present"`
+		Comments    string `json:"comments"`
+	} `json:"obs"`
+}
+
+// apiTaxon is returned by https://api.ebird.org/v2/ref/taxonomy/ebird
+type apiTaxon struct {
+	ScientificName string  `json:"sciName"`
+	CommonName     string  `json:"comName"`
+	SpeciesCode    string  `json:"speciesCode"`
+	TaxonOrder     float64 `json:"taxonOrder"`
+}
+
+// apiProtocols maps eBird protocol IDs to their names in MyEBirdData.csv.
+var apiProtocols = map[string]string{
+	"P20": "eBird - Casual Observation",
+	"P21": "eBird - Stationary Count",
+	"P22": "eBird - Traveling Count",
+	"P23": "eBird - Exhaustive Area Count",
+	"P62": "Historical",
+}
+
+// Checklist returns the records on the checklist with the provided
+// submission ID, in the same form as records read from MyEBirdData.csv.
+// Species names are looked up in the eBird taxonomy, which is cached
+// for the lifetime of the client.
+func (c *APIClient) Checklist(ctx context.Context, submissionID string) ([]Record, error) {
+	var cl apiChecklist
+	if err := c.get(ctx, "/product/checklist/view/"+url.PathEscape(submissionID), nil, &cl); err != nil {
+		return nil, fmt.Errorf("Checklist(%s): %w", submissionID, err)
+	}
+	var codes []string
+	for _, o := range cl.Obs {
+		codes = append(codes, o.SpeciesCode)
+	}
+	taxa, err := c.lookupTaxa(ctx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("Checklist(%s): %w", submissionID, err)
+	}
+
+	date, clock, _ := strings.Cut(cl.ObsDt, " ")
+	if clock != "" && cl.ObsTimeValid {
+		if t, err := time.Parse("15:04", clock); err == nil {
+			clock = t.Format("03:04 PM")
+		}
+	} else {
+		clock = ""
+	}
+	protocol, ok := apiProtocols[cl.ProtocolID]
+	if !ok {
+		protocol = cl.ProtocolID
+	}
+	allObsReported := "0"
+	if cl.AllObsReported {
+		allObsReported = "1"
+	}
+	optional := func(f *float64, scale float64) string {
+		if f == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*f*scale, 'f', -1, 64)
+	}
+	var records []Record
+	for _, o := range cl.Obs {
+		t, ok := taxa[o.SpeciesCode]
+		if !ok {
+			return nil, fmt.Errorf("Checklist(%s): unknown species code %q", submissionID, o.SpeciesCode)
+		}
+		count := o.HowManyStr
+		if o.Present || count == "" {
+			count = "X"
+		}
+		records = append(records, Record{
+			SubmissionID:       cl.SubmissionID,
+			CommonName:         t.CommonName,
+			ScientificName:     t.ScientificName,
+			TaxonomicOrder:     strconv.FormatFloat(t.TaxonOrder, 'f', -1, 64),
+			Count:              count,
+			StateProvince:      cl.Subnational1Code,
+			LocationID:         cl.LocationID,
+			Date:               date,
+			Time:               clock,
+			Protocol:           protocol,
+			DurationMin:        optional(cl.DurationHrs, 60),
+			AllObsReported:     allObsReported,
+			DistanceTraveledKm: optional(cl.EffortDistanceKm, 1),
+			AreaCoveredHa:      optional(cl.EffortAreaHa, 1),
+			NumberOfObservers:  strconv.Itoa(cl.NumObservers),
+			ObservationDetails: o.Comments,
+			ChecklistComments:  cl.Comments,
+		})
+	}
+	return records, nil
+}
+
+// lookupTaxa returns the taxa for the provided species codes,
+// fetching the ones that aren't cached.
+func (c *APIClient) lookupTaxa(ctx context.Context, codes []string) (map[string]apiTaxon, error) {
+	taxa := map[string]apiTaxon{}
+	var missing []string
+	c.mu.Lock()
+	for _, code := range codes {
+		if t, ok := c.taxa[code]; ok {
+			taxa[code] = t
+		} else if !slices.Contains(missing, code) {
+			missing = append(missing, code)
+		}
+	}
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return taxa, nil
+	}
+
+	q := url.Values{}
+	q.Set("fmt", "json")
+	q.Set("species", strings.Join(missing, ","))
+	var results []apiTaxon
+	if err := c.get(ctx, "/ref/taxonomy/ebird", q, &results); err != nil {
+		return nil, fmt.Errorf("looking up species: %w", err)
+	}
+	c.mu.Lock()
+	if c.taxa == nil {
+		c.taxa = map[string]apiTaxon{}
+	}
+	for _, t := range results {
+		c.taxa[t.SpeciesCode] = t
+		taxa[t.SpeciesCode] = t
+	}
+	c.mu.Unlock()
+	return taxa, nil
+}
@@ -0,0 +1,137 @@
+package ebird
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestAPIServer(t *testing.T, taxonomyRequests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-eBirdApiToken"); got != "key" {
+			http.Error(w, "bad key", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/product/lists/US-CA":
+			if got := r.URL.Query().Get("maxResults"); got != "10" {
+				t.Errorf("maxResults = %q, want 10", got)
+			}
+			w.Write([]byte(`[
+				{"subId": "S1", "userDisplayName": "Jane Birder", "numSpecies": 2, "isoObsDate": "2024-01-15 08:10",
+				 "loc": {"locId": "L1", "name": "Some Park", "latitude": 37.5, "longitude": -122.25,
+				         "subnational1Code": "US-CA", "subnational2Name": "Santa Clara"}},
+				{"subId": "S2", "userDisplayName": "Someone Else", "numSpecies": 1, "isoObsDate": "2024-01-15 07:00",
+				 "loc": {"locId": "L2", "name": "Other Park"}}
+			]`))
+		case "/product/checklist/view/S1":
+			w.Write([]byte(`{"subId": "S1", "protocolId": "P22", "locId": "L1", "durationHrs": 1.5,
+				"allObsReported": true, "obsDt": "2024-01-15 08:10", "obsTimeValid": true, "numObservers": 2,
+				"effortDistanceKm": 2.5, "subnational1Code": "US-CA", "comments": "Sunny",
+				"obs": [
+					{"speciesCode": "amerob", "howManyStr": "3", "comments": "Singing"},
+					{"speciesCode": "norcar", "howManyStr": "X", "present": true}
+				]}`))
+		case "/ref/region/list/subnational2/US-VA":
+			w.Write([]byte(`[{"code": "US-VA-059", "name": "Fairfax"}, {"code": "US-VA-510", "name": "Alexandria (city)"}]`))
+		case "/checklists/77":
+			w.Write([]byte(`[{"subId": "S1", "loc": {"locId": "L1", "name": "Some Park", "latitude": 37.5, "longitude": -122.25,
+				"subnational1Code": "US-CA", "subnational2Name": "Santa Clara"}}]`))
+		case "/ref/hotspot/info/L1":
+			w.Write([]byte(`{"locId": "L1", "name": "Some Park", "latitude": 37.5, "longitude": -122.25,
+				"subnational1Code": "US-CA", "subnational2Code": "US-CA-085"}`))
+		case "/ref/hotspot/info/L500":
+			http.Error(w, "oops", http.StatusInternalServerError)
+		case "/ref/taxonomy/ebird":
+			*taxonomyRequests++
+			if got := r.URL.Query().Get("species"); got != "amerob,norcar" {
+				t.Errorf("species = %q, want amerob,norcar", got)
+			}
+			w.Write([]byte(`[
+				{"sciName": "Turdus migratorius", "comName": "American Robin", "speciesCode": "amerob", "taxonOrder": 27519},
+				{"sciName": "Cardinalis cardinalis", "comName": "Northern Cardinal", "speciesCode": "norcar", "taxonOrder": 32457.5}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAPIClient_Checklist(t *testing.T) {
+	taxonomyRequests := 0
+	server := newTestAPIServer(t, &taxonomyRequests)
+	client := NewAPIClient(server.URL, "key", "test")
+
+	lists, err := client.RecentChecklists(context.Background(), "US-CA", 10)
+	if err != nil {
+		t.Fatalf("RecentChecklists() error: %v", err)
+	}
+	if len(lists) != 2 || lists[0].SubmissionID != "S1" {
+		t.Fatalf("RecentChecklists() = %+v, want S1 and S2", lists)
+	}
+	recs, err := client.Checklist(context.Background(), "S1")
+	if err != nil {
+		t.Fatalf("Checklist() error: %v", err)
+	}
+	for i := range recs {
+		lists[0].setLocation(&recs[i])
+	}
+	if len(recs) != 2 {
+		t.Fatalf("Got %d records, want 2", len(recs))
+	}
+	want := Record{
+		SubmissionID:       "S1",
+		CommonName:         "American Robin",
+		ScientificName:     "Turdus migratorius",
+		TaxonomicOrder:     "27519",
+		Count:              "3",
+		StateProvince:      "US-CA",
+		County:             "Santa Clara",
+		LocationID:         "L1",
+		Location:           "Some Park",
+		Latitude:           "37.5",
+		Longitude:          "-122.25",
+		Date:               "2024-01-15",
+		Time:               "08:10 AM",
+		Protocol:           "eBird - Traveling Count",
+		DurationMin:        "90",
+		AllObsReported:     "1",
+		DistanceTraveledKm: "2.5",
+		NumberOfObservers:  "2",
+		ObservationDetails: "Singing",
+		ChecklistComments:  "Sunny",
+	}
+	if recs[0] != want {
+		t.Errorf("First record:\ngot  %+v\nwant %+v", recs[0], want)
+	}
+	if recs[1].ScientificName != "Cardinalis cardinalis" || recs[1].Count != "X" || recs[1].TaxonomicOrder != "32457.5" {
+		t.Errorf("Second record = %+v, want a Northern Cardinal counted X", recs[1])
+	}
+
+	// The taxonomy is cached.
+	if _, err := client.Checklist(context.Background(), "S1"); err != nil {
+		t.Fatalf("Checklist() error: %v", err)
+	}
+	if taxonomyRequests != 1 {
+		t.Errorf("Got %d taxonomy requests, want 1 (cached)", taxonomyRequests)
+	}
+}
+
+func TestAPIClient_Errors(t *testing.T) {
+	taxonomyRequests := 0
+	server := newTestAPIServer(t, &taxonomyRequests)
+
+	client := NewAPIClient(server.URL, "wrong", "test")
+	if _, err := client.RecentChecklists(context.Background(), "US-CA", 10); err == nil {
+		t.Errorf("RecentChecklists() with a bad key succeeded, want an error")
+	}
+
+	client = NewAPIClient(server.URL, "key", "test")
+	if _, err := client.Checklist(context.Background(), "S404"); err == nil {
+		t.Errorf("Checklist(S404) succeeded, want an error")
+	}
+}
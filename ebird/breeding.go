@@ -0,0 +1,108 @@
+package ebird
+
+import "strings"
+
+// BreedingCode is an eBird breeding code, such as "NY" (nest with young).
+// Codes follow the categories of breeding bird atlases.
+type BreedingCode string
+
+// BreedingCategory is the strength of the breeding evidence of a BreedingCode,
+// from weakest to strongest.
+type BreedingCategory int
+
+const (
+	UnknownBreeding BreedingCategory = iota
+	ObservedBreeding
+	PossibleBreeding
+	ProbableBreeding
+	ConfirmedBreeding
+)
+
+func (c BreedingCategory) String() string {
+	switch c {
+	case ObservedBreeding:
+		return "Observed"
+	case PossibleBreeding:
+		return "Possible"
+	case ProbableBreeding:
+		return "Probable"
+	case ConfirmedBreeding:
+		return "Confirmed"
+	}
+	return "Unknown"
+}
+
+type breedingInfo struct {
+	description string
+	category    BreedingCategory
+}
+
+// breedingCodes lists eBird's breeding codes.
+// See https://support.ebird.org/en/support/solutions/articles/48000837520.
+var breedingCodes = map[BreedingCode]breedingInfo{
+	"NY": {"Nest with Young", ConfirmedBreeding},
+	"NE": {"Nest with Eggs", ConfirmedBreeding},
+	"FS": {"Carrying Fecal Sac", ConfirmedBreeding},
+	"FY": {"Feeding Young", ConfirmedBreeding},
+	"CF": {"Carrying Food", ConfirmedBreeding},
+	"FL": {"Recently Fledged Young", ConfirmedBreeding},
+	"ON": {"Occupied Nest", ConfirmedBreeding},
+	"UN": {"Used Nest", ConfirmedBreeding},
+	"DD": {"Distraction Display", ConfirmedBreeding},
+	"NB": {"Nest Building", ProbableBreeding},
+	"CN": {"Carrying Nesting Material", ProbableBreeding},
+	"PE": {"Physiological Evidence", ProbableBreeding},
+	"B":  {"Woodpecker/Wren Nest Building", ProbableBreeding},
+	"A":  {"Agitated Behavior", ProbableBreeding},
+	"N":  {"Visiting Probable Nest Site", ProbableBreeding},
+	"C":  {"Courtship, Display, or Copulation", ProbableBreeding},
+	"T":  {"Territorial Defense", ProbableBreeding},
+	"P":  {"Pair in Suitable Habitat", ProbableBreeding},
+	"M":  {"Multiple (7+) Singing Males", ProbableBreeding},
+	"S7": {"Singing Male Present 7+ Days", ProbableBreeding},
+	"S":  {"Singing Male", PossibleBreeding},
+	"H":  {"In Appropriate Habitat", PossibleBreeding},
+	"F":  {"Flyover", ObservedBreeding},
+}
+
+// ParseBreedingCode parses a breeding code as it appears in the
+// Breeding Code column of MyEBirdData.csv, which is the code optionally
+// followed by its description, like "NY Nest with Young".
+// ok is false if s is empty or isn't a known code.
+func ParseBreedingCode(s string) (code BreedingCode, ok bool) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return "", false
+	}
+	code = BreedingCode(strings.ToUpper(fields[0]))
+	if _, ok := breedingCodes[code]; !ok {
+		return "", false
+	}
+	return code, true
+}
+
+// Description returns the code's meaning, like "Nest with Young".
+func (c BreedingCode) Description() string {
+	return breedingCodes[c].description
+}
+
+// Category returns the atlas category of the code.
+func (c BreedingCode) Category() BreedingCategory {
+	return breedingCodes[c].category
+}
+
+// String returns the code with its description and category,
+// like "NY Nest with Young (Confirmed)".
+func (c BreedingCode) String() string {
+	info, ok := breedingCodes[c]
+	if !ok {
+		return string(c)
+	}
+	return string(c) + " " + info.description + " (" + info.category.String() + ")"
+}
+
+// Breeding returns the record's breeding code.
+// ok is false if it has none or it isn't a known code.
+func (r Record) Breeding() (code BreedingCode, ok bool) {
+	return ParseBreedingCode(r.BreedingCode)
+}
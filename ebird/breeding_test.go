@@ -0,0 +1,34 @@
+package ebird
+
+import "testing"
+
+func TestParseBreedingCode(t *testing.T) {
+	testCases := []struct {
+		in       string
+		code     BreedingCode
+		ok       bool
+		category BreedingCategory
+		str      string
+	}{
+		{"NY Nest with Young", "NY", true, ConfirmedBreeding, "NY Nest with Young (Confirmed)"},
+		{"CF", "CF", true, ConfirmedBreeding, "CF Carrying Food (Confirmed)"},
+		{" s7 Singing Male Present 7+ Days", "S7", true, ProbableBreeding, "S7 Singing Male Present 7+ Days (Probable)"},
+		{"S Singing Male", "S", true, PossibleBreeding, "S Singing Male (Possible)"},
+		{"F", "F", true, ObservedBreeding, "F Flyover (Observed)"},
+		{"", "", false, UnknownBreeding, ""},
+		{"ZZ Something", "", false, UnknownBreeding, ""},
+	}
+	for _, tc := range testCases {
+		code, ok := ParseBreedingCode(tc.in)
+		if code != tc.code || ok != tc.ok {
+			t.Errorf("ParseBreedingCode(%q) = %q, %v; want %q, %v", tc.in, code, ok, tc.code, tc.ok)
+			continue
+		}
+		if got := code.Category(); got != tc.category {
+			t.Errorf("%q.Category() = %v, want %v", code, got, tc.category)
+		}
+		if got := code.String(); got != tc.str {
+			t.Errorf("%q.String() = %q, want %q", code, got, tc.str)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package ebird
+
+import (
+	"context"
+	"iter"
+	"strconv"
+)
+
+// Checklist is an eBird checklist: the records that share a submission ID.
+// The checklist-level fields are copied from its first record;
+// eBird repeats them on every record of the checklist.
+type Checklist struct {
+	SubmissionID string
+	Records      []Record
+
+	LocationID         string
+	Location           string
+	StateProvince      string
+	County             string
+	Date               string // YYYY-MM-DD
+	Time               string // 07:00 AM
+	Protocol           string
+	DurationMin        string
+	AllObsReported     string // "1" means yes
+	DistanceTraveledKm string
+	AreaCoveredHa      string
+	NumberOfObservers  string
+	ChecklistComments  string
+}
+
+// NewChecklist returns the checklist containing records,
+// which must be non-empty and share a submission ID.
+func NewChecklist(records []Record) Checklist {
+	r := records[0]
+	return Checklist{
+		SubmissionID:       CanonicalSubmissionID(r.SubmissionID),
+		Records:            records,
+		LocationID:         r.LocationID,
+		Location:           r.Location,
+		StateProvince:      r.StateProvince,
+		County:             r.County,
+		Date:               r.Date,
+		Time:               r.Time,
+		Protocol:           r.Protocol,
+		DurationMin:        r.DurationMin,
+		AllObsReported:     r.AllObsReported,
+		DistanceTraveledKm: r.DistanceTraveledKm,
+		AreaCoveredHa:      r.AreaCoveredHa,
+		NumberOfObservers:  r.NumberOfObservers,
+		ChecklistComments:  r.ChecklistComments,
+	}
+}
+
+// Species returns the scientific names on the checklist, in order.
+func (c Checklist) Species() []string {
+	names := make([]string, len(c.Records))
+	for i, r := range c.Records {
+		names[i] = r.ScientificName
+	}
+	return names
+}
+
+// GroupChecklists groups records into checklists by canonical submission ID,
+// in the order of each checklist's first record. eBird usually exports a
+// checklist's records together, but not always, so GroupChecklists reads
+// all the records before yielding the first checklist.
+func GroupChecklists(records iter.Seq[Record]) iter.Seq[Checklist] {
+	return func(yield func(Checklist) bool) {
+		var ids []string
+		byID := map[string][]Record{}
+		for rec := range records {
+			id := CanonicalSubmissionID(rec.SubmissionID)
+			if _, ok := byID[id]; !ok {
+				ids = append(ids, id)
+			}
+			byID[id] = append(byID[id], rec)
+		}
+		for _, id := range ids {
+			if !yield(NewChecklist(byID[id])) {
+				return
+			}
+		}
+	}
+}
+
+// Checklists returns the checklists in the MyEBirdData.csv file filename.
+// See Records and GroupChecklists. Unlike Records, it holds all the
+// records in memory during each iteration. If reading the file fails,
+// the iteration yields the error and stops, as it does if ctx is done.
+func Checklists(ctx context.Context, filename string) (iter.Seq2[Checklist, error], error) {
+	records, err := Records(ctx, filename)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(Checklist, error) bool) {
+		// GroupChecklists reads all the records first,
+		// so any error is known before the first checklist.
+		var readErr error
+		for c := range GroupChecklists(UntilError(records, &readErr)) {
+			if readErr != nil {
+				break
+			}
+			if !yield(c, nil) {
+				return
+			}
+		}
+		if readErr != nil {
+			yield(Checklist{}, readErr)
+		}
+	}, nil
+}
+
+// Centroid returns the mean coordinates of the checklist's records.
+// Species rows on a checklist share one location, so this is usually
+// just that location's coordinates. Records with missing or unparseable
+// coordinates are ignored; ok is false if no record has coordinates.
+func (c Checklist) Centroid() (lat, lng float64, ok bool) {
+	n := 0
+	for _, r := range c.Records {
+		rlat, err := strconv.ParseFloat(r.Latitude, 64)
+		if err != nil {
+			continue
+		}
+		rlng, err := strconv.ParseFloat(r.Longitude, 64)
+		if err != nil {
+			continue
+		}
+		lat += rlat
+		lng += rlng
+		n++
+	}
+	if n == 0 {
+		return 0, 0, false
+	}
+	return lat / float64(n), lng / float64(n), true
+}
@@ -0,0 +1,75 @@
+package ebird
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestChecklist_Centroid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		coords   [][2]string
+		lat, lng float64
+		ok       bool
+	}{
+		{"shared location", [][2]string{{"37.5", "-122.25"}, {"37.5", "-122.25"}}, 37.5, -122.25, true},
+		{"different locations", [][2]string{{"37", "-122"}, {"38", "-123"}}, 37.5, -122.5, true},
+		{"some missing", [][2]string{{"", ""}, {"37.5", "-122.25"}}, 37.5, -122.25, true},
+		{"all missing", [][2]string{{"", ""}, {"", ""}}, 0, 0, false},
+		{"no records", nil, 0, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := Checklist{SubmissionID: "S1"}
+			for _, ll := range tc.coords {
+				c.Records = append(c.Records, Record{SubmissionID: "S1", Latitude: ll[0], Longitude: ll[1]})
+			}
+			lat, lng, ok := c.Centroid()
+			if lat != tc.lat || lng != tc.lng || ok != tc.ok {
+				t.Errorf("Centroid() = %v, %v, %v; want %v, %v, %v", lat, lng, ok, tc.lat, tc.lng, tc.ok)
+			}
+		})
+	}
+}
+
+func TestGroupChecklists(t *testing.T) {
+	records := []Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Location: "Some Park", Protocol: "Stationary"},
+		{SubmissionID: "S2", ScientificName: "Zenaida macroura", Location: "Other Park"},
+		{SubmissionID: "S1.2", ScientificName: "Cardinalis cardinalis", Location: "Some Park"}, // revised, out of order
+	}
+	var got []Checklist
+	for c := range GroupChecklists(slices.Values(records)) {
+		got = append(got, c)
+	}
+	if len(got) != 2 {
+		t.Fatalf("GroupChecklists() returned %d checklists, want 2", len(got))
+	}
+	if got[0].SubmissionID != "S1" || got[0].Location != "Some Park" || got[0].Protocol != "Stationary" {
+		t.Errorf("First checklist = %+v, want S1 at Some Park", got[0])
+	}
+	if species := got[0].Species(); !slices.Equal(species, []string{"Turdus migratorius", "Cardinalis cardinalis"}) {
+		t.Errorf("S1 species = %v", species)
+	}
+	if got[1].SubmissionID != "S2" || len(got[1].Records) != 1 {
+		t.Errorf("Second checklist = %+v, want S2 with 1 record", got[1])
+	}
+}
+
+func TestChecklists(t *testing.T) {
+	checklists, err := Checklists(context.Background(), "testdata/ragged.csv")
+	if err != nil {
+		t.Fatalf("Checklists() error: %v", err)
+	}
+	var ids []string
+	for c, err := range checklists {
+		if err != nil {
+			t.Fatalf("Checklists() iteration error: %v", err)
+		}
+		ids = append(ids, c.SubmissionID)
+	}
+	if !slices.Equal(ids, []string{"S100", "S101", "S102"}) {
+		t.Errorf("Checklists() = %v, want [S100 S101 S102]", ids)
+	}
+}
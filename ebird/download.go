@@ -0,0 +1,98 @@
+package ebird
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// MLDownload is the result of downloading an ML asset with DownloadMLAssets.
+// Filename and Kind are as returned by DownloadMLAsset.
+type MLDownload struct {
+	ID       string
+	Filename string
+	Kind     MediaKind
+	Err      error
+}
+
+// DownloadMLAssets downloads the ML assets with the provided IDs using
+// up to workers concurrent downloads (at least one), and returns the
+// results in the order of ids. A failed download doesn't stop the others;
+// check each result's Err.
+//
+// If progress is non-nil, it's called as each download finishes with the
+// result and the number of downloads finished so far. Calls to progress
+// are serialized, but they're in the order the downloads finish.
+//
+// If ctx is done, downloads in progress stop, and those not yet started
+// fail with ctx.Err() without being attempted.
+//
+// DownloadMLAssets prunes MLCacheDir once all the downloads are done,
+// keeping every file it returns. Remove the files that aren't cached
+// with RemoveMLDownloads when they're no longer needed.
+func DownloadMLAssets(ctx context.Context, ids []string, workers int, progress func(d MLDownload, done, total int)) []MLDownload {
+	results := downloadAll(ctx, ids, workers, downloadMLAssetCached, progress)
+	var cached []string
+	for _, d := range results {
+		if d.Err == nil && inMLCache(d.Filename) {
+			cached = append(cached, d.Filename)
+		}
+	}
+	if len(cached) > 0 {
+		pruneMLCacheOrLog(cached...)
+	}
+	return results
+}
+
+// RemoveMLDownloads removes the files downloaded by DownloadMLAssets,
+// except those in MLCacheDir, which are kept for later runs. Call it once
+// the files have been uploaded or aren't needed, such as after a failure.
+func RemoveMLDownloads(downloads []MLDownload) error {
+	var errs []error
+	for _, d := range downloads {
+		if d.Err != nil || d.Filename == "" || inMLCache(d.Filename) {
+			continue
+		}
+		if err := os.Remove(d.Filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// downloadAll implements DownloadMLAssets using download.
+func downloadAll(ctx context.Context, ids []string, workers int, download func(context.Context, string) (string, MediaKind, error), progress func(MLDownload, int, int)) []MLDownload {
+	results := make([]MLDownload, len(ids))
+	workers = max(1, min(workers, len(ids)))
+	next := make(chan int)
+	var mu sync.Mutex // serializes progress
+	done := 0
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				d := MLDownload{ID: ids[i]}
+				if d.Err = ctx.Err(); d.Err == nil {
+					d.Filename, d.Kind, d.Err = download(ctx, ids[i])
+				}
+				results[i] = d
+				if progress != nil {
+					mu.Lock()
+					done++
+					progress(d, done, len(ids))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range ids {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,112 @@
+package ebird
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDownloadAll(t *testing.T) {
+	ids := []string{"1", "2", "3", "4", "5", "6", "7"}
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	download := func(ctx context.Context, id string) (string, MediaKind, error) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if id == "4" {
+			return "", UnknownMedia, errors.New("not found")
+		}
+		if id == "2" {
+			return "/tmp/" + id, Photo, nil
+		}
+		return "/tmp/" + id, Sound, nil
+	}
+	var progress []int
+	results := downloadAll(context.Background(), ids, 3, download, func(d MLDownload, done, total int) {
+		if total != len(ids) {
+			t.Errorf("progress total = %d, want %d", total, len(ids))
+		}
+		progress = append(progress, done)
+	})
+
+	if maxRunning > 3 || maxRunning < 2 {
+		t.Errorf("Ran %d downloads at once, want 2 or 3 (3 workers)", maxRunning)
+	}
+	if len(progress) != len(ids) || progress[len(progress)-1] != len(ids) {
+		t.Errorf("progress = %v, want 1 through %d", progress, len(ids))
+	}
+	for i, r := range results {
+		if r.ID != ids[i] {
+			t.Errorf("results[%d].ID = %s, want %s (in order)", i, r.ID, ids[i])
+		}
+		if (r.Err != nil) != (r.ID == "4") {
+			t.Errorf("results[%d].Err = %v", i, r.Err)
+		}
+	}
+	if results[1].Kind != Photo || results[2].Filename != "/tmp/3" {
+		t.Errorf("results = %+v", results)
+	}
+
+	// Zero workers means one, and no IDs means no results.
+	if got := downloadAll(context.Background(), ids[:2], 0, download, nil); len(got) != 2 {
+		t.Errorf("downloadAll with 0 workers returned %d results, want 2", len(got))
+	}
+	if got := downloadAll(context.Background(), nil, 4, download, nil); len(got) != 0 {
+		t.Errorf("downloadAll(nil) returned %d results", len(got))
+	}
+}
+
+func TestDownloadAllCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	downloaded := 0
+	download := func(ctx context.Context, id string) (string, MediaKind, error) {
+		downloaded++
+		cancel() // after the first download
+		return "/tmp/" + id, Photo, nil
+	}
+	results := downloadAll(ctx, []string{"1", "2", "3"}, 1, download, nil)
+	if downloaded != 1 {
+		t.Errorf("Downloaded %d assets after canceling, want 1", downloaded)
+	}
+	if results[0].Err != nil || !errors.Is(results[1].Err, context.Canceled) || !errors.Is(results[2].Err, context.Canceled) {
+		t.Errorf("results = %+v, want the first to succeed and the rest canceled", results)
+	}
+}
+
+func TestRemoveMLDownloads(t *testing.T) {
+	defer func(dir string) { MLCacheDir = dir }(MLCacheDir)
+	MLCacheDir = t.TempDir()
+	tmp := filepath.Join(t.TempDir(), "ml-1.jpg")
+	cached := filepath.Join(MLCacheDir, "2.jpg")
+	for _, name := range []string{tmp, cached} {
+		if err := os.WriteFile(name, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := RemoveMLDownloads([]MLDownload{
+		{ID: "1", Filename: tmp},
+		{ID: "2", Filename: cached},
+		{ID: "3", Err: errors.New("not found")},
+		{ID: "4", Kind: Video}, // not downloaded
+	})
+	if err != nil {
+		t.Fatalf("RemoveMLDownloads() error = %v", err)
+	}
+	if _, err := os.Stat(tmp); err == nil {
+		t.Errorf("RemoveMLDownloads() kept %s, want it removed", tmp)
+	}
+	if _, err := os.Stat(cached); err != nil {
+		t.Errorf("RemoveMLDownloads() removed the cached %s: %v", cached, err)
+	}
+}
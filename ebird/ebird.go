@@ -2,14 +2,21 @@
 package ebird
 
 import (
+	"archive/zip"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
 	"iter"
 	"log"
+	"math"
 	"mime"
 	"net/http"
 	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -45,6 +52,7 @@ type Record struct {
 	ObservationDetails string
 	ChecklistComments  string
 	MLCatalogNumbers   string
+	ExoticCode         string // in newer exports; see Exotic
 }
 
 func (r Record) URL() string {
@@ -58,88 +66,433 @@ func (r Record) URLWithSpecies() string {
 // Observed returns the observation time for this record.
 // The record always includes the date but might not include the time.
 // The date and time formats vary between users for reasons I don't understand.
+// Observed interprets the date and time as UTC, so the result is the
+// wall-clock time where the bird was seen, not the instant. That's what
+// filtering and matching by local date and time need; for the instant,
+// see ObservedIn and ObservedInRegion.
 func (r Record) Observed() (time.Time, error) {
+	return r.ObservedIn(time.UTC)
+}
+
+// ObservedIn is like Observed but interprets the record's date and time,
+// which are local to where the bird was observed, in the time zone loc.
+func (r Record) ObservedIn(loc *time.Location) (time.Time, error) {
 	if r.Time == "" {
 		if strings.Contains(r.Date, "/") {
-			return time.Parse("1/2/2006", r.Date)
+			return time.ParseInLocation("1/2/2006", r.Date, loc)
 		} else {
-			return time.Parse("2006-01-02", r.Date)
+			return time.ParseInLocation("2006-01-02", r.Date, loc)
 		}
 	}
 	if strings.Contains(r.Date, "/") {
-		return time.Parse("1/2/2006 3:04 PM", r.Date+" "+r.Time)
+		return time.ParseInLocation("1/2/2006 3:04 PM", r.Date+" "+r.Time, loc)
 	} else {
-		return time.Parse("2006-01-02 03:04 PM", r.Date+" "+r.Time)
+		return time.ParseInLocation("2006-01-02 03:04 PM", r.Date+" "+r.Time, loc)
+	}
+}
+
+// PlausibleDate reports whether the record was observed between 1800
+// and tomorrow. Dates outside that range are almost always data-entry typos
+// (year 0019 instead of 2019, or 2202 instead of 2022).
+// Records with unparseable dates are not plausible.
+func (r Record) PlausibleDate() bool {
+	return r.PlausibleDateAt(time.Now())
+}
+
+// PlausibleDateAt is like PlausibleDate but treats now as the current time.
+func (r Record) PlausibleDateAt(now time.Time) bool {
+	observed, err := r.Observed()
+	if err != nil {
+		return false
+	}
+	earliest := time.Date(1800, 1, 1, 0, 0, 0, 0, time.UTC)
+	latest := now.AddDate(0, 0, 1)
+	return !observed.Before(earliest) && observed.Before(latest)
+}
+
+// SuspiciousCoordinates reports whether the record's latitude and longitude
+// are obviously wrong. It's a cheap heuristic, not a geographic lookup,
+// so it can't tell whether a point is in the ocean. It flags:
+//   - (0, 0), "Null Island" in the Atlantic, which is what missing
+//     coordinates often turn into;
+//   - coordinates that aren't numbers;
+//   - latitudes beyond ±90 or longitudes beyond ±180.
+//
+// Records with no coordinates at all aren't suspicious, just missing.
+func (r Record) SuspiciousCoordinates() bool {
+	if r.Latitude == "" && r.Longitude == "" {
+		return false
+	}
+	lat, err := strconv.ParseFloat(r.Latitude, 64)
+	if err != nil {
+		return true
+	}
+	lng, err := strconv.ParseFloat(r.Longitude, 64)
+	if err != nil {
+		return true
+	}
+	if lat == 0 && lng == 0 {
+		return true
+	}
+	return math.Abs(lat) > 90 || math.Abs(lng) > 180
+}
+
+// Accuracy returns the positional accuracy in meters of this record's
+// location, given base, the accuracy of the checklist location itself
+// (typically PositionalAccuracy).
+//
+// Birds on a stationary count were seen from one spot, so their accuracy
+// is exactly base, even if the record has a (bogus) distance traveled.
+// Birds on traveling checklists may have been seen anywhere along the route,
+// so the distance traveled is added to base.
+func (r Record) Accuracy(base int) int {
+	if ParseProtocol(r.Protocol) == Stationary {
+		return base
 	}
+	km, err := strconv.ParseFloat(r.DistanceTraveledKm, 64)
+	if err != nil || km <= 0 {
+		return base
+	}
+	return base + int(math.Round(km*1000))
+}
+
+// SpansMidnight reports whether the checklist continued past midnight:
+// its start time plus its duration is on a later day than its start.
+// Such records are still dated by their start, which is what eBird shows.
+// Records without a time or duration don't span midnight.
+func (r Record) SpansMidnight() bool {
+	if r.Time == "" {
+		return false
+	}
+	minutes, err := strconv.Atoi(r.DurationMin)
+	if err != nil || minutes <= 0 {
+		return false
+	}
+	start, err := r.Observed()
+	if err != nil {
+		return false
+	}
+	end := start.Add(time.Duration(minutes) * time.Minute)
+	return end.YearDay() != start.YearDay() || end.Year() != start.Year()
+}
+
+// CountInt returns the number of birds counted.
+// ok is false if the count is "X", which means the species was present
+// but not counted, or if the count isn't otherwise a number.
+func (r Record) CountInt() (n int, ok bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(r.Count))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
 }
 
+// TaxonomicOrderFloat returns the record's position in the eBird taxonomy.
+// Orders can be fractional for taxa added between existing ones.
+// ok is false if the order is missing, zero, or not a number,
+// as it is for some spuhs and in some older exports.
+func (r Record) TaxonomicOrderFloat() (order float64, ok bool) {
+	order, err := strconv.ParseFloat(strings.TrimSpace(r.TaxonomicOrder), 64)
+	if err != nil || order <= 0 {
+		return 0, false
+	}
+	return order, true
+}
+
+// AllObsCompleted reports whether the record is on a complete checklist,
+// one on which the observer reported all the species they identified.
+// Absence of a species from a complete checklist is meaningful.
+func (r Record) AllObsCompleted() bool {
+	return r.AllObsReported == "1"
+}
+
+// ObservationID returns the ID of this observation.
+// Its submission ID is canonical; see CanonicalSubmissionID.
 func (r Record) ObservationID() ObservationID {
-	return ObservationID{r.SubmissionID, r.ScientificName}
+	return ObservationID{CanonicalSubmissionID(r.SubmissionID), r.ScientificName}
+}
+
+// Records returns the records in the MyEBirdData.csv file filename.
+// If filename ends in .zip, it's read as the ZIP archive eBird delivers
+// the export in; see RecordsFromZip.
+//
+// Records reads the header now, so that a missing or empty file is
+// reported right away, but it streams the remaining rows: each iteration
+// reopens the file and reads it one row at a time, so large exports don't
+// need to fit in memory. If reopening or reading the file fails during an
+// iteration, the iteration yields the error and stops. Warnings about
+// malformed rows are logged only during the first iteration.
+// Unknown columns are logged, or reported as an error if StrictHeader is set.
+//
+// If ctx is done, an iteration yields ctx.Err() and stops.
+func Records(ctx context.Context, filename string) (iter.Seq2[Record, error], error) {
+	if strings.EqualFold(filepath.Ext(filename), ".zip") {
+		return RecordsFromZip(ctx, filename)
+	}
+	return records(ctx, filename, func() (io.ReadCloser, error) {
+		return os.Open(filename)
+	})
 }
 
-func Records(filename string) (iter.Seq[Record], error) {
-	f, err := os.Open(filename)
+// ExportFilename is the name of the CSV file in eBird's data export.
+const ExportFilename = "MyEBirdData.csv"
+
+// RecordsFromZip is like Records but reads MyEBirdData.csv from
+// the ZIP archive filename, such as ebird_1234.zip, without
+// extracting it.
+func RecordsFromZip(ctx context.Context, filename string) (iter.Seq2[Record, error], error) {
+	return records(ctx, filename, func() (io.ReadCloser, error) {
+		z, err := zip.OpenReader(filename)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range z.File {
+			if !strings.EqualFold(path.Base(f.Name), ExportFilename) {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				z.Close()
+				return nil, err
+			}
+			return zipFileReader{r, z}, nil
+		}
+		z.Close()
+		return nil, fmt.Errorf("no %s in archive", ExportFilename)
+	})
+}
+
+// zipFileReader reads a file in a ZIP archive and closes both when it's closed.
+type zipFileReader struct {
+	io.ReadCloser
+	archive io.Closer
+}
+
+func (r zipFileReader) Close() error {
+	err := r.ReadCloser.Close()
+	if cerr := r.archive.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// RecordsFromReader is like Records but reads CSV data from r, such as
+// an HTTP response body or standard input. Since r can only be read once,
+// the records can only be iterated once: later iterations yield an error.
+// RecordsFromReader reads the header now; the caller must not use r
+// while iterating.
+func RecordsFromReader(ctx context.Context, r io.Reader) (iter.Seq2[Record, error], error) {
+	const name = "reader"
+	cr := newCSVReader(r)
+	header, field, err := readHeader(name, cr)
 	if err != nil {
-		log.Fatalf("ebird.Records(%s): %v", filename, err)
+		return nil, err
+	}
+	if err := checkHeader(name, header); err != nil {
+		return nil, err
 	}
-	defer f.Close()
+	read := false
+	return func(yield func(Record, error) bool) {
+		if read {
+			yield(Record{}, fmt.Errorf("Records(%s): can't read records more than once", name))
+			return
+		}
+		read = true
+		yieldRows(ctx, name, cr, len(header), field, true, yield)
+	}, nil
+}
 
-	r := csv.NewReader(f)
-	// eBird's CSV export returns a variable number of fields per record,
-	// so disable this check. This means we need to explicitly check len(rec)
-	// before accessing fields that might not be there.
-	r.FieldsPerRecord = -1
-	recs, err := r.ReadAll()
+// records returns the records in the CSV data returned by open,
+// which is called once now and again for each iteration.
+// name identifies the data in errors and warnings.
+func records(ctx context.Context, name string, open func() (io.ReadCloser, error)) (iter.Seq2[Record, error], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("Records(%s): %w", name, err)
+	}
+	f, err := open()
 	if err != nil {
-		log.Fatalf("Error reading CSV records from %s: %v", filename, err)
+		return nil, fmt.Errorf("Records(%s): %w", name, err)
 	}
-	if len(recs) < 1 {
-		log.Fatalf("No records found in %s", filename)
+	header, field, err := readHeader(name, newCSVReader(f))
+	f.Close()
+	if err != nil {
+		return nil, err
 	}
-	field := make(map[string]int)
-	for i, f := range recs[0] {
-		field[f] = i
+	if err := checkHeader(name, header); err != nil {
+		return nil, err
+	}
+	warn := true
+	return func(yield func(Record, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(Record{}, fmt.Errorf("Records(%s): %w", name, err))
+			return
+		}
+		f, err := open()
+		if err != nil {
+			yield(Record{}, fmt.Errorf("Records(%s): %w", name, err))
+			return
+		}
+		defer f.Close()
+		warned := warn
+		warn = false
+
+		r := newCSVReader(f)
+		if _, err := r.Read(); err != nil { // header
+			yield(Record{}, fmt.Errorf("Records(%s): %w", name, err))
+			return
+		}
+		yieldRows(ctx, name, r, len(header), field, warned, yield)
+	}, nil
+}
+
+// readHeader reads the header from r and returns it along with the index
+// of each column name, as returned by headerFields.
+// It logs a warning about any duplicate column names.
+func readHeader(name string, r *csv.Reader) (header []string, field map[string]int, err error) {
+	header, err = r.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("Records(%s): no records found", name)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("Records(%s): %w", name, err)
+	}
+	header = slices.Clone(header) // r reuses its records
+	for _, f := range duplicateColumns(header) {
+		log.Printf("%s: duplicate column %q in header; using the first one", name, f)
+	}
+	return header, headerFields(header), nil
+}
+
+// yieldRows yields a Record for each row remaining in r, which has
+// already read the header. width is the number of columns in the header,
+// and field maps their names to indexes. If warn is set, it logs warnings
+// about rows with extra fields. It stops at the first error, which it yields,
+// including ctx.Err() if ctx is done.
+func yieldRows(ctx context.Context, name string, r *csv.Reader, width int, field map[string]int, warn bool, yield func(Record, error) bool) {
+	for line := 2; ; line++ { // header was line 1
+		if err := ctx.Err(); err != nil {
+			yield(Record{}, fmt.Errorf("Records(%s): line %d: %w", name, line, err))
+			return
+		}
+		row, err := r.Read()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			yield(Record{}, fmt.Errorf("Records(%s): %w", name, err))
+			return
+		}
+		row, extra := trimRow(row, width)
+		if extra && warn {
+			log.Printf("%s: line %d has %d fields but the header has %d; ignoring the extra fields",
+				name, line, len(row), width)
+		}
+		if !yield(parseRecord(field, row, line), nil) {
+			return
+		}
 	}
-	recs = recs[1:]
-	log.Printf("Read %d eBird observations", len(recs))
+}
+
+// UntilError returns the records in records up to the first error,
+// which it stores in *err. Callers should check *err after iterating.
+// It lets functions that take an iter.Seq[Record] read from [Records].
+func UntilError(records iter.Seq2[Record, error], err *error) iter.Seq[Record] {
 	return func(yield func(Record) bool) {
-		for i, rec := range recs {
-			stringField := func(key string) string {
-				if f := field[key]; f < len(rec) {
-					return rec[f]
-				}
-				return ""
+		for rec, e := range records {
+			if e != nil {
+				*err = e
+				return
 			}
-			if !yield(Record{
-				Line:               i + 2, // header was line 1
-				SubmissionID:       stringField("Submission ID"),
-				CommonName:         stringField("Common Name"),
-				ScientificName:     stringField("Scientific Name"),
-				TaxonomicOrder:     stringField("Taxonomic Order"),
-				Count:              stringField("Count"),
-				StateProvince:      stringField("State/Province"),
-				County:             stringField("County"),
-				LocationID:         stringField("Location ID"),
-				Location:           stringField("Location"),
-				Latitude:           stringField("Latitude"),
-				Longitude:          stringField("Longitude"),
-				Date:               stringField("Date"),
-				Time:               stringField("Time"),
-				Protocol:           stringField("Protocol"),
-				DurationMin:        stringField("Duration (Min)"),
-				AllObsReported:     stringField("All Obs Reported"),
-				DistanceTraveledKm: stringField("Distance Traveled (km)"),
-				AreaCoveredHa:      stringField("Area Covered (ha)"),
-				NumberOfObservers:  stringField("Number of Observers"),
-				BreedingCode:       stringField("Breeding Code"),
-				ObservationDetails: stringField("Observation Details"),
-				ChecklistComments:  stringField("Checklist Comments"),
-				MLCatalogNumbers:   stringField("ML Catalog Numbers"),
-			}) {
+			if !yield(rec) {
 				return
 			}
 		}
-	}, nil
+	}
+}
+
+// newCSVReader returns a CSV reader for MyEBirdData.csv data in r.
+func newCSVReader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	// eBird's CSV export returns a variable number of fields per record,
+	// so disable this check. This means we need to explicitly check len(rec)
+	// before accessing fields that might not be there.
+	cr.FieldsPerRecord = -1
+	// Records copy the fields they need, so the row slice can be reused.
+	cr.ReuseRecord = true
+	return cr
+}
+
+// headerFields maps each column name in header to its index.
+// If a column name appears more than once, the first one is used.
+func headerFields(header []string) map[string]int {
+	field := make(map[string]int)
+	for i, f := range header {
+		if _, ok := field[f]; !ok {
+			field[f] = i
+		}
+	}
+	return field
+}
+
+// duplicateColumns returns the column names that appear more than once in header.
+func duplicateColumns(header []string) []string {
+	var dups []string
+	seen := map[string]bool{}
+	for _, f := range header {
+		if seen[f] {
+			dups = append(dups, f)
+		}
+		seen[f] = true
+	}
+	return dups
+}
+
+// parseRecord returns the Record for row, which is on the provided line.
+// field maps column names to indexes in row.
+func parseRecord(field map[string]int, row []string, line int) Record {
+	stringField := func(key string) string {
+		if f, ok := field[key]; ok && f < len(row) {
+			return row[f]
+		}
+		return ""
+	}
+	return Record{
+		Line:               line,
+		SubmissionID:       stringField("Submission ID"),
+		CommonName:         stringField("Common Name"),
+		ScientificName:     stringField("Scientific Name"),
+		TaxonomicOrder:     stringField("Taxonomic Order"),
+		Count:              stringField("Count"),
+		StateProvince:      stringField("State/Province"),
+		County:             stringField("County"),
+		LocationID:         stringField("Location ID"),
+		Location:           stringField("Location"),
+		Latitude:           stringField("Latitude"),
+		Longitude:          stringField("Longitude"),
+		Date:               stringField("Date"),
+		Time:               stringField("Time"),
+		Protocol:           stringField("Protocol"),
+		DurationMin:        stringField("Duration (Min)"),
+		AllObsReported:     stringField("All Obs Reported"),
+		DistanceTraveledKm: stringField("Distance Traveled (km)"),
+		AreaCoveredHa:      stringField("Area Covered (ha)"),
+		NumberOfObservers:  stringField("Number of Observers"),
+		BreedingCode:       stringField("Breeding Code"),
+		ObservationDetails: stringField("Observation Details"),
+		ChecklistComments:  stringField("Checklist Comments"),
+		MLCatalogNumbers:   stringField("ML Catalog Numbers"),
+		ExoticCode:         stringField("Exotic Code"),
+	}
+}
+
+// trimRow removes trailing empty fields beyond the header width from row.
+// eBird sometimes emits rows with extra trailing commas.
+// It reports whether the row still has more fields than the header.
+func trimRow(row []string, width int) ([]string, bool) {
+	for len(row) > width && row[len(row)-1] == "" {
+		row = row[:len(row)-1]
+	}
+	return row, len(row) > width
 }
 
 // ObservationID identifies a unique eBird observation
@@ -160,6 +513,28 @@ type ObservationID struct {
 	ScientificName string
 }
 
+// CanonicalSubmissionID returns the base form of an eBird submission ID:
+// "S" followed by the checklist number. It trims surrounding space,
+// uppercases the "S", and drops any revision suffix after the number,
+// so "S12345", " s12345", "S12345.2", "S12345-1", and "S12345v3" are
+// all "S12345". That way a revised checklist is recognized as the same
+// checklist when it's re-exported. IDs that don't start with "S" and
+// a number are returned with only the space trimmed.
+func CanonicalSubmissionID(id string) string {
+	id = strings.TrimSpace(id)
+	if len(id) < 2 || (id[0] != 'S' && id[0] != 's') {
+		return id
+	}
+	n := 1
+	for n < len(id) && '0' <= id[n] && id[n] <= '9' {
+		n++
+	}
+	if n == 1 {
+		return id
+	}
+	return "S" + id[1:n]
+}
+
 // Valid returns whether this observation ID has all fields set.
 func (o ObservationID) Valid() bool {
 	return o.SubmissionID != "" && o.ScientificName != ""
@@ -169,72 +544,215 @@ func (o ObservationID) String() string {
 	return fmt.Sprintf("%s[%s]", o.SubmissionID, o.ScientificName)
 }
 
-// DownloadMLAsset downloads the photo or sound with the provided ML asset ID
-// (numbers only) and returns the local filename and whether it's a photo.
-// This file is temporary and may be deleted at any time.
+// DownloadMLAsset downloads the photo, sound, or video with the provided
+// ML asset ID (numbers only) and returns the local filename and the kind
+// of media. This file is temporary and may be deleted at any time.
+// It's in MLTempDir; use DownloadMLAssetTo to choose the directory,
+// or WriteMLAsset to skip the file.
+//
+// Since the ML asset ID doesn't indicate what kind of media it is,
+// we look it up with GetMLAssetInfo and download it from the URL for
+// that kind. If the lookup fails, we try downloading it as a photo first,
+// then as a sound, and then as a video, which are the rarest. iNaturalist doesn't accept videos,
+// so for a video, DownloadMLAsset only checks that it exists, with a HEAD
+// request, and returns an empty filename and Video. Use DownloadMLAssetTo
+// or WriteMLAsset to download videos.
+//
+// If the Macaulay Library fails with a server error or the connection
+// breaks, DownloadMLAsset tries again, up to MLMaxAttempts times in all,
+// waiting longer each time (see MLRetryDelay).
+//
+// If MLCacheDir is set, DownloadMLAsset returns the cached file instead,
+// if it has one, and otherwise caches the download. Callers mustn't
+// modify or remove cached files.
 //
-// Since the ML asset ID doesn't indicate whether this is a photo or sound file,
-// we try downloading the photo file first, and if it's not there,
-// we try downloading the sound file.
-func DownloadMLAsset(mlAssetID string) (string, bool, error) {
-	// Try fetching this ML asset as a photo
-	url := fmt.Sprintf("https://cdn.download.ams.birds.cornell.edu/api/v2/asset/%s/2400", mlAssetID)
-	resp, err := http.Get(url)
+// Canceling ctx stops the download, including any wait to retry it.
+func DownloadMLAsset(ctx context.Context, mlAssetID string) (string, MediaKind, error) {
+	filename, kind, err := downloadMLAssetCached(ctx, mlAssetID)
+	if err == nil && inMLCache(filename) {
+		pruneMLCacheOrLog(filename)
+	}
+	return filename, kind, err
+}
+
+// downloadMLAssetCached implements DownloadMLAsset without pruning the cache.
+func downloadMLAssetCached(ctx context.Context, mlAssetID string) (string, MediaKind, error) {
+	if filename, kind, ok := cachedMLAsset(mlAssetID); ok {
+		return filename, kind, nil
+	}
+	filename, kind, err := downloadMLAssetTo(ctx, mlAssetID, MLTempDir, false)
 	if err != nil {
-		return "", false, fmt.Errorf("DownloadMLAsset(%s): %s: %w", mlAssetID, url, err)
-	}
-	defer resp.Body.Close()
-	isPhoto := resp.StatusCode == http.StatusOK
-	if resp.StatusCode == http.StatusNotFound {
-		// Photo not found; try fetching it as a sound
-		url = fmt.Sprintf("https://cdn.download.ams.birds.cornell.edu/api/v2/asset/%s/mp3", mlAssetID)
-		resp, err = http.Get(url)
+		return "", kind, fmt.Errorf("DownloadMLAsset(%s): %w", mlAssetID, err)
+	}
+	if MLCacheDir != "" && filename != "" {
+		cached, err := cacheMLAsset(mlAssetID, filename)
 		if err != nil {
-			return "", isPhoto, fmt.Errorf("DownloadMLAsset(%s): %s: %w", mlAssetID, url, err)
+			// The download is still usable without the cache.
+			log.Printf("DownloadMLAsset(%s): can't cache in %s: %v", mlAssetID, MLCacheDir, err)
+		} else {
+			filename = cached
 		}
-		defer resp.Body.Close()
-	}
-	if resp.StatusCode != http.StatusOK {
-		return "", isPhoto, fmt.Errorf("DownloadMLAsset(%s): %s: %s", mlAssetID, url, resp.Status)
 	}
+	return filename, kind, nil
+}
 
-	tmpFile, err := os.CreateTemp("", "birdsync")
+// DownloadMLAssetTo is like DownloadMLAsset but saves the asset in the
+// directory dir, such as a large scratch volume, rather than MLTempDir.
+// It doesn't use MLCacheDir, and the file is the caller's to keep or remove.
+func DownloadMLAssetTo(ctx context.Context, mlAssetID, dir string) (string, MediaKind, error) {
+	filename, kind, err := downloadMLAssetTo(ctx, mlAssetID, dir, true)
 	if err != nil {
-		return "", isPhoto, fmt.Errorf("DownloadMLAsset(%s): CreateTemp: %w", mlAssetID, err)
+		return "", kind, fmt.Errorf("DownloadMLAssetTo(%s): %w", mlAssetID, err)
 	}
-	_, err = io.Copy(tmpFile, resp.Body)
+	return filename, kind, nil
+}
+
+// WriteMLAsset writes the photo, sound, or video with the provided
+// ML asset ID to w, such as the request body of an upload, and returns
+// the kind of media. It tries again after failures like DownloadMLAsset,
+// but only until it starts writing to w: since w can't be rewound,
+// a failure after that leaves part of the asset in w and returns an error.
+// It doesn't use MLCacheDir.
+func WriteMLAsset(ctx context.Context, w io.Writer, mlAssetID string) (MediaKind, error) {
+	probes := mlProbes(ctx, mlAssetID)
+	kind, err := retryMLAsset(ctx, mlAssetID, func() (MediaKind, error) {
+		return fetchMLAsset(ctx, probes, w, false, true)
+	})
 	if err != nil {
-		return "", isPhoto, fmt.Errorf("DownloadMLAsset(%s): failed to copy asset data to file: %w", mlAssetID, err)
+		return kind, fmt.Errorf("WriteMLAsset(%s): %w", mlAssetID, err)
 	}
+	return kind, nil
+}
 
-	ext := ".mp3"
-	if isPhoto {
-		// For photos only: re-open the file to detect content type
-		_, err = tmpFile.Seek(0, 0)
+// downloadMLAssetTo implements DownloadMLAssetTo, naming the file with
+// the MLTempPattern and an extension for the kind of media. Unless videos
+// is true, it doesn't download videos, and their filenames are empty.
+func downloadMLAssetTo(ctx context.Context, mlAssetID, dir string, videos bool) (string, MediaKind, error) {
+	var filename string
+	probes := mlProbes(ctx, mlAssetID)
+	kind, err := retryMLAsset(ctx, mlAssetID, func() (MediaKind, error) {
+		f, err := os.CreateTemp(dir, MLTempPattern)
 		if err != nil {
-			return "", isPhoto, fmt.Errorf("DownloadMLAsset(%s): failed to seek to beginning of temp file: %w", mlAssetID, err)
+			return UnknownMedia, fmt.Errorf("CreateTemp: %w", err)
 		}
-
-		buf := make([]byte, 512) // 512 bytes is the required size for DetectContentType
-		n, err := tmpFile.Read(buf)
-		if err != nil && err != io.EOF {
-			return "", isPhoto, fmt.Errorf("DownloadMLAsset(%s): failed to read from temp file for content type detection: %w", mlAssetID, err)
+		kind, err := fetchMLAsset(ctx, probes, f, true, videos)
+		if err == nil && kind == Video && !videos {
+			f.Close()
+			os.Remove(f.Name())
+			return kind, nil
+		}
+		var ext string
+		if err == nil {
+			ext, err = mlFileExt(f, kind)
+		}
+		if cerr := f.Close(); err == nil { // Close the file before renaming it.
+			err = cerr
 		}
-		buf = buf[:n]
+		if err == nil {
+			filename = f.Name() + ext
+			if err = os.Rename(f.Name(), filename); err != nil {
+				err = fmt.Errorf("failed to rename file: %w", err)
+			}
+		}
+		if err != nil {
+			os.Remove(f.Name())
+		}
+		return kind, err
+	})
+	return filename, kind, err
+}
 
-		mimeType := http.DetectContentType(buf)
-		extensions, err := mime.ExtensionsByType(mimeType)
-		if err != nil || len(extensions) == 0 {
-			return "", isPhoto, fmt.Errorf("DownloadMLAsset(%s): failed to find file extension for mime type %s: %w", mlAssetID, mimeType, err)
+// retryMLAsset calls attempt until it succeeds, fails with an error that
+// isn't retryable, or has been called MLMaxAttempts times, waiting between
+// attempts as described at MLRetryDelay. It stops waiting if ctx is done.
+func retryMLAsset(ctx context.Context, mlAssetID string, attempt func() (MediaKind, error)) (MediaKind, error) {
+	for n := 1; ; n++ {
+		kind, err := attempt()
+		if err == nil || n >= MLMaxAttempts || !isRetryable(err) || ctx.Err() != nil {
+			return kind, err
+		}
+		delay := mlRetryDelay(n)
+		log.Printf("ML asset %s: %v; retrying in %v", mlAssetID, err, delay.Round(time.Millisecond))
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return UnknownMedia, ctx.Err()
 		}
-		ext = extensions[0]
 	}
-	tmpFile.Close() // Close the file before renaming it.
+}
 
-	newPath := tmpFile.Name() + ext
-	err = os.Rename(tmpFile.Name(), newPath)
+// fetchMLAsset makes one attempt at downloading the asset into w,
+// trying the probes, from mlProbes, in order until one is found.
+// Errors after which another attempt may succeed are retryableErrors.
+// If restartable is false, a failure after writing to w isn't retryable,
+// since the next attempt can't start over. Unless videos is true, it only
+// checks that a video exists, with a HEAD request, and writes nothing.
+func fetchMLAsset(ctx context.Context, probes []mlProbe, w io.Writer, restartable, videos bool) (MediaKind, error) {
+	client := mlClient()
+	var resp *http.Response
+	var probe mlProbe
+	for _, probe = range probes {
+		method := "GET"
+		if probe.kind == Video && !videos {
+			method = "HEAD"
+		}
+		req, err := http.NewRequestWithContext(ctx, method, probe.url, nil)
+		if err != nil {
+			return UnknownMedia, err
+		}
+		resp, err = client.Do(req)
+		if err != nil {
+			if !os.IsTimeout(err) {
+				// MLDownloadTimeout is already generous,
+				// so only retry other network errors.
+				err = retryableError{err}
+			}
+			return UnknownMedia, fmt.Errorf("%s: %w", probe.url, err)
+		}
+		defer resp.Body.Close()
+		// If it's not found, try the next kind of media.
+		if resp.StatusCode != http.StatusNotFound {
+			break
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("%s: %s", probe.url, resp.Status)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			err = retryableError{err}
+		}
+		return UnknownMedia, err
+	}
+	if probe.kind == Video && !videos {
+		return Video, nil
+	}
+	n, err := io.Copy(w, resp.Body)
 	if err != nil {
-		return "", isPhoto, fmt.Errorf("DownloadMLAsset(%s): failed to rename file: %w", mlAssetID, err)
+		if !os.IsTimeout(err) && (restartable || n == 0) {
+			err = retryableError{err}
+		}
+		return probe.kind, fmt.Errorf("failed to copy asset data: %w", err)
+	}
+	return probe.kind, nil
+}
+
+// mlFileExt returns the file extension for the downloaded asset in f.
+func mlFileExt(f *os.File, kind MediaKind) (string, error) {
+	switch kind {
+	case Video:
+		return ".mp4", nil
+	case Photo:
+		// For photos only: detect the content type to choose the file extension
+		mimeType, err := detectContentType(f)
+		if err != nil {
+			return "", err
+		}
+		extensions, err := mime.ExtensionsByType(mimeType)
+		if err != nil || len(extensions) == 0 {
+			return "", fmt.Errorf("failed to find file extension for mime type %s: %w", mimeType, err)
+		}
+		return extensions[0], nil
 	}
-	return newPath, isPhoto, nil
+	return ".mp3", nil
 }
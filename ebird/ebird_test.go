@@ -1,7 +1,15 @@
 package ebird
 
 import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -75,6 +83,111 @@ func TestRecord_Observed(t *testing.T) {
 	}
 }
 
+func TestRecord_PlausibleDate(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name string
+		date string
+		want bool
+	}{
+		{"recent", "2023-01-02", true},
+		{"slash format", "1/2/2023", true},
+		{"historical", "1850-06-01", true},
+		{"typo'd century", "0019-05-04", false},
+		{"typo'd slash year", "5/4/0019", false},
+		{"far future", "2202-05-04", false},
+		{"today", "2025-07-01", true},
+		{"tomorrow", "2025-07-02", true},
+		{"in two days", "2025-07-03", false},
+		{"unparseable", "invalid-date", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Record{Date: tc.date}
+			if got := r.PlausibleDateAt(now); got != tc.want {
+				t.Errorf("PlausibleDateAt(%q) = %v, want %v", tc.date, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRecord_SuspiciousCoordinates(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lat, lng string
+		want     bool
+	}{
+		{"normal", "37.123", "-122.123", false},
+		{"missing", "", "", false},
+		{"null island", "0", "0.0", true},
+		{"equator", "0", "-78.5", false},
+		{"latitude out of range", "91", "10", true},
+		{"longitude out of range", "45", "-181", true},
+		{"not a number", "north", "10", true},
+		{"missing longitude", "45", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Record{Latitude: tc.lat, Longitude: tc.lng}
+			if got := r.SuspiciousCoordinates(); got != tc.want {
+				t.Errorf("SuspiciousCoordinates(%q, %q) = %v, want %v", tc.lat, tc.lng, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRecord_Accuracy(t *testing.T) {
+	const base = PositionalAccuracy
+	testCases := []struct {
+		name     string
+		protocol string
+		distance string
+		want     int
+	}{
+		{"stationary", "Stationary", "", base},
+		{"stationary with distance", "Stationary", "2.5", base},
+		{"stationary long form", "eBird - Stationary Count", "0.8", base},
+		{"traveling", "Traveling", "2.5", base + 2500},
+		{"traveling without distance", "Traveling", "", base},
+		{"incidental", "Incidental", "", base},
+		{"bad distance", "Traveling", "far", base},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Record{Protocol: tc.protocol, DistanceTraveledKm: tc.distance}
+			if got := r.Accuracy(base); got != tc.want {
+				t.Errorf("Accuracy(%d) = %d, want %d", base, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRecord_SpansMidnight(t *testing.T) {
+	testCases := []struct {
+		name     string
+		date     string
+		time     string
+		duration string
+		want     bool
+	}{
+		{"daytime", "2023-01-02", "07:00 AM", "120", false},
+		{"ends at 11:59 PM", "2023-01-02", "10:59 PM", "60", false},
+		{"ends at midnight", "2023-01-02", "11:00 PM", "60", true},
+		{"owling past midnight", "2023-01-02", "11:30 PM", "90", true},
+		{"new year's eve", "12/31/2023", "11:45 PM", "30", true},
+		{"no time", "2023-01-02", "", "1440", false},
+		{"no duration", "2023-01-02", "11:30 PM", "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Record{Date: tc.date, Time: tc.time, DurationMin: tc.duration}
+			if got := r.SpansMidnight(); got != tc.want {
+				t.Errorf("SpansMidnight(%s %s + %s min) = %v, want %v", tc.date, tc.time, tc.duration, got, tc.want)
+			}
+		})
+	}
+}
+
 func TestRecords(t *testing.T) {
 	csvData := `Submission ID,Common Name,Scientific Name,Taxonomic Order,Count,State/Province,County,Location ID,Location,Latitude,Longitude,Date,Time,Protocol,Duration (Min),All Obs Reported,Distance Traveled (km),Area Covered (ha),Number of Observers,Breeding Code,Observation Details,Checklist Comments,ML Catalog Numbers
 S123,American Robin,Turdus migratorius,1,1,CA,Santa Clara,L123,Some Park,37.123,-122.123,2023-01-02,03:04 PM,Stationary,60,1,0,0,1,,,
@@ -92,13 +205,16 @@ S123,American Robin,Turdus migratorius,1,1,CA,Santa Clara,L123,Some Park,37.123,
 		t.Fatal(err)
 	}
 
-	records, err := Records(tmpfile.Name())
+	records, err := Records(context.Background(), tmpfile.Name())
 	if err != nil {
 		t.Fatalf("Records() error: %v", err)
 	}
 
 	var recs []Record
-	for rec := range records {
+	for rec, err := range records {
+		if err != nil {
+			t.Fatalf("Records() iteration error: %v", err)
+		}
 		recs = append(recs, rec)
 	}
 
@@ -142,6 +258,55 @@ S123,American Robin,Turdus migratorius,1,1,CA,Santa Clara,L123,Some Park,37.123,
 	}
 }
 
+func TestRecord_CountInt(t *testing.T) {
+	testCases := []struct {
+		count  string
+		want   int
+		wantOK bool
+	}{
+		{"1", 1, true},
+		{"250", 250, true},
+		{" 3 ", 3, true},
+		{"X", 0, false},
+		{"x", 0, false},
+		{"", 0, false},
+		{"-1", 0, false},
+	}
+	for _, tc := range testCases {
+		n, ok := Record{Count: tc.count}.CountInt()
+		if n != tc.want || ok != tc.wantOK {
+			t.Errorf("CountInt(%q) = %d, %v; want %d, %v", tc.count, n, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestCanonicalSubmissionID(t *testing.T) {
+	testCases := []struct {
+		id, want string
+	}{
+		{"S12345", "S12345"},
+		{" S12345\n", "S12345"},
+		{"s12345", "S12345"},
+		{"S12345.2", "S12345"},
+		{"S12345-1", "S12345"},
+		{"S12345v3", "S12345"},
+		{"", ""},
+		{"S", "S"},
+		{"Sx123", "Sx123"},
+		{"L123", "L123"},
+	}
+	for _, tc := range testCases {
+		if got := CanonicalSubmissionID(tc.id); got != tc.want {
+			t.Errorf("CanonicalSubmissionID(%q) = %q, want %q", tc.id, got, tc.want)
+		}
+	}
+	r1 := Record{SubmissionID: "S12345", ScientificName: "Turdus migratorius"}
+	r2 := Record{SubmissionID: "S12345.2", ScientificName: "Turdus migratorius"}
+	if r1.ObservationID() != r2.ObservationID() {
+		t.Errorf("ObservationID() of revised checklist = %v, want %v", r2.ObservationID(), r1.ObservationID())
+	}
+}
+
 func TestObservationID_Valid(t *testing.T) {
 	testCases := []struct {
 		name string
@@ -177,3 +342,212 @@ func TestObservationID_Valid(t *testing.T) {
 		})
 	}
 }
+
+func TestRecordsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	records, err := Records(ctx, "testdata/ragged.csv")
+	if err != nil {
+		t.Fatalf("Records() error: %v", err)
+	}
+	n := 0
+	for _, err := range records {
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("Records() iteration error = %v, want context.Canceled", err)
+			}
+			break
+		}
+		n++
+		cancel()
+	}
+	if n != 1 {
+		t.Errorf("Records() yielded %d records after canceling, want 1", n)
+	}
+	if _, err := Records(ctx, "testdata/ragged.csv"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Records(canceled) error = %v, want context.Canceled", err)
+	}
+}
+
+func TestRecordsRagged(t *testing.T) {
+	records, err := Records(context.Background(), "testdata/ragged.csv")
+	if err != nil {
+		t.Fatalf("Records() error: %v", err)
+	}
+	var recs []Record
+	for rec, err := range records {
+		if err != nil {
+			t.Fatalf("Records() iteration error: %v", err)
+		}
+		recs = append(recs, rec)
+	}
+	if len(recs) != 3 {
+		t.Fatalf("Expected 3 records, but got %d", len(recs))
+	}
+	testCases := []struct {
+		rec        Record
+		line       int
+		commonName string
+		count      string
+		protocol   string
+		mlNumbers  string
+	}{
+		// Trailing empty fields are trimmed, and the duplicate
+		// "Common Name" column doesn't override the first one.
+		{recs[0], 2, "American Robin", "1", "Stationary", ""},
+		// Short rows leave missing fields empty.
+		{recs[1], 3, "Northern Cardinal", "2", "", ""},
+		// Extra non-empty fields are ignored.
+		{recs[2], 4, "Mourning Dove", "X", "Stationary", ""},
+	}
+	for _, tc := range testCases {
+		if tc.rec.Line != tc.line ||
+			tc.rec.CommonName != tc.commonName ||
+			tc.rec.Count != tc.count ||
+			tc.rec.Protocol != tc.protocol ||
+			tc.rec.MLCatalogNumbers != tc.mlNumbers {
+			t.Errorf("line %d: got %+v", tc.line, tc.rec)
+		}
+	}
+}
+
+func TestRecordsStreaming(t *testing.T) {
+	records, err := Records(context.Background(), "testdata/ragged.csv")
+	if err != nil {
+		t.Fatalf("Records() error: %v", err)
+	}
+	// Each iteration rereads the file from the start.
+	var readErr error
+	first := slices.Collect(UntilError(records, &readErr))
+	second := slices.Collect(UntilError(records, &readErr))
+	if readErr != nil {
+		t.Fatalf("Records() iteration error: %v", readErr)
+	}
+	if len(first) != 3 || !slices.Equal(first, second) {
+		t.Errorf("Iterating twice got %d and %d records, want the same 3", len(first), len(second))
+	}
+	// Stopping early is fine, and the next iteration starts over.
+	for rec := range UntilError(records, &readErr) {
+		if rec.Line != 2 {
+			t.Errorf("First record is on line %d, want 2", rec.Line)
+		}
+		break
+	}
+	if n := len(slices.Collect(UntilError(records, &readErr))); n != 3 {
+		t.Errorf("After stopping early, got %d records, want 3", n)
+	}
+}
+
+func TestRecordsErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Records(context.Background(), filepath.Join(dir, "missing.csv")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Records(missing file) error = %v, want fs.ErrNotExist", err)
+	}
+	empty := filepath.Join(dir, "empty.csv")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Records(context.Background(), empty); err == nil {
+		t.Errorf("Records(empty file) succeeded, want an error")
+	}
+
+	// A malformed row stops the iteration with an error.
+	bad := filepath.Join(dir, "bad.csv")
+	data := "Submission ID,Common Name\nS1,American Robin\nS2,\"Northern \"Cardinal\nS3,Mourning Dove\n"
+	if err := os.WriteFile(bad, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := Records(context.Background(), bad)
+	if err != nil {
+		t.Fatalf("Records() error: %v", err)
+	}
+	var readErr error
+	recs := slices.Collect(UntilError(records, &readErr))
+	var parseErr *csv.ParseError
+	if len(recs) != 1 || !errors.As(readErr, &parseErr) {
+		t.Errorf("Got %d records and error %v, want 1 record and a *csv.ParseError", len(recs), readErr)
+	}
+
+	// So does a file that disappears between iterations.
+	os.Remove(bad)
+	readErr = nil
+	if recs := slices.Collect(UntilError(records, &readErr)); len(recs) != 0 || !errors.Is(readErr, fs.ErrNotExist) {
+		t.Errorf("Got %d records and error %v after removing the file, want fs.ErrNotExist", len(recs), readErr)
+	}
+}
+
+func TestRecordsFromZip(t *testing.T) {
+	csvData, err := os.ReadFile("testdata/ragged.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeZip := func(name string, files map[string][]byte) string {
+		filename := filepath.Join(t.TempDir(), name)
+		f, err := os.Create(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		z := zip.NewWriter(f)
+		for name, data := range files {
+			w, err := z.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(data)
+		}
+		if err := z.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	archive := writeZip("ebird_1234.zip", map[string][]byte{
+		"README.txt":      []byte("Thanks for using eBird"),
+		"MyEBirdData.csv": csvData,
+	})
+	records, err := Records(context.Background(), archive) // detects the .zip extension
+	if err != nil {
+		t.Fatalf("Records(%s) error: %v", archive, err)
+	}
+	var readErr error
+	recs := slices.Collect(UntilError(records, &readErr))
+	if readErr != nil {
+		t.Fatalf("Records() iteration error: %v", readErr)
+	}
+	if len(recs) != 3 || recs[0].SubmissionID != "S100" {
+		t.Errorf("Got %d records from the archive, want 3 starting with S100", len(recs))
+	}
+
+	archive = writeZip("empty.zip", map[string][]byte{"README.txt": nil})
+	if _, err := RecordsFromZip(context.Background(), archive); err == nil {
+		t.Errorf("RecordsFromZip(archive without %s) succeeded, want an error", ExportFilename)
+	}
+}
+
+func TestRecordsFromReader(t *testing.T) {
+	data := "Submission ID,Scientific Name,Count\nS1,Turdus migratorius,2\nS1,Cardinalis cardinalis,X\n"
+	records, err := RecordsFromReader(context.Background(), strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("RecordsFromReader() error: %v", err)
+	}
+	var readErr error
+	recs := slices.Collect(UntilError(records, &readErr))
+	if readErr != nil {
+		t.Fatalf("RecordsFromReader() iteration error: %v", readErr)
+	}
+	if len(recs) != 2 || recs[1].ScientificName != "Cardinalis cardinalis" || recs[1].Line != 3 {
+		t.Errorf("RecordsFromReader() = %+v, want 2 records ending with Cardinalis cardinalis on line 3", recs)
+	}
+
+	// The reader has been consumed, so a second iteration fails.
+	if recs := slices.Collect(UntilError(records, &readErr)); len(recs) != 0 || readErr == nil {
+		t.Errorf("Second iteration got %d records and error %v, want an error", len(recs), readErr)
+	}
+
+	if _, err := RecordsFromReader(context.Background(), strings.NewReader("")); err == nil {
+		t.Errorf("RecordsFromReader(empty) succeeded, want an error")
+	}
+}
@@ -0,0 +1,60 @@
+package ebird
+
+import "strings"
+
+// ExoticCategory is eBird's provenance of a species that isn't native
+// where it was observed. Newer exports record it in the Exotic Code column.
+// See https://support.ebird.org/en/support/solutions/articles/48000950859.
+type ExoticCategory string
+
+const (
+	Native      ExoticCategory = ""  // native, or not categorized
+	Naturalized ExoticCategory = "N" // an established, self-sustaining population
+	Provisional ExoticCategory = "P" // breeding, but not (yet) established
+	Escapee     ExoticCategory = "X" // escaped or released from captivity
+)
+
+// ParseExoticCategory parses the Exotic Code column of MyEBirdData.csv,
+// which is a code like "X" or a name like "Escapee", ignoring case.
+// ok is false if s isn't empty and isn't a known category.
+func ParseExoticCategory(s string) (c ExoticCategory, ok bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return Native, true
+	case "n", "naturalized":
+		return Naturalized, true
+	case "p", "provisional":
+		return Provisional, true
+	case "x", "escapee":
+		return Escapee, true
+	}
+	return Native, false
+}
+
+func (c ExoticCategory) String() string {
+	switch c {
+	case Native:
+		return "Native"
+	case Naturalized:
+		return "Naturalized"
+	case Provisional:
+		return "Provisional"
+	case Escapee:
+		return "Escapee"
+	}
+	return string(c)
+}
+
+// Exotic returns the record's exotic category, or Native if it has none
+// or isn't a known category.
+func (r Record) Exotic() ExoticCategory {
+	c, _ := ParseExoticCategory(r.ExoticCode)
+	return c
+}
+
+// Captive reports whether the record's bird escaped or was released from
+// captivity, which iNaturalist considers captive rather than wild.
+// Naturalized and provisional populations are wild.
+func (r Record) Captive() bool {
+	return r.Exotic() == Escapee
+}
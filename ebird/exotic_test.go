@@ -0,0 +1,59 @@
+package ebird
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseExoticCategory(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		want ExoticCategory
+		ok   bool
+	}{
+		{"", Native, true},
+		{"N", Naturalized, true},
+		{"p", Provisional, true},
+		{" X ", Escapee, true},
+		{"Escapee", Escapee, true},
+		{"naturalized", Naturalized, true},
+		{"Q", Native, false},
+	} {
+		got, ok := ParseExoticCategory(tc.s)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("ParseExoticCategory(%q) = %q, %v; want %q, %v", tc.s, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestExoticCodeColumn(t *testing.T) {
+	defer func(strict bool) { StrictHeader = strict }(StrictHeader)
+	StrictHeader = true
+	csv := strings.Join(exportHeader, ",") + ",Exotic Code\n" +
+		"S1,Mute Swan,Cygnus olor,1,2,US-VA,,,,,,2024-01-01,,,,,,,,,,,,N\n" +
+		"S1,Indian Peafowl,Pavo cristatus,2,1,US-VA,,,,,,2024-01-01,,,,,,,,,,,,X\n" +
+		"S1,Mallard,Anas platyrhynchos,3,5,US-VA,,,,,,2024-01-01,,,,,,,,,,,,\n"
+	seq, err := RecordsFromReader(context.Background(), strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("RecordsFromReader() error: %v", err)
+	}
+	var got []string
+	for rec, err := range seq {
+		if err != nil {
+			t.Fatalf("RecordsFromReader() iteration error: %v", err)
+		}
+		got = append(got, rec.Exotic().String())
+		if rec.Captive() != (rec.Exotic() == Escapee) {
+			t.Errorf("%s: Captive() = %v with exotic category %v", rec.CommonName, rec.Captive(), rec.Exotic())
+		}
+	}
+	if want := "Naturalized Escapee Native"; strings.Join(got, " ") != want {
+		t.Errorf("exotic categories = %q, want %q", got, want)
+	}
+
+	// Older exports without the column are fine, even with StrictHeader.
+	if err := CheckHeader(exportHeader); err != nil {
+		t.Errorf("CheckHeader(exportHeader) = %v, want nil", err)
+	}
+}
@@ -0,0 +1,29 @@
+package ebird
+
+import "iter"
+
+// Filter returns the records for which keep returns true.
+// It's lazy: records are read and tested only as the result is iterated,
+// and iteration stops reading records as soon as the caller stops.
+func Filter(records iter.Seq[Record], keep func(Record) bool) iter.Seq[Record] {
+	return func(yield func(Record) bool) {
+		for rec := range records {
+			if keep(rec) && !yield(rec) {
+				return
+			}
+		}
+	}
+}
+
+// RecordsForSubmissions returns the records from the checklists with the
+// provided submission IDs, in their original order. Like [Filter], it's lazy.
+// Submission IDs may be in any form accepted by [CanonicalSubmissionID].
+func RecordsForSubmissions(records iter.Seq[Record], ids ...string) iter.Seq[Record] {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[CanonicalSubmissionID(id)] = true
+	}
+	return Filter(records, func(rec Record) bool {
+		return set[CanonicalSubmissionID(rec.SubmissionID)]
+	})
+}
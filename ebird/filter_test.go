@@ -0,0 +1,59 @@
+package ebird
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	records := []Record{
+		{Line: 2, Count: "1"},
+		{Line: 3, Count: "X"},
+		{Line: 4, Count: "5"},
+		{Line: 5, Count: "X"},
+	}
+	counted := func(r Record) bool { return r.Count != "X" }
+	var lines []int
+	for rec := range Filter(slices.Values(records), counted) {
+		lines = append(lines, rec.Line)
+	}
+	if !slices.Equal(lines, []int{2, 4}) {
+		t.Errorf("Filter() lines = %v, want [2 4]", lines)
+	}
+
+	// Stopping early stops reading records.
+	read := 0
+	reading := func(yield func(Record) bool) {
+		for _, r := range records {
+			read++
+			if !yield(r) {
+				return
+			}
+		}
+	}
+	for range Filter(reading, counted) {
+		break
+	}
+	if read != 1 {
+		t.Errorf("Filter() read %d records after the caller stopped, want 1", read)
+	}
+}
+
+func TestRecordsForSubmissions(t *testing.T) {
+	records := []Record{
+		{Line: 2, SubmissionID: "S1"},
+		{Line: 3, SubmissionID: "S2"},
+		{Line: 4, SubmissionID: "S3"},
+		{Line: 5, SubmissionID: "S1"},
+	}
+	var lines []int
+	for rec := range RecordsForSubmissions(slices.Values(records), "S3", " s1.2") {
+		lines = append(lines, rec.Line)
+	}
+	if !slices.Equal(lines, []int{2, 4, 5}) {
+		t.Errorf("RecordsForSubmissions() lines = %v, want [2 4 5]", lines)
+	}
+	if n := len(slices.Collect(RecordsForSubmissions(slices.Values(records)))); n != 0 {
+		t.Errorf("RecordsForSubmissions() with no IDs returned %d records, want 0", n)
+	}
+}
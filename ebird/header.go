@@ -0,0 +1,126 @@
+package ebird
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// StrictHeader makes Records and the functions like it fail when the
+// header of an export has columns that birdsync doesn't know or lacks
+// columns it expects, as happens when eBird changes the export format.
+// Otherwise, unknown columns are only logged, and missing columns are
+// read as empty.
+var StrictHeader = false
+
+// HeaderError describes the differences between an export's header and
+// the columns of MyEBirdData.csv. See CheckHeader.
+type HeaderError struct {
+	Unknown []string // columns birdsync doesn't read
+	Missing []string // columns birdsync expects that aren't there
+
+	// Suggestions maps unknown columns to the expected columns they
+	// may have been renamed from.
+	Suggestions map[string]string
+}
+
+func (e *HeaderError) Error() string {
+	var parts []string
+	if len(e.Unknown) > 0 {
+		var cols []string
+		for _, u := range e.Unknown {
+			col := fmt.Sprintf("%q", u)
+			if s, ok := e.Suggestions[u]; ok {
+				col += fmt.Sprintf(" (renamed from %q?)", s)
+			}
+			cols = append(cols, col)
+		}
+		parts = append(parts, "unknown columns "+strings.Join(cols, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("missing columns %q", e.Missing))
+	}
+	return "eBird export format changed: " + strings.Join(parts, "; ")
+}
+
+// CheckHeader compares header with the columns of MyEBirdData.csv and
+// returns a *HeaderError describing any unknown or missing columns,
+// or nil if there are none. Column order doesn't matter, and the columns
+// of newer exports, such as Exotic Code, aren't required.
+func CheckHeader(header []string) error {
+	e := &HeaderError{Suggestions: map[string]string{}}
+	for _, h := range header {
+		known := slices.Contains(exportHeader, h) || slices.Contains(optionalColumns, h)
+		if !known && !slices.Contains(e.Unknown, h) {
+			e.Unknown = append(e.Unknown, h)
+		}
+	}
+	for _, h := range exportHeader {
+		if !slices.Contains(header, h) {
+			e.Missing = append(e.Missing, h)
+		}
+	}
+	if len(e.Unknown) == 0 && len(e.Missing) == 0 {
+		return nil
+	}
+	for _, u := range e.Unknown {
+		if s, ok := closestColumn(u, e.Missing); ok {
+			e.Suggestions[u] = s
+		}
+	}
+	return e
+}
+
+// checkHeader applies StrictHeader to the header of the export name.
+func checkHeader(name string, header []string) error {
+	err := CheckHeader(header)
+	if err == nil {
+		return nil
+	}
+	if StrictHeader {
+		return fmt.Errorf("Records(%s): %w", name, err)
+	}
+	if e := err.(*HeaderError); len(e.Unknown) > 0 {
+		e.Missing = nil // usually just an older or trimmed export
+		log.Printf("%s: ignoring columns: %v", name, e)
+	}
+	return nil
+}
+
+// closestColumn returns the column in candidates most like col,
+// ignoring case, if any is close enough to be a likely rename.
+func closestColumn(col string, candidates []string) (string, bool) {
+	best, bestDist := "", -1
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(col), strings.ToLower(c))
+		if bestDist < 0 || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if bestDist < 0 || bestDist > max(2, len(best)/3) {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,79 @@
+package ebird
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckHeader(t *testing.T) {
+	if err := CheckHeader(exportHeader); err != nil {
+		t.Errorf("CheckHeader(exportHeader) = %v, want nil", err)
+	}
+
+	header := slices.Clone(exportHeader)
+	header[slices.Index(header, "Duration (Min)")] = "Duration (min)" // renamed
+	header[slices.Index(header, "Breeding Code")] = "Breeding Codes"  // renamed
+	header = slices.DeleteFunc(header, func(h string) bool { return h == "Area Covered (ha)" })
+	header = append(header, "Age/Sex")
+	err := CheckHeader(header)
+	var he *HeaderError
+	if !errors.As(err, &he) {
+		t.Fatalf("CheckHeader() = %v, want a *HeaderError", err)
+	}
+	if want := []string{"Duration (min)", "Breeding Codes", "Age/Sex"}; !slices.Equal(he.Unknown, want) {
+		t.Errorf("Unknown = %q, want %q", he.Unknown, want)
+	}
+	if want := []string{"Duration (Min)", "Area Covered (ha)", "Breeding Code"}; !slices.Equal(he.Missing, want) {
+		t.Errorf("Missing = %q, want %q", he.Missing, want)
+	}
+	wantSuggestions := map[string]string{
+		"Duration (min)": "Duration (Min)",
+		"Breeding Codes": "Breeding Code",
+	}
+	if len(he.Suggestions) != len(wantSuggestions) {
+		t.Errorf("Suggestions = %q, want %q", he.Suggestions, wantSuggestions)
+	}
+	for k, v := range wantSuggestions {
+		if he.Suggestions[k] != v {
+			t.Errorf("Suggestions[%q] = %q, want %q", k, he.Suggestions[k], v)
+		}
+	}
+	if msg := err.Error(); !strings.Contains(msg, `"Duration (min)" (renamed from "Duration (Min)"?)`) {
+		t.Errorf("Error() = %q, want a suggestion", msg)
+	}
+}
+
+func TestStrictHeader(t *testing.T) {
+	defer func(strict bool) { StrictHeader = strict }(StrictHeader)
+	data := "Submission ID,Common Name,Scientific Nam,Count\nS1,American Robin,Turdus migratorius,1\n"
+
+	StrictHeader = false
+	if _, err := RecordsFromReader(context.Background(), strings.NewReader(data)); err != nil {
+		t.Errorf("RecordsFromReader() error = %v, want success without StrictHeader", err)
+	}
+	StrictHeader = true
+	_, err := RecordsFromReader(context.Background(), strings.NewReader(data))
+	var he *HeaderError
+	if !errors.As(err, &he) || he.Suggestions["Scientific Nam"] != "Scientific Name" {
+		t.Errorf("RecordsFromReader() with StrictHeader error = %v, want unknown Scientific Nam", err)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"count", "count", 0},
+	} {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
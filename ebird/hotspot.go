@@ -0,0 +1,95 @@
+package ebird
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// PersonalLocationAccuracy is the positional accuracy in meters of
+// personal locations: the points users drop on a map for a checklist,
+// which are usually where they birded. Hotspots are shared by everyone
+// who birds a park or refuge, so they're much less precise; see
+// PositionalAccuracy.
+var PersonalLocationAccuracy = 100
+
+// Hotspot is an eBird hotspot.
+type Hotspot struct {
+	LocationID          string
+	Name                string
+	Latitude, Longitude float64
+	Subnational1Code    string // like "US-VA"
+	Subnational2Code    string // like "US-VA-059"
+}
+
+// Hotspot returns the hotspot with the provided location ID, like "L123".
+// ok is false if the location isn't a hotspot, as for personal locations.
+// Results are cached for the life of the client.
+func (c *APIClient) Hotspot(ctx context.Context, locationID string) (h Hotspot, ok bool, err error) {
+	c.mu.Lock()
+	cached, found := c.hotspots[locationID]
+	c.mu.Unlock()
+	if found {
+		if cached == nil {
+			return Hotspot{}, false, nil
+		}
+		return *cached, true, nil
+	}
+
+	var info struct {
+		LocID            string  `json:"locId"`
+		Name             string  `json:"name"`
+		Latitude         float64 `json:"latitude"`
+		Longitude        float64 `json:"longitude"`
+		Subnational1Code string  `json:"subnational1Code"`
+		Subnational2Code string  `json:"subnational2Code"`
+	}
+	var hotspot *Hotspot
+	err = c.get(ctx, "/ref/hotspot/info/"+locationID, nil, &info)
+	var se statusError
+	switch {
+	case errors.As(err, &se) && (se.code == http.StatusNotFound || se.code == http.StatusGone):
+		// Not a hotspot; cache that too.
+	case err != nil:
+		return Hotspot{}, false, fmt.Errorf("Hotspot(%s): %w", locationID, err)
+	default:
+		hotspot = &Hotspot{
+			LocationID:       info.LocID,
+			Name:             info.Name,
+			Latitude:         info.Latitude,
+			Longitude:        info.Longitude,
+			Subnational1Code: info.Subnational1Code,
+			Subnational2Code: info.Subnational2Code,
+		}
+	}
+	c.mu.Lock()
+	if c.hotspots == nil {
+		c.hotspots = map[string]*Hotspot{}
+	}
+	c.hotspots[locationID] = hotspot
+	c.mu.Unlock()
+	if hotspot == nil {
+		return Hotspot{}, false, nil
+	}
+	return *hotspot, true, nil
+}
+
+// LocationAccuracy returns the positional accuracy in meters of the
+// location with the provided ID, for use as the base of Record.Accuracy:
+// hotspotAccuracy (typically PositionalAccuracy) for hotspots, and
+// PersonalLocationAccuracy for other locations. If the location can't
+// be looked up, it returns hotspotAccuracy and the error.
+func (c *APIClient) LocationAccuracy(ctx context.Context, locationID string, hotspotAccuracy int) (int, error) {
+	if locationID == "" {
+		return hotspotAccuracy, nil
+	}
+	_, isHotspot, err := c.Hotspot(ctx, locationID)
+	if err != nil {
+		return hotspotAccuracy, err
+	}
+	if isHotspot {
+		return hotspotAccuracy, nil
+	}
+	return PersonalLocationAccuracy, nil
+}
@@ -0,0 +1,58 @@
+package ebird
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHotspot(t *testing.T) {
+	taxonomyRequests := 0
+	server := newTestAPIServer(t, &taxonomyRequests)
+	client := NewAPIClient(server.URL, "key", "test")
+	ctx := context.Background()
+
+	h, ok, err := client.Hotspot(ctx, "L1")
+	if err != nil || !ok {
+		t.Fatalf("Hotspot(L1) = %v, %v; want a hotspot", ok, err)
+	}
+	want := Hotspot{"L1", "Some Park", 37.5, -122.25, "US-CA", "US-CA-085"}
+	if h != want {
+		t.Errorf("Hotspot(L1) = %+v, want %+v", h, want)
+	}
+	if _, ok, err := client.Hotspot(ctx, "L2"); err != nil || ok {
+		t.Errorf("Hotspot(L2) = %v, %v; want not a hotspot", ok, err)
+	}
+	if _, _, err := client.Hotspot(ctx, "L500"); err == nil {
+		t.Error("Hotspot(L500) succeeded, want an error")
+	}
+
+	// Lookups are cached, including locations that aren't hotspots.
+	server.Close()
+	if _, ok, err := client.Hotspot(ctx, "L1"); err != nil || !ok {
+		t.Errorf("cached Hotspot(L1) = %v, %v; want a hotspot", ok, err)
+	}
+	if _, ok, err := client.Hotspot(ctx, "L2"); err != nil || ok {
+		t.Errorf("cached Hotspot(L2) = %v, %v; want not a hotspot", ok, err)
+	}
+}
+
+func TestLocationAccuracy(t *testing.T) {
+	taxonomyRequests := 0
+	server := newTestAPIServer(t, &taxonomyRequests)
+	client := NewAPIClient(server.URL, "key", "test")
+	for _, tc := range []struct {
+		locationID string
+		want       int
+		wantErr    bool
+	}{
+		{"L1", 1000, false},                     // hotspot
+		{"L2", PersonalLocationAccuracy, false}, // personal location
+		{"", 1000, false},
+		{"L500", 1000, true},
+	} {
+		got, err := client.LocationAccuracy(context.Background(), tc.locationID, 1000)
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("LocationAccuracy(%q) = %d, %v; want %d, error %v", tc.locationID, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
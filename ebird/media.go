@@ -0,0 +1,271 @@
+package ebird
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MediaKind is the kind of media in a Macaulay Library asset.
+type MediaKind int
+
+const (
+	UnknownMedia MediaKind = iota
+	Photo
+	Sound
+	Video
+)
+
+func (k MediaKind) String() string {
+	switch k {
+	case Photo:
+		return "photo"
+	case Sound:
+		return "sound"
+	case Video:
+		return "video"
+	}
+	return "unknown"
+}
+
+// MLBaseURL is the base URL of the Macaulay Library media CDN.
+var MLBaseURL = "https://cdn.download.ams.birds.cornell.edu/api/v2/asset"
+
+// MLDownloadTimeout limits how long each request to the Macaulay Library
+// may take. Large photos and long recordings can take a while to download,
+// so this is more generous than the iNaturalist API client's timeout.
+var MLDownloadTimeout = 10 * time.Minute
+
+// MLTempDir is the directory in which DownloadMLAsset saves downloads.
+// If it's empty, downloads go in the default directory for temporary
+// files, os.TempDir. Set it when that directory is small or read-only,
+// as it often is in containers.
+var MLTempDir = ""
+
+// MLTempPattern is the os.CreateTemp pattern for the names of
+// downloaded files. DownloadMLAsset adds a file extension to the name.
+var MLTempPattern = "birdsync"
+
+// CheckMLTempDir reports an error if DownloadMLAsset can't create files
+// in MLTempDir. Call it before downloading anything to fail fast.
+func CheckMLTempDir() error {
+	f, err := os.CreateTemp(MLTempDir, MLTempPattern)
+	if err != nil {
+		dir := MLTempDir
+		if dir == "" {
+			dir = os.TempDir()
+		}
+		return fmt.Errorf("can't create files for Macaulay Library downloads in %s: %w", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// MLMaxAttempts is the most times DownloadMLAsset tries to download
+// an asset when the Macaulay Library fails in ways that may be temporary,
+// such as server errors and broken connections. It's at least one.
+var MLMaxAttempts = 4
+
+// MLRetryDelay is about how long DownloadMLAsset waits before its
+// first retry. The wait doubles for each later retry, and each wait is
+// randomized between half and all of that, so that concurrent downloads
+// don't retry in lockstep.
+var MLRetryDelay = 2 * time.Second
+
+// mlRetryDelay returns how long to wait after the provided attempt fails.
+func mlRetryDelay(attempt int) time.Duration {
+	d := MLRetryDelay << min(attempt-1, 16)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryableError is an error after which a request may succeed if it's
+// made again.
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+// isRetryable reports whether err is or wraps a retryableError.
+func isRetryable(err error) bool {
+	var r retryableError
+	return errors.As(err, &r)
+}
+
+// mlClient returns the HTTP client for Macaulay Library requests.
+func mlClient() *http.Client {
+	return &http.Client{Timeout: MLDownloadTimeout}
+}
+
+func mlPhotoURL(mlAssetID string) string {
+	return fmt.Sprintf("%s/%s/2400", MLBaseURL, mlAssetID)
+}
+
+func mlSoundURL(mlAssetID string) string {
+	return fmt.Sprintf("%s/%s/mp3", MLBaseURL, mlAssetID)
+}
+
+func mlVideoURL(mlAssetID string) string {
+	return fmt.Sprintf("%s/%s/mp4/1280", MLBaseURL, mlAssetID)
+}
+
+// mlProbe is a URL at which an ML asset may be found, and the kind
+// of media it is if it's there.
+type mlProbe struct {
+	url  string
+	kind MediaKind
+}
+
+// mlProbes returns the URLs to try, in order, to find an ML asset.
+// If the asset's metadata says what kind of media it is, that's the
+// only URL. Otherwise, such as when the search API is down, it's every
+// kind, starting with photos, which are by far the most common, and
+// ending with videos, the least.
+func mlProbes(ctx context.Context, mlAssetID string) []mlProbe {
+	if info, err := GetMLAssetInfo(ctx, mlAssetID); err == nil {
+		switch info.Kind {
+		case Photo:
+			return []mlProbe{{mlPhotoURL(mlAssetID), Photo}}
+		case Sound:
+			return []mlProbe{{mlSoundURL(mlAssetID), Sound}}
+		case Video:
+			return []mlProbe{{mlVideoURL(mlAssetID), Video}}
+		}
+	}
+	return []mlProbe{
+		{mlPhotoURL(mlAssetID), Photo},
+		{mlSoundURL(mlAssetID), Sound},
+		{mlVideoURL(mlAssetID), Video},
+	}
+}
+
+// MLAssetID is a Macaulay Library asset ID: a catalog number
+// without its "ML" prefix, like "123456789".
+type MLAssetID string
+
+// MLAssetIDs returns the Macaulay Library asset IDs in the record's
+// ML Catalog Numbers, in order and without duplicates. eBird separates
+// them with spaces; an "ML" prefix, as in "ML123456789", is removed.
+// Entries that aren't numbers are left out and described in the error,
+// which includes the record's line for diagnostics.
+func (r Record) MLAssetIDs() ([]MLAssetID, error) {
+	ids, bad := parseMLCatalogNumbers(r.MLCatalogNumbers)
+	if len(bad) > 0 {
+		return ids, fmt.Errorf("line %d: malformed ML Catalog Numbers %q", r.Line, bad)
+	}
+	return ids, nil
+}
+
+// parseMLCatalogNumbers parses ML Catalog Numbers as described in
+// Record.MLAssetIDs, returning the valid IDs and the malformed entries.
+func parseMLCatalogNumbers(mlCatalogNumbers string) (ids []MLAssetID, bad []string) {
+	for _, f := range strings.Fields(mlCatalogNumbers) {
+		id := MLAssetID(strings.TrimPrefix(strings.ToUpper(f), "ML"))
+		if id == "" || strings.Trim(string(id), "0123456789") != "" {
+			bad = append(bad, f)
+			continue
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, bad
+}
+
+// mlAssetIDs returns the valid asset IDs in a record's ML Catalog Numbers.
+func mlAssetIDs(mlCatalogNumbers string) []string {
+	ids, _ := parseMLCatalogNumbers(mlCatalogNumbers)
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = string(id)
+	}
+	return strs
+}
+
+// MLAssetKind reports whether the ML asset is a photo, sound, or video
+// without downloading it, using the same URLs as DownloadMLAsset.
+func MLAssetKind(ctx context.Context, mlAssetID string) (MediaKind, error) {
+	for _, probe := range mlProbes(ctx, mlAssetID) {
+		req, err := http.NewRequestWithContext(ctx, "HEAD", probe.url, nil)
+		if err != nil {
+			return UnknownMedia, fmt.Errorf("MLAssetKind(%s): %w", mlAssetID, err)
+		}
+		resp, err := mlClient().Do(req)
+		if err != nil {
+			return UnknownMedia, fmt.Errorf("MLAssetKind(%s): %s: %w", mlAssetID, probe.url, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return probe.kind, nil
+		}
+		if resp.StatusCode != http.StatusNotFound {
+			return UnknownMedia, fmt.Errorf("MLAssetKind(%s): %s: %s", mlAssetID, probe.url, resp.Status)
+		}
+	}
+	return UnknownMedia, nil
+}
+
+// detectContentType returns the MIME type of the file's contents.
+// It reads from the start of f and leaves the offset unspecified.
+func detectContentType(f *os.File) (string, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to seek to beginning of file: %w", err)
+	}
+	buf := make([]byte, 512) // 512 bytes is the required size for DetectContentType
+	n, err := f.Read(buf)
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read file for content type detection: %w", err)
+	}
+	buf = buf[:n]
+	// DetectContentType only recognizes MP3 files that start with an ID3 tag,
+	// so also check for a bare MPEG audio frame header.
+	if len(buf) >= 2 && buf[0] == 0xFF && buf[1]&0xE0 == 0xE0 {
+		return "audio/mpeg", nil
+	}
+	return http.DetectContentType(buf), nil
+}
+
+// ValidateMediaFile checks that the file at path exists, is non-empty,
+// and contains a recognized photo, sound, or video, and it returns the kind of media.
+// Use it to catch corrupt or truncated downloads before uploading them.
+func ValidateMediaFile(path string) (MediaKind, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return UnknownMedia, fmt.Errorf("ValidateMediaFile: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return UnknownMedia, fmt.Errorf("ValidateMediaFile: %w", err)
+	}
+	if info.IsDir() {
+		return UnknownMedia, fmt.Errorf("ValidateMediaFile(%s): is a directory", path)
+	}
+	if info.Size() == 0 {
+		return UnknownMedia, fmt.Errorf("ValidateMediaFile(%s): file is empty", path)
+	}
+	mimeType, err := detectContentType(f)
+	if err != nil {
+		return UnknownMedia, fmt.Errorf("ValidateMediaFile(%s): %w", path, err)
+	}
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return Photo, nil
+	case strings.HasPrefix(mimeType, "audio/"):
+		return Sound, nil
+	case strings.HasPrefix(mimeType, "video/"):
+		return Video, nil
+	}
+	return UnknownMedia, fmt.Errorf("ValidateMediaFile(%s): unrecognized content type %s", path, mimeType)
+}
@@ -0,0 +1,356 @@
+package ebird
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateMediaFile(t *testing.T) {
+	dir := t.TempDir()
+	testCases := []struct {
+		name     string
+		contents []byte
+		want     MediaKind
+		hasError bool
+	}{
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), Photo, false},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"), Photo, false},
+		{"mp3 with ID3 tag", []byte("ID3\x03\x00\x00\x00\x00\x00\x00"), Sound, false},
+		{"mp3 without ID3 tag", []byte("\xff\xfb\x90\x64\x00\x00\x00\x00"), Sound, false},
+		{"wav", []byte("RIFF\x24\x00\x00\x00WAVEfmt "), Sound, false},
+		{"mp4", []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), Video, false},
+		{"empty", []byte{}, UnknownMedia, true},
+		{"html error page", []byte("<html><body>Not Found</body></html>"), UnknownMedia, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			if err := os.WriteFile(path, tc.contents, 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ValidateMediaFile(path)
+			if tc.hasError != (err != nil) {
+				t.Errorf("ValidateMediaFile() error = %v, want error %v", err, tc.hasError)
+			}
+			if got != tc.want {
+				t.Errorf("ValidateMediaFile() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		if _, err := ValidateMediaFile(filepath.Join(dir, "missing")); err == nil {
+			t.Error("ValidateMediaFile() of missing file succeeded, want error")
+		}
+	})
+}
+
+func TestDownloadMLAsset(t *testing.T) {
+	mp3 := []byte("ID3\x03\x00\x00\x00\x00\x00\x00")
+	var videoRequests []string // methods
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/100/2400":
+			w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+		case "/200/mp3":
+			w.Write(mp3)
+		case "/500/mp4/1280":
+			videoRequests = append(videoRequests, r.Method)
+			w.Write([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"))
+		case "/300/2400":
+			time.Sleep(100 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(u string, d time.Duration) { MLBaseURL, MLDownloadTimeout = u, d }(MLBaseURL, MLDownloadTimeout)
+	MLBaseURL = server.URL
+	defer func(u string) { MLSearchURL = u }(MLSearchURL)
+	MLSearchURL = server.URL + "/search" // not found, so probe every kind
+	MLDownloadTimeout = 50 * time.Millisecond
+	defer func(dir, pattern string) { MLTempDir, MLTempPattern = dir, pattern }(MLTempDir, MLTempPattern)
+	MLTempDir = t.TempDir()
+	MLTempPattern = "ml-*-download"
+
+	filename, kind, err := DownloadMLAsset(context.Background(), "100")
+	if err != nil {
+		t.Fatalf("DownloadMLAsset(100) error = %v", err)
+	}
+	defer os.Remove(filename)
+	if kind != Photo || filepath.Ext(filename) != ".png" {
+		t.Errorf("DownloadMLAsset(100) = %s, %v; want a .png photo", filename, kind)
+	}
+	if dir, base := filepath.Split(filename); filepath.Clean(dir) != MLTempDir || !strings.HasPrefix(base, "ml-") {
+		t.Errorf("DownloadMLAsset(100) = %s, want a file named ml-* in %s", filename, MLTempDir)
+	}
+
+	filename, kind, err = DownloadMLAsset(context.Background(), "200")
+	if err != nil {
+		t.Fatalf("DownloadMLAsset(200) error = %v", err)
+	}
+	defer os.Remove(filename)
+	if kind != Sound || filepath.Ext(filename) != ".mp3" {
+		t.Errorf("DownloadMLAsset(200) = %s, %v; want an .mp3 sound", filename, kind)
+	}
+
+	// DownloadMLAsset doesn't download videos, which iNaturalist doesn't accept.
+	filename, kind, err = DownloadMLAsset(context.Background(), "500")
+	if err != nil {
+		t.Fatalf("DownloadMLAsset(500) error = %v", err)
+	}
+	if kind != Video || filename != "" {
+		t.Errorf("DownloadMLAsset(500) = %q, %v; want no file and video", filename, kind)
+	}
+	if len(videoRequests) != 1 || videoRequests[0] != "HEAD" {
+		t.Errorf("DownloadMLAsset(500) requested the video with %v, want HEAD only", videoRequests)
+	}
+	if entries, _ := os.ReadDir(MLTempDir); len(entries) != 2 {
+		t.Errorf("DownloadMLAsset(500) left %d files in MLTempDir, want only the photo and sound", len(entries))
+	}
+	filename, kind, err = DownloadMLAssetTo(context.Background(), "500", t.TempDir())
+	if err != nil {
+		t.Fatalf("DownloadMLAssetTo(500) error = %v", err)
+	}
+	if kind != Video || filepath.Ext(filename) != ".mp4" {
+		t.Errorf("DownloadMLAssetTo(500) = %s, %v; want an .mp4 video", filename, kind)
+	}
+	if got, err := ValidateMediaFile(filename); got != Video || err != nil {
+		t.Errorf("ValidateMediaFile(%s) = %v, %v; want video", filename, got, err)
+	}
+
+	if _, _, err := DownloadMLAsset(context.Background(), "300"); err == nil {
+		t.Error("DownloadMLAsset(300) succeeded, want timeout")
+	}
+	if _, _, err := DownloadMLAsset(context.Background(), "400"); err == nil {
+		t.Error("DownloadMLAsset(400) succeeded, want not found")
+	}
+}
+
+func TestDownloadMLAssetMetadata(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch r.URL.Path {
+		case "/search":
+			w.Write([]byte(`{"results": {"content": [{"catalogId": "100", "mediaType": "Audio"}]}}`))
+		case "/100/2400": // the sound's spectrogram
+			w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+		case "/100/mp3":
+			w.Write([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(u, s string) { MLBaseURL, MLSearchURL = u, s }(MLBaseURL, MLSearchURL)
+	MLBaseURL = server.URL
+	MLSearchURL = server.URL + "/search"
+	defer func(dir string) { MLTempDir = dir }(MLTempDir)
+	MLTempDir = t.TempDir()
+
+	filename, kind, err := DownloadMLAsset(context.Background(), "100")
+	if err != nil || kind != Sound || filepath.Ext(filename) != ".mp3" {
+		t.Errorf("DownloadMLAsset(100) = %s, %v, %v; want an .mp3 sound", filename, kind, err)
+	}
+	if want := []string{"/search", "/100/mp3"}; !slices.Equal(requests, want) {
+		t.Errorf("DownloadMLAsset(100) requested %q, want %q", requests, want)
+	}
+	if kind, err := MLAssetKind(context.Background(), "100"); err != nil || kind != Sound {
+		t.Errorf("MLAssetKind(100) = %v, %v; want sound", kind, err)
+	}
+}
+
+func TestCheckMLTempDir(t *testing.T) {
+	defer func(dir string) { MLTempDir = dir }(MLTempDir)
+	MLTempDir = t.TempDir()
+	if err := CheckMLTempDir(); err != nil {
+		t.Errorf("CheckMLTempDir() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(MLTempDir); len(entries) != 0 {
+		t.Errorf("CheckMLTempDir() left %d files behind", len(entries))
+	}
+	MLTempDir = filepath.Join(MLTempDir, "missing")
+	if err := CheckMLTempDir(); err == nil {
+		t.Errorf("CheckMLTempDir(%s) succeeded, want error", MLTempDir)
+	}
+}
+
+func TestRecord_MLAssetIDs(t *testing.T) {
+	r := Record{Line: 12, MLCatalogNumbers: "100  ML200 ml300 100 abc 4x5"}
+	ids, err := r.MLAssetIDs()
+	if want := []MLAssetID{"100", "200", "300"}; !slices.Equal(ids, want) {
+		t.Errorf("MLAssetIDs() = %q, want %q", ids, want)
+	}
+	if err == nil || !strings.Contains(err.Error(), "line 12") || !strings.Contains(err.Error(), `"abc" "4x5"`) {
+		t.Errorf("MLAssetIDs() error = %v, want line 12 with abc and 4x5", err)
+	}
+	if ids, err := (Record{}).MLAssetIDs(); len(ids) != 0 || err != nil {
+		t.Errorf("MLAssetIDs() with no numbers = %q, %v", ids, err)
+	}
+}
+
+func TestDownloadMLAssetRetry(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		n := requests[r.URL.Path]
+		switch r.URL.Path {
+		case "/100/2400": // fails twice, then succeeds
+			if n <= 2 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write(png)
+		case "/200/2400": // the connection breaks once
+			if n == 1 {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+			w.Write(png)
+		case "/300/2400": // always fails
+			http.Error(w, "oops", http.StatusInternalServerError)
+		case "/400/2400": // not retried
+			http.Error(w, "forbidden", http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(u string) { MLBaseURL = u }(MLBaseURL)
+	MLBaseURL = server.URL
+	defer func(u string) { MLSearchURL = u }(MLSearchURL)
+	MLSearchURL = server.URL + "/search"
+	defer func(dir string) { MLTempDir = dir }(MLTempDir)
+	MLTempDir = t.TempDir()
+	defer func(n int, d time.Duration) { MLMaxAttempts, MLRetryDelay = n, d }(MLMaxAttempts, MLRetryDelay)
+	MLMaxAttempts = 3
+	MLRetryDelay = time.Millisecond
+
+	for _, id := range []string{"100", "200"} {
+		if _, kind, err := DownloadMLAsset(context.Background(), id); err != nil || kind != Photo {
+			t.Errorf("DownloadMLAsset(%s) = %v, %v; want a photo after retrying", id, kind, err)
+		}
+	}
+	if _, _, err := DownloadMLAsset(context.Background(), "300"); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("DownloadMLAsset(300) error = %v, want 500", err)
+	}
+	if got := requests["/300/2400"]; got != MLMaxAttempts {
+		t.Errorf("DownloadMLAsset(300) made %d requests, want %d", got, MLMaxAttempts)
+	}
+	if _, _, err := DownloadMLAsset(context.Background(), "400"); err == nil {
+		t.Error("DownloadMLAsset(400) succeeded, want error")
+	}
+	if got := requests["/400/2400"]; got != 1 {
+		t.Errorf("DownloadMLAsset(400) made %d requests, want 1", got)
+	}
+}
+
+func TestDownloadMLAssetCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	defer func(u string) { MLBaseURL = u }(MLBaseURL)
+	MLBaseURL = server.URL
+	defer func(u string) { MLSearchURL = u }(MLSearchURL)
+	MLSearchURL = server.URL + "/search"
+	defer func(n int, d time.Duration) { MLMaxAttempts, MLRetryDelay = n, d }(MLMaxAttempts, MLRetryDelay)
+	MLMaxAttempts = 10
+	MLRetryDelay = time.Hour
+
+	// Canceling stops the wait to retry.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, _, err := DownloadMLAsset(ctx, "100"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DownloadMLAsset() error = %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("DownloadMLAsset() took %v after its context was done", d)
+	}
+}
+
+func TestMLRetryDelay(t *testing.T) {
+	defer func(d time.Duration) { MLRetryDelay = d }(MLRetryDelay)
+	MLRetryDelay = time.Second
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		for range 10 {
+			if got := mlRetryDelay(attempt + 1); got < want/2 || got > want {
+				t.Errorf("mlRetryDelay(%d) = %v, want between %v and %v", attempt+1, got, want/2, want)
+			}
+		}
+	}
+}
+
+func TestDownloadMLAssetTo(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	truncatedRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/100/2400":
+			w.Write(png)
+		case "/200/2400": // the connection breaks partway through
+			truncatedRequests++
+			w.Header().Set("Content-Length", "1000")
+			w.Write(png)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(u string) { MLBaseURL = u }(MLBaseURL)
+	MLBaseURL = server.URL
+	defer func(u string) { MLSearchURL = u }(MLSearchURL)
+	MLSearchURL = server.URL + "/search"
+	defer func(dir string) { MLCacheDir = dir }(MLCacheDir)
+	MLCacheDir = t.TempDir() // not used
+	defer func(n int, d time.Duration) { MLMaxAttempts, MLRetryDelay = n, d }(MLMaxAttempts, MLRetryDelay)
+	MLMaxAttempts = 3
+	MLRetryDelay = time.Millisecond
+
+	dir := t.TempDir()
+	filename, kind, err := DownloadMLAssetTo(context.Background(), "100", dir)
+	if err != nil || kind != Photo || filepath.Dir(filename) != dir || filepath.Ext(filename) != ".png" {
+		t.Errorf("DownloadMLAssetTo(100, %s) = %s, %v, %v; want a .png photo in %s", dir, filename, kind, err, dir)
+	}
+	if entries, _ := os.ReadDir(MLCacheDir); len(entries) != 0 {
+		t.Errorf("DownloadMLAssetTo() cached %d files, want none", len(entries))
+	}
+
+	var b bytes.Buffer
+	kind, err = WriteMLAsset(context.Background(), &b, "100")
+	if err != nil || kind != Photo || !bytes.Equal(b.Bytes(), png) {
+		t.Errorf("WriteMLAsset(100) = %v, %v and wrote %q; want a photo and %q", kind, err, b.Bytes(), png)
+	}
+
+	// A file can be downloaded again from the start, but a writer can't.
+	if _, _, err := DownloadMLAssetTo(context.Background(), "200", dir); err == nil {
+		t.Error("DownloadMLAssetTo(200) succeeded, want error")
+	}
+	if truncatedRequests != MLMaxAttempts {
+		t.Errorf("DownloadMLAssetTo(200) made %d requests, want %d", truncatedRequests, MLMaxAttempts)
+	}
+	truncatedRequests = 0
+	b.Reset()
+	if _, err := WriteMLAsset(context.Background(), &b, "200"); err == nil {
+		t.Error("WriteMLAsset(200) succeeded, want error")
+	}
+	if truncatedRequests != 1 {
+		t.Errorf("WriteMLAsset(200) made %d requests after writing, want 1", truncatedRequests)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("%s has %d files, want 1 (failed downloads are removed)", dir, len(entries))
+	}
+}
@@ -0,0 +1,160 @@
+package ebird
+
+import (
+	"cmp"
+	"errors"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MLCacheDir is the directory in which DownloadMLAsset keeps downloaded
+// assets between runs, so that repeated and resumed syncs don't download
+// them again. If it's empty, there's no cache. Cached files are named by
+// ML asset ID and file extension, like 123456789.jpg.
+var MLCacheDir = ""
+
+// MLCacheMaxBytes limits the total size of the files in MLCacheDir.
+// When a download, or a batch of downloads by DownloadMLAssets, takes the
+// cache over the limit, the oldest files not in the batch are removed.
+var MLCacheMaxBytes int64 = 2 << 30 // 2 GiB
+
+// MLCacheTTL is how long a cached asset is used before it's downloaded
+// again, in case it was edited in the Macaulay Library.
+var MLCacheTTL = 30 * 24 * time.Hour
+
+// cachedMLAsset returns the cached file for the ML asset, if there's
+// an unexpired one, and its kind of media. DownloadMLAsset saves
+// sounds as .mp3 and videos as .mp4; anything else is a photo.
+func cachedMLAsset(mlAssetID string) (filename string, kind MediaKind, ok bool) {
+	if MLCacheDir == "" {
+		return "", UnknownMedia, false
+	}
+	matches, _ := filepath.Glob(filepath.Join(MLCacheDir, mlAssetID+".*"))
+	for _, m := range matches {
+		fi, err := os.Stat(m)
+		if err != nil || !fi.Mode().IsRegular() || fi.Size() == 0 || time.Since(fi.ModTime()) > MLCacheTTL {
+			continue
+		}
+		switch filepath.Ext(m) {
+		case ".mp3":
+			return m, Sound, true
+		case ".mp4":
+			return m, Video, true
+		}
+		return m, Photo, true
+	}
+	return "", UnknownMedia, false
+}
+
+// cacheMLAsset moves the downloaded file filename for the ML asset into
+// MLCacheDir and returns its new name. It doesn't prune the cache, so
+// that the file isn't removed before the caller uses it; the caller
+// prunes it with pruneMLCache once its downloads are done.
+func cacheMLAsset(mlAssetID, filename string) (string, error) {
+	if err := os.MkdirAll(MLCacheDir, 0o755); err != nil {
+		return "", err
+	}
+	cached := filepath.Join(MLCacheDir, mlAssetID+filepath.Ext(filename))
+	if err := os.Rename(filename, cached); err != nil {
+		// The temporary directory may be on another file system.
+		if err := copyFile(cached, filename); err != nil {
+			return "", err
+		}
+		os.Remove(filename)
+	}
+	return cached, nil
+}
+
+// inMLCache reports whether filename is in MLCacheDir.
+func inMLCache(filename string) bool {
+	return MLCacheDir != "" && filepath.Dir(filename) == filepath.Clean(MLCacheDir)
+}
+
+// copyFile copies the file src to dst.
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// mlCachePruneMu serializes calls to pruneMLCache.
+var mlCachePruneMu sync.Mutex
+
+// pruneMLCache removes expired files from MLCacheDir, then the oldest
+// files until the cache fits in MLCacheMaxBytes. It never removes the
+// files in keep, which were just downloaded and are about to be used.
+func pruneMLCache(keep ...string) error {
+	mlCachePruneMu.Lock()
+	defer mlCachePruneMu.Unlock()
+	entries, err := os.ReadDir(MLCacheDir)
+	if err != nil {
+		return err
+	}
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []file
+	var total int64
+	var errs []error
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		path := filepath.Join(MLCacheDir, e.Name())
+		if !slices.Contains(keep, path) && time.Since(fi.ModTime()) > MLCacheTTL {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		files = append(files, file{path, fi.Size(), fi.ModTime()})
+		total += fi.Size()
+	}
+	slices.SortFunc(files, func(a, b file) int {
+		return cmp.Or(a.modTime.Compare(b.modTime), strings.Compare(a.path, b.path))
+	})
+	for _, f := range files {
+		if total <= MLCacheMaxBytes {
+			break
+		}
+		if slices.Contains(keep, f.path) {
+			continue
+		}
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		total -= f.size
+	}
+	return errors.Join(errs...)
+}
+
+// pruneMLCacheOrLog prunes the cache like pruneMLCache, logging any error,
+// since the downloads are still usable.
+func pruneMLCacheOrLog(keep ...string) {
+	if err := pruneMLCache(keep...); err != nil {
+		log.Printf("Pruning %s: %v", MLCacheDir, err)
+	}
+}
@@ -0,0 +1,175 @@
+package ebird
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDownloadMLAssetCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/100/2400":
+			w.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+		case "/200/mp3":
+			w.Write([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"))
+		case "/300/mp4/1280":
+			w.Write([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(u string) { MLBaseURL = u }(MLBaseURL)
+	MLBaseURL = server.URL
+	defer func(u string) { MLSearchURL = u }(MLSearchURL)
+	MLSearchURL = server.URL + "/search"
+	defer func(dir string) { MLTempDir = dir }(MLTempDir)
+	MLTempDir = t.TempDir()
+	defer func(dir string, size int64, ttl time.Duration) {
+		MLCacheDir, MLCacheMaxBytes, MLCacheTTL = dir, size, ttl
+	}(MLCacheDir, MLCacheMaxBytes, MLCacheTTL)
+	MLCacheDir = filepath.Join(t.TempDir(), "cache")
+	MLCacheMaxBytes = 1 << 20
+	MLCacheTTL = time.Hour
+
+	for _, tc := range []struct {
+		id   string
+		want string
+		kind MediaKind
+	}{
+		{"100", "100.png", Photo},
+		{"200", "200.mp3", Sound},
+	} {
+		filename, kind, err := DownloadMLAsset(context.Background(), tc.id)
+		if err != nil {
+			t.Fatalf("DownloadMLAsset(%s) error = %v", tc.id, err)
+		}
+		if want := filepath.Join(MLCacheDir, tc.want); filename != want || kind != tc.kind {
+			t.Errorf("DownloadMLAsset(%s) = %s, %v; want %s, %v", tc.id, filename, kind, want, tc.kind)
+		}
+		n := requests
+		filename2, kind2, err := DownloadMLAsset(context.Background(), tc.id)
+		if err != nil || filename2 != filename || kind2 != kind {
+			t.Errorf("DownloadMLAsset(%s) again = %s, %v, %v; want %s, %v from the cache", tc.id, filename2, kind2, err, filename, kind)
+		}
+		if requests != n {
+			t.Errorf("DownloadMLAsset(%s) again made %d requests, want 0", tc.id, requests-n)
+		}
+	}
+
+	// Videos aren't downloaded, so they aren't cached.
+	if filename, kind, err := DownloadMLAsset(context.Background(), "300"); err != nil || filename != "" || kind != Video {
+		t.Errorf("DownloadMLAsset(300) = %q, %v, %v; want no file and video", filename, kind, err)
+	}
+	if _, err := os.Stat(filepath.Join(MLCacheDir, "300.mp4")); err == nil {
+		t.Error("DownloadMLAsset(300) cached a video")
+	}
+
+	// An expired file is downloaded again.
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(MLCacheDir, "100.png"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	n := requests
+	if _, _, err := DownloadMLAsset(context.Background(), "100"); err != nil {
+		t.Fatalf("DownloadMLAsset(100) after expiry error = %v", err)
+	}
+	if requests == n {
+		t.Error("DownloadMLAsset(100) after expiry used the cache, want a download")
+	}
+}
+
+func TestPruneMLCache(t *testing.T) {
+	defer func(dir string, size int64, ttl time.Duration) {
+		MLCacheDir, MLCacheMaxBytes, MLCacheTTL = dir, size, ttl
+	}(MLCacheDir, MLCacheMaxBytes, MLCacheTTL)
+	MLCacheDir = t.TempDir()
+	MLCacheMaxBytes = 25
+	MLCacheTTL = 24 * time.Hour
+
+	now := time.Now()
+	files := []struct {
+		name string
+		age  time.Duration
+	}{
+		{"1.jpg", 48 * time.Hour}, // expired
+		{"2.jpg", 3 * time.Hour},  // oldest
+		{"3.mp3", 2 * time.Hour},
+		{"4.png", time.Hour},
+		{"5.jpg", 5 * time.Hour}, // older, but just added
+	}
+	for _, f := range files {
+		path := filepath.Join(MLCacheDir, f.name)
+		if err := os.WriteFile(path, make([]byte, 10), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-f.age), now.Add(-f.age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pruneMLCache(filepath.Join(MLCacheDir, "5.jpg")); err != nil {
+		t.Fatalf("pruneMLCache() error = %v", err)
+	}
+	var got []string
+	entries, err := os.ReadDir(MLCacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	want := []string{"4.png", "5.jpg"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("after pruneMLCache(), cache has %q, want %q", got, want)
+	}
+}
+
+func TestDownloadMLAssetsKeepsBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/1/mp3" || r.URL.Path == "/2/mp3" || r.URL.Path == "/3/mp3" {
+			w.Write([]byte("ID3\x03\x00\x00\x00\x00\x00\x00"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	defer func(u string) { MLBaseURL = u }(MLBaseURL)
+	MLBaseURL = server.URL
+	defer func(u string) { MLSearchURL = u }(MLSearchURL)
+	MLSearchURL = server.URL + "/search"
+	defer func(dir string) { MLTempDir = dir }(MLTempDir)
+	MLTempDir = t.TempDir()
+	defer func(dir string, size int64, ttl time.Duration) {
+		MLCacheDir, MLCacheMaxBytes, MLCacheTTL = dir, size, ttl
+	}(MLCacheDir, MLCacheMaxBytes, MLCacheTTL)
+	MLCacheDir = filepath.Join(t.TempDir(), "cache")
+	MLCacheMaxBytes = 1 // smaller than any one file
+	MLCacheTTL = time.Hour
+
+	// Every file in the batch is over the limit, but none may be
+	// removed before the caller uploads it.
+	for _, d := range DownloadMLAssets(context.Background(), []string{"1", "2", "3"}, 3, nil) {
+		if d.Err != nil {
+			t.Fatalf("DownloadMLAssets() %s error = %v", d.ID, d.Err)
+		}
+		if _, err := os.Stat(d.Filename); err != nil {
+			t.Errorf("DownloadMLAssets() removed %s from the cache: %v", d.ID, err)
+		}
+	}
+	// The next batch prunes the earlier one.
+	DownloadMLAssets(context.Background(), []string{"3"}, 1, nil)
+	entries, err := os.ReadDir(MLCacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "3.mp3" {
+		t.Errorf("after the next batch, cache has %v, want only 3.mp3", entries)
+	}
+}
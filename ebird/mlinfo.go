@@ -0,0 +1,105 @@
+package ebird
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// MLSearchURL is the Macaulay Library search API, which returns
+// the metadata of assets.
+var MLSearchURL = "https://search.macaulaylibrary.org/api/v1/search"
+
+// MLAssetInfo is the metadata of a Macaulay Library asset.
+type MLAssetInfo struct {
+	ID        MLAssetID
+	Kind      MediaKind
+	License   string  // like "CC BY-NC-SA 4.0"; empty if the ML doesn't say
+	Rating    float64 // average rating from 1 to 5, or 0 if unrated
+	Recordist string  // the photographer or sound recordist
+	Date      string  // observation date, as the ML formats it
+}
+
+// mlSearchResults is returned by MLSearchURL.
+type mlSearchResults struct {
+	Results struct {
+		Content []struct {
+			CatalogID       string          `json:"catalogId"`
+			MediaType       string          `json:"mediaType"` // "Photo", "Audio", or "Video"
+			Rating          json.RawMessage `json:"rating"`    // a number or a numeric string
+			UserDisplayName string          `json:"userDisplayName"`
+			ObsDt           string          `json:"obsDt"`
+			License         string          `json:"licenseType"`
+		} `json:"content"`
+	} `json:"results"`
+}
+
+// GetMLAssetInfo returns the metadata of the ML asset with the provided ID.
+// Unlike DownloadMLAsset and MLAssetKind, it tells photos, sounds,
+// and videos apart in one request.
+func GetMLAssetInfo(ctx context.Context, mlAssetID string) (MLAssetInfo, error) {
+	u := MLSearchURL + "?" + url.Values{"catId": {mlAssetID}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return MLAssetInfo{}, fmt.Errorf("GetMLAssetInfo(%s): %w", mlAssetID, err)
+	}
+	resp, err := mlClient().Do(req)
+	if err != nil {
+		return MLAssetInfo{}, fmt.Errorf("GetMLAssetInfo(%s): %w", mlAssetID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return MLAssetInfo{}, fmt.Errorf("GetMLAssetInfo(%s): %s", mlAssetID, resp.Status)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return MLAssetInfo{}, fmt.Errorf("GetMLAssetInfo(%s): %w", mlAssetID, err)
+	}
+	var results mlSearchResults
+	if err := json.Unmarshal(b, &results); err != nil {
+		return MLAssetInfo{}, fmt.Errorf("GetMLAssetInfo(%s): %w", mlAssetID, err)
+	}
+	for _, c := range results.Results.Content {
+		if c.CatalogID != mlAssetID {
+			continue
+		}
+		info := MLAssetInfo{
+			ID:        MLAssetID(c.CatalogID),
+			License:   c.License,
+			Recordist: c.UserDisplayName,
+			Date:      c.ObsDt,
+		}
+		switch c.MediaType {
+		case "Photo":
+			info.Kind = Photo
+		case "Audio":
+			info.Kind = Sound
+		case "Video":
+			info.Kind = Video
+		}
+		var rating string
+		if json.Unmarshal(c.Rating, &rating) != nil {
+			rating = string(c.Rating)
+		}
+		info.Rating, _ = strconv.ParseFloat(rating, 64)
+		return info, nil
+	}
+	return MLAssetInfo{}, fmt.Errorf("GetMLAssetInfo(%s): asset not found", mlAssetID)
+}
+
+// Attribution returns a credit line for the asset, like
+// "© Jane Birder; CC BY-NC-SA 4.0; Macaulay Library ML123".
+func (info MLAssetInfo) Attribution() string {
+	s := "©"
+	if info.Recordist != "" {
+		s += " " + info.Recordist + ";"
+	}
+	if info.License != "" {
+		s += " " + info.License + ";"
+	}
+	return s + " Macaulay Library ML" + string(info.ID)
+}
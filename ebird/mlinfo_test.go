@@ -0,0 +1,49 @@
+package ebird
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetMLAssetInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("catId") {
+		case "100":
+			w.Write([]byte(`{"results": {"content": [{"catalogId": "100", "mediaType": "Photo", "rating": "4.5",
+				"userDisplayName": "Jane Birder", "obsDt": "15 Jan 2024", "licenseType": "CC BY-NC-SA 4.0"}]}}`))
+		case "200":
+			w.Write([]byte(`{"results": {"content": [{"catalogId": "200", "mediaType": "Audio", "rating": 3}]}}`))
+		default:
+			w.Write([]byte(`{"results": {"content": []}}`))
+		}
+	}))
+	defer server.Close()
+	defer func(u string) { MLSearchURL = u }(MLSearchURL)
+	MLSearchURL = server.URL
+
+	info, err := GetMLAssetInfo(context.Background(), "100")
+	if err != nil {
+		t.Fatalf("GetMLAssetInfo(100) error: %v", err)
+	}
+	want := MLAssetInfo{ID: "100", Kind: Photo, License: "CC BY-NC-SA 4.0", Rating: 4.5, Recordist: "Jane Birder", Date: "15 Jan 2024"}
+	if info != want {
+		t.Errorf("GetMLAssetInfo(100) = %+v, want %+v", info, want)
+	}
+	if got := info.Attribution(); got != "© Jane Birder; CC BY-NC-SA 4.0; Macaulay Library ML100" {
+		t.Errorf("Attribution() = %q", got)
+	}
+
+	info, err = GetMLAssetInfo(context.Background(), "200")
+	if err != nil || info.Kind != Sound || info.Rating != 3 {
+		t.Errorf("GetMLAssetInfo(200) = %+v, %v; want a sound rated 3", info, err)
+	}
+	if got := info.Attribution(); got != "© Macaulay Library ML200" {
+		t.Errorf("Attribution() without recordist or license = %q", got)
+	}
+
+	if _, err := GetMLAssetInfo(context.Background(), "300"); err == nil {
+		t.Errorf("GetMLAssetInfo(300) succeeded, want not found")
+	}
+}
@@ -0,0 +1,62 @@
+package ebird
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ObserverNotes returns the parts of a shared checklist's Observation Details
+// that apply to the named observer.
+//
+// eBird doesn't structure per-observer notes, so this relies on a common
+// convention: each observer's note is prefixed with their name and a colon,
+// and notes are separated by newlines or semicolons, like
+// "Alice: heard only; Bob: saw it fly over". Matching names is
+// case-insensitive. ObserverNotes keeps observer's notes (without the name
+// prefix) and any notes that have no prefix, since those apply to everyone.
+// Notes prefixed with another name are assumed to belong to other observers.
+// A prefix that doesn't look like a name, such as a time ("Seen at 7:45")
+// or a URL, isn't one, so the whole note applies to everyone.
+//
+// If no note is prefixed with observer's name, the details can't be
+// disambiguated, and ObserverNotes returns them unchanged.
+func ObserverNotes(details, observer string) string {
+	observer = strings.TrimSpace(observer)
+	if observer == "" {
+		return details
+	}
+	parts := strings.FieldsFunc(details, func(r rune) bool {
+		return r == '\n' || r == ';'
+	})
+	var kept []string
+	found := false
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, note, ok := strings.Cut(part, ":")
+		switch {
+		case !ok || !looksLikeName(name) || strings.HasPrefix(note, "//"):
+			kept = append(kept, part)
+		case strings.EqualFold(strings.TrimSpace(name), observer):
+			found = true
+			kept = append(kept, strings.TrimSpace(note))
+		}
+	}
+	if !found {
+		return details
+	}
+	return strings.Join(kept, "\n")
+}
+
+// maxNameWords is the most words in an observer name prefix.
+const maxNameWords = 4
+
+// looksLikeName reports whether the prefix s of a note could be an
+// observer's name: a few words without digits.
+func looksLikeName(s string) bool {
+	words := strings.Fields(s)
+	return len(words) > 0 && len(words) <= maxNameWords &&
+		!strings.ContainsFunc(s, unicode.IsDigit)
+}
@@ -0,0 +1,62 @@
+package ebird
+
+import "testing"
+
+func TestObserverNotes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		details  string
+		observer string
+		want     string
+	}{
+		{
+			name:     "semicolons",
+			details:  "Alice: heard only; Bob: saw it fly over",
+			observer: "Bob",
+			want:     "saw it fly over",
+		},
+		{
+			name:     "newlines and shared notes",
+			details:  "Calling from the marsh\nALICE: heard only\nbob: saw it fly over",
+			observer: "Bob",
+			want:     "Calling from the marsh\nsaw it fly over",
+		},
+		{
+			name:     "observer not mentioned",
+			details:  "Alice: heard only; Carol: saw it",
+			observer: "Bob",
+			want:     "Alice: heard only; Carol: saw it",
+		},
+		{
+			name:     "no prefixes",
+			details:  "Perched on a wire",
+			observer: "Bob",
+			want:     "Perched on a wire",
+		},
+		{
+			name:     "prefixes that aren't names",
+			details:  "Seen at 7:45; see https://example.com/photo; Bob: saw it fly over",
+			observer: "Bob",
+			want:     "Seen at 7:45\nsee https://example.com/photo\nsaw it fly over",
+		},
+		{
+			name:     "sentence with a colon",
+			details:  "Two birds were seen together near the pond: one flew off; Bob: saw it fly over",
+			observer: "Bob",
+			want:     "Two birds were seen together near the pond: one flew off\nsaw it fly over",
+		},
+		{
+			name:     "no observer configured",
+			details:  "Alice: heard only; Bob: saw it fly over",
+			observer: "",
+			want:     "Alice: heard only; Bob: saw it fly over",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ObserverNotes(tc.details, tc.observer); got != tc.want {
+				t.Errorf("ObserverNotes(%q, %q) = %q, want %q", tc.details, tc.observer, got, tc.want)
+			}
+		})
+	}
+}
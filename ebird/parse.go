@@ -0,0 +1,130 @@
+package ebird
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParsedRecord holds the numeric fields of a Record as Go values.
+// Fields that are empty in the Record are zero here; the Has fields
+// distinguish a missing value from a zero one where that matters.
+type ParsedRecord struct {
+	Record
+
+	Count   int  // zero if Present
+	Present bool // the count is "X": present but not counted
+
+	Duration          time.Duration
+	DistanceKm        float64
+	AreaHa            float64
+	NumberOfObservers int
+
+	HasCoordinates      bool
+	Latitude, Longitude float64
+}
+
+// Parse converts the record's numeric fields into a ParsedRecord.
+// It returns an error describing every field that isn't a valid number,
+// is negative, or (for coordinates) is out of range, along with a
+// ParsedRecord in which those fields are zero.
+func (r Record) Parse() (ParsedRecord, error) {
+	p, fieldErrs := r.parse()
+	if len(fieldErrs) > 0 {
+		errs := make([]error, len(fieldErrs))
+		for i, e := range fieldErrs {
+			errs[i] = e
+		}
+		return p, fmt.Errorf("line %d: %w", r.Line, errors.Join(errs...))
+	}
+	return p, nil
+}
+
+// fieldError is a problem with the value of a column of a record.
+type fieldError struct {
+	column, value string
+	err           error
+}
+
+func (e fieldError) Error() string {
+	if e.value == "" {
+		return fmt.Sprintf("%s: %v", e.column, e.err)
+	}
+	return fmt.Sprintf("%s %q: %v", e.column, e.value, e.err)
+}
+
+func (e fieldError) Unwrap() error { return e.err }
+
+// parse implements Parse, returning the problems with each field.
+func (r Record) parse() (ParsedRecord, []fieldError) {
+	p := ParsedRecord{Record: r}
+	var errs []fieldError
+	bad := func(column, value string, err error) {
+		errs = append(errs, fieldError{column, value, err})
+	}
+	parseInt := func(column, value string) int {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return 0
+		}
+		n, err := strconv.Atoi(value)
+		if err == nil && n < 0 {
+			err = errors.New("negative")
+		}
+		if err != nil {
+			bad(column, value, err)
+			return 0
+		}
+		return n
+	}
+	parseFloat := func(column, value string, limit float64) float64 {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return 0
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+			err = errors.New("not a finite number")
+		}
+		if err == nil && limit == 0 && f < 0 {
+			err = errors.New("negative")
+		}
+		if err == nil && limit > 0 && math.Abs(f) > limit {
+			err = fmt.Errorf("beyond ±%v", limit)
+		}
+		if err != nil {
+			bad(column, value, err)
+			return 0
+		}
+		return f
+	}
+
+	if strings.EqualFold(strings.TrimSpace(r.Count), "X") {
+		p.Present = true
+	} else {
+		p.Count = parseInt("Count", r.Count)
+	}
+	p.Duration = time.Duration(parseInt("Duration (Min)", r.DurationMin)) * time.Minute
+	p.DistanceKm = parseFloat("Distance Traveled (km)", r.DistanceTraveledKm, 0)
+	p.AreaHa = parseFloat("Area Covered (ha)", r.AreaCoveredHa, 0)
+	p.NumberOfObservers = parseInt("Number of Observers", r.NumberOfObservers)
+	if strings.TrimSpace(r.Latitude) != "" || strings.TrimSpace(r.Longitude) != "" {
+		n := len(errs)
+		p.Latitude = parseFloat("Latitude", r.Latitude, 90)
+		p.Longitude = parseFloat("Longitude", r.Longitude, 180)
+		switch {
+		case strings.TrimSpace(r.Latitude) == "":
+			bad("Latitude", "", errors.New("missing, but Longitude is set"))
+		case strings.TrimSpace(r.Longitude) == "":
+			bad("Longitude", "", errors.New("missing, but Latitude is set"))
+		}
+		p.HasCoordinates = len(errs) == n
+		if !p.HasCoordinates {
+			p.Latitude, p.Longitude = 0, 0
+		}
+	}
+	return p, errs
+}
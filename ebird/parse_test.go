@@ -0,0 +1,59 @@
+package ebird
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecord_Parse(t *testing.T) {
+	r := Record{
+		Line:               7,
+		Count:              "12",
+		DurationMin:        "90",
+		DistanceTraveledKm: "2.5",
+		AreaCoveredHa:      "",
+		NumberOfObservers:  "3",
+		Latitude:           "37.25",
+		Longitude:          "-122.5",
+	}
+	p, err := r.Parse()
+	if err != nil {
+		t.Fatalf("Parse() error: %v", err)
+	}
+	if p.Count != 12 || p.Present || p.Duration != 90*time.Minute || p.DistanceKm != 2.5 || p.AreaHa != 0 ||
+		p.NumberOfObservers != 3 || !p.HasCoordinates || p.Latitude != 37.25 || p.Longitude != -122.5 {
+		t.Errorf("Parse() = %+v", p)
+	}
+
+	p, err = Record{Count: "X"}.Parse()
+	if err != nil || !p.Present || p.Count != 0 || p.HasCoordinates {
+		t.Errorf("Parse(X, no coordinates) = %+v, %v; want present without coordinates", p, err)
+	}
+
+	bad := Record{
+		Line:              9,
+		Count:             "many",
+		DurationMin:       "-5",
+		NumberOfObservers: "1",
+		Latitude:          "95",
+		Longitude:         "10",
+	}
+	p, err = bad.Parse()
+	if err == nil {
+		t.Fatalf("Parse(%+v) succeeded, want an error", bad)
+	}
+	for _, want := range []string{"line 9", "Count \"many\"", "Duration (Min) \"-5\": negative", "Latitude \"95\": beyond ±90"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse() error %q doesn't mention %q", err, want)
+		}
+	}
+	// The valid fields are still parsed.
+	if p.NumberOfObservers != 1 || p.HasCoordinates || p.Latitude != 0 {
+		t.Errorf("Parse() with errors = %+v, want 1 observer and no coordinates", p)
+	}
+
+	if _, err := (Record{Latitude: "37"}).Parse(); err == nil {
+		t.Errorf("Parse() with only a latitude succeeded, want an error")
+	}
+}
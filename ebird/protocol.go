@@ -0,0 +1,121 @@
+package ebird
+
+import (
+	"strings"
+	"time"
+)
+
+// Protocol is an eBird checklist protocol.
+type Protocol int
+
+const (
+	UnknownProtocol Protocol = iota
+	Traveling
+	Stationary
+	Incidental
+	Area
+	Historical
+	Pelagic
+	NocturnalFlightCall
+	Banding
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case Traveling:
+		return "Traveling"
+	case Stationary:
+		return "Stationary"
+	case Incidental:
+		return "Incidental"
+	case Area:
+		return "Area"
+	case Historical:
+		return "Historical"
+	case Pelagic:
+		return "Pelagic"
+	case NocturnalFlightCall:
+		return "Nocturnal Flight Call"
+	case Banding:
+		return "Banding"
+	}
+	return "Unknown"
+}
+
+// protocolKeywords identifies protocols by words in their names.
+// eBird's exports name protocols like "eBird - Traveling Count" and
+// "eBird - Casual Observation", and the names have changed over time,
+// so ParseProtocol matches keywords rather than whole names.
+var protocolKeywords = []struct {
+	keyword  string
+	protocol Protocol
+}{
+	{"traveling", Traveling},
+	{"stationary", Stationary},
+	{"incidental", Incidental},
+	{"casual", Incidental},
+	{"area", Area},
+	{"historical", Historical},
+	{"pelagic", Pelagic},
+	{"nocturnal flight call", NocturnalFlightCall},
+	{"banding", Banding},
+}
+
+// ParseProtocol returns the protocol named by s, a value of
+// the Protocol column in MyEBirdData.csv.
+// It returns UnknownProtocol if it doesn't recognize s.
+func ParseProtocol(s string) Protocol {
+	s = strings.ToLower(s)
+	for _, k := range protocolKeywords {
+		if strings.Contains(s, k.keyword) {
+			return k.protocol
+		}
+	}
+	return UnknownProtocol
+}
+
+// Effort describes how much birding went into a checklist.
+// Fields that the checklist doesn't report are zero.
+type Effort struct {
+	Protocol   Protocol
+	Duration   time.Duration
+	DistanceKm float64
+	AreaHa     float64
+	Observers  int
+}
+
+// Effort returns the effort of the record's checklist.
+// It returns an error if any of the effort fields is malformed,
+// along with an Effort in which those fields are zero.
+func (r Record) Effort() (Effort, error) {
+	p, err := Record{
+		Line:               r.Line,
+		DurationMin:        r.DurationMin,
+		DistanceTraveledKm: r.DistanceTraveledKm,
+		AreaCoveredHa:      r.AreaCoveredHa,
+		NumberOfObservers:  r.NumberOfObservers,
+	}.Parse()
+	return Effort{
+		Protocol:   ParseProtocol(r.Protocol),
+		Duration:   p.Duration,
+		DistanceKm: p.DistanceKm,
+		AreaHa:     p.AreaHa,
+		Observers:  p.NumberOfObservers,
+	}, err
+}
+
+// Effort returns the effort of the checklist. See Record.Effort.
+func (c Checklist) Effort() (Effort, error) {
+	line := 0
+	if len(c.Records) > 0 {
+		line = c.Records[0].Line
+	}
+	return Record{
+		Line:               line,
+		Protocol:           c.Protocol,
+		DurationMin:        c.DurationMin,
+		DistanceTraveledKm: c.DistanceTraveledKm,
+		AreaCoveredHa:      c.AreaCoveredHa,
+		NumberOfObservers:  c.NumberOfObservers,
+	}.Effort()
+}
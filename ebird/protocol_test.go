@@ -0,0 +1,53 @@
+package ebird
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProtocol(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want Protocol
+	}{
+		{"eBird - Traveling Count", Traveling},
+		{"eBird - Stationary Count", Stationary},
+		{"Stationary", Stationary},
+		{"eBird - Casual Observation", Incidental},
+		{"Incidental", Incidental},
+		{"eBird - Exhaustive Area Count", Area},
+		{"Historical", Historical},
+		{"eBird Pelagic Protocol", Pelagic},
+		{"eBird - Nocturnal Flight Call Count", NocturnalFlightCall},
+		{"", UnknownProtocol},
+		{"eBird Random", UnknownProtocol},
+	}
+	for _, tc := range testCases {
+		if got := ParseProtocol(tc.in); got != tc.want {
+			t.Errorf("ParseProtocol(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestEffort(t *testing.T) {
+	r := Record{
+		Count:              "many", // not part of the effort
+		Protocol:           "eBird - Traveling Count",
+		DurationMin:        "45",
+		DistanceTraveledKm: "1.2",
+		NumberOfObservers:  "2",
+	}
+	want := Effort{Protocol: Traveling, Duration: 45 * time.Minute, DistanceKm: 1.2, Observers: 2}
+	if got, err := r.Effort(); err != nil || got != want {
+		t.Errorf("Record.Effort() = %+v, %v; want %+v", got, err, want)
+	}
+	if got, err := NewChecklist([]Record{r}).Effort(); err != nil || got != want {
+		t.Errorf("Checklist.Effort() = %+v, %v; want %+v", got, err, want)
+	}
+
+	r.DurationMin = "forever"
+	got, err := r.Effort()
+	if err == nil || got.Duration != 0 || got.Observers != 2 {
+		t.Errorf("Effort() with a bad duration = %+v, %v; want an error and 2 observers", got, err)
+	}
+}
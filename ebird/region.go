@@ -0,0 +1,184 @@
+package ebird
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// RegionCode is an eBird region code: a country, like "US";
+// a subnational1 region (a state or province), like "US-VA";
+// or a subnational2 region (a county), like "US-VA-059".
+// The State/Province column of an export is a subnational1 code,
+// but the County column is a name; see Regions.
+type RegionCode string
+
+// Region types, as the eBird API names them.
+const (
+	RegionCountry      = "country"
+	RegionSubnational1 = "subnational1"
+	RegionSubnational2 = "subnational2"
+)
+
+// ParseRegionCode parses s as a region code, ignoring case and
+// surrounding space.
+func ParseRegionCode(s string) (RegionCode, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	parts := strings.Split(s, "-")
+	if len(parts) > 3 {
+		return "", fmt.Errorf("ParseRegionCode(%q): too many parts", s)
+	}
+	for i, p := range parts {
+		if p == "" || strings.Trim(p, "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789") != "" {
+			return "", fmt.Errorf("ParseRegionCode(%q): bad part %q", s, p)
+		}
+		if i == 0 && (len(p) != 2 || strings.Trim(p, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "") {
+			return "", fmt.Errorf("ParseRegionCode(%q): country %q isn't two letters", s, p)
+		}
+	}
+	return RegionCode(s), nil
+}
+
+// Type returns RegionCountry, RegionSubnational1, or RegionSubnational2.
+func (c RegionCode) Type() string {
+	switch strings.Count(string(c), "-") {
+	case 0:
+		return RegionCountry
+	case 1:
+		return RegionSubnational1
+	}
+	return RegionSubnational2
+}
+
+// Parent returns the region that contains c, like "US-VA" for "US-VA-059",
+// or the empty string for a country.
+func (c RegionCode) Parent() RegionCode {
+	i := strings.LastIndex(string(c), "-")
+	if i < 0 {
+		return ""
+	}
+	return c[:i]
+}
+
+// Country returns the country of c, like "US" for "US-VA-059".
+func (c RegionCode) Country() RegionCode {
+	country, _, _ := strings.Cut(string(c), "-")
+	return RegionCode(country)
+}
+
+// Contains reports whether other is c or one of its subregions.
+func (c RegionCode) Contains(other RegionCode) bool {
+	return c != "" && (other == c || strings.HasPrefix(string(other), string(c)+"-"))
+}
+
+// Region is a named eBird region.
+type Region struct {
+	Code RegionCode
+	Name string // like "Fairfax"
+}
+
+// SubregionList returns the regions of type regionType, such as
+// RegionSubnational2, within parent, such as "US-VA". Save the list
+// for repeated lookups; the eBird API asks clients not to fetch it often.
+func (c *APIClient) SubregionList(ctx context.Context, regionType string, parent RegionCode) ([]Region, error) {
+	var list []struct {
+		Code string `json:"code"`
+		Name string `json:"name"`
+	}
+	if err := c.get(ctx, "/ref/region/list/"+regionType+"/"+string(parent), nil, &list); err != nil {
+		return nil, fmt.Errorf("SubregionList(%s, %s): %w", regionType, parent, err)
+	}
+	regions := make([]Region, len(list))
+	for i, r := range list {
+		regions[i] = Region{RegionCode(r.Code), r.Name}
+	}
+	return regions, nil
+}
+
+// Regions maps between region codes and names, such as the county
+// names in exports, using lists from SubregionList.
+// The zero value is empty and ready to use.
+type Regions struct {
+	names map[RegionCode]string
+	codes map[RegionCode]map[string]RegionCode // parent to lowercase name to code
+}
+
+// Add adds regions to rs.
+func (rs *Regions) Add(regions ...Region) {
+	if rs.names == nil {
+		rs.names = map[RegionCode]string{}
+		rs.codes = map[RegionCode]map[string]RegionCode{}
+	}
+	for _, r := range regions {
+		rs.names[r.Code] = r.Name
+		parent := r.Code.Parent()
+		if rs.codes[parent] == nil {
+			rs.codes[parent] = map[string]RegionCode{}
+		}
+		rs.codes[parent][strings.ToLower(strings.TrimSpace(r.Name))] = r.Code
+	}
+}
+
+// Name returns the name of the region with the provided code.
+func (rs *Regions) Name(code RegionCode) (string, bool) {
+	name, ok := rs.names[code]
+	return name, ok
+}
+
+// Code returns the code of the region named name within parent,
+// ignoring case, like "US-VA-059" for "Fairfax" in "US-VA".
+func (rs *Regions) Code(parent RegionCode, name string) (RegionCode, bool) {
+	code, ok := rs.codes[parent][strings.ToLower(strings.TrimSpace(name))]
+	return code, ok
+}
+
+// StateRegion returns the record's State/Province as a region code.
+func (r Record) StateRegion() (RegionCode, bool) {
+	code, err := ParseRegionCode(r.StateProvince)
+	if err != nil || code.Type() != RegionSubnational1 {
+		return "", false
+	}
+	return code, true
+}
+
+// CountyRegion returns the region code of the record's County, looked up
+// in regions by name within its State/Province. ok is false if the record
+// has no county or regions doesn't know it.
+func (r Record) CountyRegion(regions *Regions) (RegionCode, bool) {
+	state, ok := r.StateRegion()
+	if !ok || strings.TrimSpace(r.County) == "" {
+		return "", false
+	}
+	return regions.Code(state, r.County)
+}
+
+// Region returns the most specific region code known for the record:
+// its county if regions knows it, otherwise its state or province,
+// otherwise its country. regions may be nil.
+func (r Record) Region(regions *Regions) (RegionCode, bool) {
+	if regions != nil {
+		if code, ok := r.CountyRegion(regions); ok {
+			return code, true
+		}
+	}
+	if code, ok := r.StateRegion(); ok {
+		return code, true
+	}
+	country, _, _ := strings.Cut(r.StateProvince, "-")
+	if code, err := ParseRegionCode(country); err == nil {
+		return code, true
+	}
+	return "", false
+}
+
+// RecordsInRegion returns the records observed in region, such as "US",
+// "US-VA", or "US-VA-059". Like [Filter], it's lazy. Filtering by
+// county needs regions to look up the records' counties by name;
+// otherwise regions may be nil.
+func RecordsInRegion(records iter.Seq[Record], region RegionCode, regions *Regions) iter.Seq[Record] {
+	return Filter(records, func(rec Record) bool {
+		code, ok := rec.Region(regions)
+		return ok && region.Contains(code)
+	})
+}
@@ -0,0 +1,112 @@
+package ebird
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestParseRegionCode(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		want     RegionCode
+		typ      string
+		parent   RegionCode
+		hasError bool
+	}{
+		{"US", "US", RegionCountry, "", false},
+		{" us-va ", "US-VA", RegionSubnational1, "US", false},
+		{"US-VA-059", "US-VA-059", RegionSubnational2, "US-VA", false},
+		{"CR-P", "CR-P", RegionSubnational1, "CR", false},
+		{"", "", "", "", true},
+		{"USA", "", "", "", true},
+		{"US--059", "", "", "", true},
+		{"US-VA-059-1", "", "", "", true},
+		{"US-V A", "", "", "", true},
+	} {
+		got, err := ParseRegionCode(tc.in)
+		if (err != nil) != tc.hasError || got != tc.want {
+			t.Errorf("ParseRegionCode(%q) = %q, %v; want %q, error %v", tc.in, got, err, tc.want, tc.hasError)
+			continue
+		}
+		if err == nil && (got.Type() != tc.typ || got.Parent() != tc.parent) {
+			t.Errorf("%q: Type() = %s, Parent() = %q; want %s, %q", got, got.Type(), got.Parent(), tc.typ, tc.parent)
+		}
+	}
+	if got := RegionCode("US-VA-059").Country(); got != "US" {
+		t.Errorf("Country() = %q, want US", got)
+	}
+}
+
+func TestRegionCodeContains(t *testing.T) {
+	for _, tc := range []struct {
+		c, other RegionCode
+		want     bool
+	}{
+		{"US", "US-VA-059", true},
+		{"US-VA", "US-VA-059", true},
+		{"US-VA", "US-VA", true},
+		{"US-VA", "US-VT", false},
+		{"US-V", "US-VA", false},
+		{"US-VA-059", "US-VA", false},
+		{"", "US", false},
+	} {
+		if got := tc.c.Contains(tc.other); got != tc.want {
+			t.Errorf("%q.Contains(%q) = %v, want %v", tc.c, tc.other, got, tc.want)
+		}
+	}
+}
+
+func TestRegions(t *testing.T) {
+	taxonomyRequests := 0
+	server := newTestAPIServer(t, &taxonomyRequests)
+	client := NewAPIClient(server.URL, "key", "test")
+	list, err := client.SubregionList(context.Background(), RegionSubnational2, "US-VA")
+	if err != nil {
+		t.Fatalf("SubregionList() error: %v", err)
+	}
+	var regions Regions
+	regions.Add(list...)
+	if code, ok := regions.Code("US-VA", "fairfax "); !ok || code != "US-VA-059" {
+		t.Errorf("Code(US-VA, fairfax) = %q, %v; want US-VA-059", code, ok)
+	}
+	if _, ok := regions.Code("US-MD", "Fairfax"); ok {
+		t.Errorf("Code(US-MD, Fairfax) succeeded, want not found")
+	}
+	if name, ok := regions.Name("US-VA-510"); !ok || name != "Alexandria (city)" {
+		t.Errorf("Name(US-VA-510) = %q, %v; want Alexandria (city)", name, ok)
+	}
+
+	records := []Record{
+		{Line: 2, StateProvince: "US-VA", County: "Fairfax"},
+		{Line: 3, StateProvince: "US-VA", County: "Loudoun"}, // unknown county
+		{Line: 4, StateProvince: "US-MD", County: "Fairfax"},
+		{Line: 5, StateProvince: "US"}, // country only
+	}
+	if code, ok := records[0].CountyRegion(&regions); !ok || code != "US-VA-059" {
+		t.Errorf("CountyRegion() = %q, %v; want US-VA-059", code, ok)
+	}
+	if code, ok := records[1].Region(&regions); !ok || code != "US-VA" {
+		t.Errorf("Region() of unknown county = %q, %v; want US-VA", code, ok)
+	}
+	if code, ok := records[3].Region(nil); !ok || code != "US" {
+		t.Errorf("Region() of country = %q, %v; want US", code, ok)
+	}
+
+	lines := func(region RegionCode, regions *Regions) []int {
+		var lines []int
+		for rec := range RecordsInRegion(slices.Values(records), region, regions) {
+			lines = append(lines, rec.Line)
+		}
+		return lines
+	}
+	if got := lines("US-VA-059", &regions); !slices.Equal(got, []int{2}) {
+		t.Errorf("RecordsInRegion(US-VA-059) lines = %v, want [2]", got)
+	}
+	if got := lines("US-VA", nil); !slices.Equal(got, []int{2, 3}) {
+		t.Errorf("RecordsInRegion(US-VA) lines = %v, want [2 3]", got)
+	}
+	if got := lines("US", nil); !slices.Equal(got, []int{2, 3, 4, 5}) {
+		t.Errorf("RecordsInRegion(US) lines = %v, want [2 3 4 5]", got)
+	}
+}
@@ -0,0 +1,121 @@
+package ebird
+
+import (
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// When a checklist is shared, each observer gets a copy with its own
+// submission ID, but eBird copies the location, date, time, and effort,
+// including the number of observers. Observers may then add or remove
+// species on their own copies. Syncing more than one copy, such as the
+// exports of two people who share an iNaturalist account, or an export
+// that contains a checklist and a copy shared with its owner, would create
+// duplicate iNaturalist observations for the species they have in common.
+
+// sharedChecklistKey is the checklist metadata that eBird copies to every
+// observer's copy of a shared checklist.
+type sharedChecklistKey struct {
+	location          string // location ID, or coordinates for records without one
+	date, time        string
+	protocol          string
+	duration          string
+	distance, area    string
+	numberOfObservers string
+}
+
+// shared reports whether c may be a copy of a shared checklist,
+// which has more than one observer.
+func (c Checklist) shared() bool {
+	n, err := strconv.Atoi(strings.TrimSpace(c.NumberOfObservers))
+	return err == nil && n > 1
+}
+
+// sharedKey returns the metadata that c has in common with other copies.
+func (c Checklist) sharedKey() sharedChecklistKey {
+	location := c.LocationID
+	if location == "" && len(c.Records) > 0 {
+		location = c.Records[0].Latitude + "," + c.Records[0].Longitude
+	}
+	return sharedChecklistKey{
+		location:          location,
+		date:              c.Date,
+		time:              c.Time,
+		protocol:          c.Protocol,
+		duration:          c.DurationMin,
+		distance:          c.DistanceTraveledKm,
+		area:              c.AreaCoveredHa,
+		numberOfObservers: c.NumberOfObservers,
+	}
+}
+
+// SharedDuplicates finds the observations in records that are on copies
+// of a shared checklist that appears earlier in records, and maps each
+// to the same species on the earlier copy. Copies have more than one
+// observer and the same location, date, time, protocol, and effort.
+// Species that are only on a later copy aren't duplicates.
+// SharedDuplicates reads all the records; see GroupChecklists.
+func SharedDuplicates(records iter.Seq[Record]) map[ObservationID]ObservationID {
+	dups := map[ObservationID]ObservationID{}
+	originals := map[sharedChecklistKey]Checklist{}
+	for c := range GroupChecklists(records) {
+		if !c.shared() {
+			continue
+		}
+		key := c.sharedKey()
+		orig, ok := originals[key]
+		if !ok {
+			originals[key] = c
+			continue
+		}
+		species := map[string]bool{}
+		for _, name := range orig.Species() {
+			species[name] = true
+		}
+		for _, rec := range c.Records {
+			if species[rec.ScientificName] {
+				dups[rec.ObservationID()] = ObservationID{orig.SubmissionID, rec.ScientificName}
+			}
+		}
+	}
+	return dups
+}
+
+// SharedKey identifies an observation on a shared checklist regardless of
+// which observer's copy it's on, for matching eBird records to iNaturalist
+// observations synced from another copy (see reconcile.FromINatResult).
+// iNaturalist observations don't have the effort of their checklists,
+// so it's less exact than SharedDuplicates.
+type SharedKey struct {
+	Date, Time          string
+	ScientificName      string
+	Latitude, Longitude string
+}
+
+// SharedKey returns the record's date, checklist start time, scientific
+// name, and coordinates rounded to about 10 meters. The time tells apart
+// different group checklists at the same place on the same day. ok is
+// false if the record isn't from a checklist with more than one observer
+// or is missing its date, time, name, or coordinates.
+func (r Record) SharedKey() (key SharedKey, ok bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(r.NumberOfObservers))
+	if err != nil || n < 2 || r.Date == "" || r.Time == "" || r.ScientificName == "" {
+		return SharedKey{}, false
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(r.Latitude), 64)
+	if err != nil {
+		return SharedKey{}, false
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(r.Longitude), 64)
+	if err != nil {
+		return SharedKey{}, false
+	}
+	return SharedKey{
+		Date:           r.Date,
+		Time:           r.Time,
+		ScientificName: r.ScientificName,
+		Latitude:       strconv.FormatFloat(lat, 'f', 4, 64),
+		Longitude:      strconv.FormatFloat(lng, 'f', 4, 64),
+	}, true
+}
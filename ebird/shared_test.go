@@ -0,0 +1,66 @@
+package ebird
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSharedDuplicates(t *testing.T) {
+	checklist := func(id, observers string, species ...string) []Record {
+		var records []Record
+		for _, name := range species {
+			records = append(records, Record{
+				SubmissionID: id, ScientificName: name, LocationID: "L1", Date: "2024-05-01",
+				Time: "07:00 AM", Protocol: "eBird - Traveling Count", DurationMin: "60",
+				DistanceTraveledKm: "2", NumberOfObservers: observers,
+			})
+		}
+		return records
+	}
+	records := slices.Concat(
+		checklist("S1", "2", "Turdus migratorius", "Cardinalis cardinalis"),
+		checklist("S2", "2", "Turdus migratorius", "Sitta carolinensis"), // S1 shared with another observer
+		checklist("S3", "1", "Turdus migratorius"),                       // a solo checklist at the same time and place
+	)
+	later := checklist("S4", "2", "Turdus migratorius")
+	later[0].Time = "08:00 AM" // a later checklist at the same place
+	records = append(records, later...)
+
+	got := SharedDuplicates(slices.Values(records))
+	want := map[ObservationID]ObservationID{
+		{"S2", "Turdus migratorius"}: {"S1", "Turdus migratorius"},
+	}
+	if len(got) != len(want) || got[ObservationID{"S2", "Turdus migratorius"}] != want[ObservationID{"S2", "Turdus migratorius"}] {
+		t.Errorf("SharedDuplicates() = %v, want %v", got, want)
+	}
+}
+
+func TestSharedKey(t *testing.T) {
+	rec := Record{
+		SubmissionID: "S2", ScientificName: "Turdus migratorius", Date: "2024-05-01", Time: "07:30 AM",
+		Latitude: "38.12345678", Longitude: "-77.5", NumberOfObservers: "3",
+	}
+	synced := Record{
+		SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2024-05-01", Time: "07:30 AM",
+		Latitude: "38.12346", Longitude: "-77.50000", NumberOfObservers: "3",
+	}
+	k1, ok1 := rec.SharedKey()
+	k2, ok2 := synced.SharedKey()
+	if !ok1 || !ok2 || k1 != k2 {
+		t.Errorf("SharedKey() = %+v, %v and %+v, %v; want equal keys", k1, ok1, k2, ok2)
+	}
+	afternoon := synced
+	afternoon.Time = "03:00 PM"
+	if k3, _ := afternoon.SharedKey(); k3 == k1 {
+		t.Errorf("SharedKey() of a later checklist at the same place = %+v, want a different key", k3)
+	}
+	rec.Time = ""
+	if k, ok := rec.SharedKey(); ok {
+		t.Errorf("SharedKey() without a time = %+v, want none", k)
+	}
+	rec.Time = "07:30 AM"
+	rec.NumberOfObservers = "1"
+	if k, ok := rec.SharedKey(); ok {
+		t.Errorf("SharedKey() with one observer = %+v, want none", k)
+	}
+}
@@ -0,0 +1,129 @@
+package ebird
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"time"
+)
+
+// DetectOrder reports whether records are sorted by observation time
+// in ascending (oldest first) or descending (newest first) order.
+// Records with the same time, such as those on the same checklist, and
+// records with unparseable dates are ignored. ok is false if the order
+// can't be determined: the records are unsorted, or they don't include
+// at least two different observation times.
+func DetectOrder(records iter.Seq[Record]) (ascending, ok bool) {
+	var up, down bool
+	var prev int64
+	first := true
+	for rec := range records {
+		observed, err := rec.Observed()
+		if err != nil {
+			continue
+		}
+		t := observed.Unix()
+		if !first {
+			switch {
+			case t > prev:
+				up = true
+			case t < prev:
+				down = true
+			}
+			if up && down {
+				return false, false
+			}
+		}
+		first = false
+		prev = t
+	}
+	return up, up != down
+}
+
+// LatestObserved returns the latest observation time in records,
+// reading them once without buffering them. Records with unparseable
+// dates are ignored; ok is false if no record has a valid date.
+// Use it to choose the --after time for the next incremental sync.
+func LatestObserved(records iter.Seq[Record]) (latest time.Time, ok bool) {
+	for rec := range records {
+		observed, err := rec.Observed()
+		if err != nil {
+			continue
+		}
+		if !ok || observed.After(latest) {
+			latest, ok = observed, true
+		}
+	}
+	return latest, ok
+}
+
+// SortForSync sorts records into the order birdsync syncs them:
+// ascending observation time, then submission ID, then CSV line.
+// Records with unparseable dates sort last.
+//
+// Sorting needs every record in memory, so callers with an iter.Seq
+// must collect it into a slice first.
+func SortForSync(records []Record) {
+	type key struct {
+		rec   Record
+		unix  int64
+		dated bool
+	}
+	keys := make([]key, len(records))
+	for i, rec := range records {
+		observed, err := rec.Observed()
+		keys[i] = key{rec, observed.Unix(), err == nil}
+	}
+	slices.SortStableFunc(keys, func(a, b key) int {
+		if a.dated != b.dated {
+			if a.dated {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(
+			cmp.Compare(a.unix, b.unix),
+			cmp.Compare(a.rec.SubmissionID, b.rec.SubmissionID),
+			cmp.Compare(a.rec.Line, b.rec.Line),
+		)
+	})
+	for i, k := range keys {
+		records[i] = k.rec
+	}
+}
+
+// SortTaxonomic sorts records into eBird taxonomic order, as in a
+// checklist or life list. The order is deterministic:
+//   - records with a taxonomic order (see TaxonomicOrderFloat) come first,
+//     in ascending order;
+//   - records without one come last;
+//   - ties, including among records without an order, are broken by
+//     scientific name and then by CSV line.
+func SortTaxonomic(records []Record) {
+	type key struct {
+		rec     Record
+		order   float64
+		ordered bool
+	}
+	keys := make([]key, len(records))
+	for i, rec := range records {
+		order, ok := rec.TaxonomicOrderFloat()
+		keys[i] = key{rec, order, ok}
+	}
+	slices.SortStableFunc(keys, func(a, b key) int {
+		if a.ordered != b.ordered {
+			if a.ordered {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(
+			cmp.Compare(a.order, b.order),
+			cmp.Compare(a.rec.ScientificName, b.rec.ScientificName),
+			cmp.Compare(a.rec.Line, b.rec.Line),
+		)
+	})
+	for i, k := range keys {
+		records[i] = k.rec
+	}
+}
@@ -0,0 +1,92 @@
+package ebird
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDetectOrder(t *testing.T) {
+	testCases := []struct {
+		name          string
+		dates         []string
+		wantAscending bool
+		wantOK        bool
+	}{
+		{"ascending", []string{"2023-01-01", "2023-01-01", "1/2/2023", "2023-01-03"}, true, true},
+		{"descending", []string{"2023-01-03", "2023-01-02", "2023-01-02", "2023-01-01"}, false, true},
+		{"unsorted", []string{"2023-01-02", "2023-01-01", "2023-01-03"}, false, false},
+		{"one date", []string{"2023-01-01", "2023-01-01"}, false, false},
+		{"empty", nil, false, false},
+		{"ignores bad dates", []string{"2023-01-01", "bad", "2023-01-02"}, true, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var records []Record
+			for _, d := range tc.dates {
+				records = append(records, Record{Date: d})
+			}
+			ascending, ok := DetectOrder(slices.Values(records))
+			if ascending != tc.wantAscending || ok != tc.wantOK {
+				t.Errorf("DetectOrder() = %v, %v; want %v, %v", ascending, ok, tc.wantAscending, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestSortForSync(t *testing.T) {
+	records := []Record{
+		{Line: 2, SubmissionID: "S3", Date: "2023-01-03"},
+		{Line: 3, SubmissionID: "S9", Date: "bad"},
+		{Line: 4, SubmissionID: "S2", Date: "2023-01-02", Time: "08:00 AM"},
+		{Line: 5, SubmissionID: "S1", Date: "1/2/2023", Time: "8:00 AM"},
+		{Line: 6, SubmissionID: "S1", Date: "1/2/2023", Time: "8:00 AM"},
+	}
+	SortForSync(records)
+	var got []int
+	for _, r := range records {
+		got = append(got, r.Line)
+	}
+	if want := []int{5, 6, 4, 2, 3}; !slices.Equal(got, want) {
+		t.Errorf("SortForSync() lines = %v, want %v", got, want)
+	}
+}
+
+func TestLatestObserved(t *testing.T) {
+	records := []Record{
+		{Date: "2023-01-02", Time: "07:00 AM"},
+		{Date: "1/3/2023"},
+		{Date: "bad"},
+		{Date: "2023-01-02", Time: "11:00 PM"},
+	}
+	latest, ok := LatestObserved(slices.Values(records))
+	if want := time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC); !ok || !latest.Equal(want) {
+		t.Errorf("LatestObserved() = %v, %v; want %v, true", latest, ok, want)
+	}
+	if _, ok := LatestObserved(slices.Values([]Record{{Date: "bad"}})); ok {
+		t.Errorf("LatestObserved(undated) ok = true, want false")
+	}
+	if _, ok := LatestObserved(slices.Values([]Record(nil))); ok {
+		t.Errorf("LatestObserved(empty) ok = true, want false")
+	}
+}
+
+func TestSortTaxonomic(t *testing.T) {
+	records := []Record{
+		{Line: 2, ScientificName: "Turdus migratorius", TaxonomicOrder: "27403"},
+		{Line: 3, ScientificName: "Larus sp.", TaxonomicOrder: ""},
+		{Line: 4, ScientificName: "Branta canadensis", TaxonomicOrder: "278.5"},
+		{Line: 5, ScientificName: "Anatidae sp.", TaxonomicOrder: "0"},
+		{Line: 6, ScientificName: "Turdus migratorius", TaxonomicOrder: "27403"},
+		{Line: 7, ScientificName: "Corvus brachyrhynchos", TaxonomicOrder: "21330"},
+		{Line: 8, ScientificName: "Aythya sp.", TaxonomicOrder: "unknown"},
+	}
+	SortTaxonomic(records)
+	var lines []int
+	for _, r := range records {
+		lines = append(lines, r.Line)
+	}
+	if want := []int{4, 7, 2, 6, 5, 8, 3}; !slices.Equal(lines, want) {
+		t.Errorf("SortTaxonomic() lines = %v, want %v", lines, want)
+	}
+}
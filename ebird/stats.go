@@ -0,0 +1,113 @@
+package ebird
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"time"
+)
+
+// CountMLAssets returns the number of distinct Macaulay Library assets
+// referenced by records. It only reads the ML Catalog Numbers column,
+// so it's cheap and doesn't contact the Macaulay Library.
+func CountMLAssets(records iter.Seq[Record]) int {
+	seen := map[string]bool{}
+	for rec := range records {
+		for _, id := range mlAssetIDs(rec.MLCatalogNumbers) {
+			seen[id] = true
+		}
+	}
+	return len(seen)
+}
+
+// CountMedia classifies the distinct Macaulay Library assets referenced by
+// records using kind, which typically calls MLAssetKind. Since MLAssetKind
+// makes network requests for every asset, prefer CountMLAssets when
+// the total is enough. CountMedia stops at the first error from kind.
+func CountMedia(records iter.Seq[Record], kind func(mlAssetID string) (MediaKind, error)) (photos, sounds, videos, unknown int, err error) {
+	seen := map[string]bool{}
+	for rec := range records {
+		for _, id := range mlAssetIDs(rec.MLCatalogNumbers) {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			k, err := kind(id)
+			if err != nil {
+				return photos, sounds, videos, unknown, err
+			}
+			switch k {
+			case Photo:
+				photos++
+			case Sound:
+				sounds++
+			case Video:
+				videos++
+			default:
+				unknown++
+			}
+		}
+	}
+	return photos, sounds, videos, unknown, nil
+}
+
+// TimeOfDayHistogram counts checklists by the hour of the day they started.
+// Each checklist is counted once, no matter how many records it has.
+// Checklists without a start time (such as some incidental and historical
+// checklists) aren't included in the histogram; they're counted in untimed.
+func TimeOfDayHistogram(records iter.Seq[Record]) (hours [24]int, untimed int) {
+	seen := map[string]bool{}
+	for rec := range records {
+		if seen[rec.SubmissionID] {
+			continue
+		}
+		seen[rec.SubmissionID] = true
+		observed, err := rec.Observed()
+		if rec.Time == "" || err != nil {
+			untimed++
+			continue
+		}
+		hours[observed.Hour()]++
+	}
+	return hours, untimed
+}
+
+// CountsByState counts records by their State/Province column,
+// which eBird formats as a region code like "US-CA" or "CR-P".
+// Records without a state or province, which are rare but appear in
+// some old and pelagic checklists, are counted under the empty string.
+// CountsByState reads records once and keeps only the counts.
+func CountsByState(records iter.Seq[Record]) map[string]int {
+	counts := map[string]int{}
+	for rec := range records {
+		counts[rec.StateProvince]++
+	}
+	return counts
+}
+
+// BirdingDays counts the distinct dates on which records were observed
+// and the longest run of consecutive such dates. Dates are the local
+// dates recorded in eBird. Records with missing or malformed dates are
+// skipped. BirdingDays reads records once and keeps only the dates.
+func BirdingDays(records iter.Seq[Record]) (days, longestStreak int) {
+	seen := map[time.Time]bool{}
+	for rec := range records {
+		observed, err := rec.Observed()
+		if err != nil {
+			continue
+		}
+		y, m, d := observed.Date()
+		seen[time.Date(y, m, d, 0, 0, 0, 0, time.UTC)] = true
+	}
+	dates := slices.SortedFunc(maps.Keys(seen), time.Time.Compare)
+	streak := 0
+	for i, date := range dates {
+		if i > 0 && dates[i-1].AddDate(0, 0, 1).Equal(date) {
+			streak++
+		} else {
+			streak = 1
+		}
+		longestStreak = max(longestStreak, streak)
+	}
+	return len(dates), longestStreak
+}
@@ -0,0 +1,95 @@
+package ebird
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+)
+
+var mediaRecords = []Record{
+	{SubmissionID: "S1", MLCatalogNumbers: "100 200"},
+	{SubmissionID: "S1", MLCatalogNumbers: ""},
+	{SubmissionID: "S2", MLCatalogNumbers: "300  200"}, // duplicate asset, extra space
+	{SubmissionID: "S3", MLCatalogNumbers: "400"},
+}
+
+func TestCountMLAssets(t *testing.T) {
+	if got := CountMLAssets(slices.Values(mediaRecords)); got != 4 {
+		t.Errorf("CountMLAssets() = %d, want 4", got)
+	}
+}
+
+func TestCountMedia(t *testing.T) {
+	kinds := map[string]MediaKind{"100": Photo, "300": Sound, "400": Video}
+	kind := func(id string) (MediaKind, error) {
+		return kinds[id], nil
+	}
+	photos, sounds, videos, unknown, err := CountMedia(slices.Values(mediaRecords), kind)
+	if err != nil {
+		t.Fatalf("CountMedia() error = %v", err)
+	}
+	if photos != 1 || sounds != 1 || videos != 1 || unknown != 1 {
+		t.Errorf("CountMedia() = %d photos, %d sounds, %d videos, %d unknown; want 1, 1, 1, 1", photos, sounds, videos, unknown)
+	}
+
+	errKind := func(id string) (MediaKind, error) {
+		return UnknownMedia, errors.New("network down")
+	}
+	if _, _, _, _, err := CountMedia(slices.Values(mediaRecords), errKind); err == nil {
+		t.Error("CountMedia() succeeded, want error")
+	}
+}
+
+func TestTimeOfDayHistogram(t *testing.T) {
+	records := []Record{
+		{SubmissionID: "S1", Date: "2023-01-02", Time: "07:15 AM"},
+		{SubmissionID: "S1", Date: "2023-01-02", Time: "07:15 AM"}, // same checklist
+		{SubmissionID: "S2", Date: "1/3/2023", Time: "7:45 AM"},
+		{SubmissionID: "S3", Date: "2023-01-04", Time: "05:30 PM"},
+		{SubmissionID: "S4", Date: "2023-01-05"}, // no time
+	}
+	hours, untimed := TimeOfDayHistogram(slices.Values(records))
+	var want [24]int
+	want[7] = 2
+	want[17] = 1
+	if hours != want {
+		t.Errorf("TimeOfDayHistogram() hours = %v, want %v", hours, want)
+	}
+	if untimed != 1 {
+		t.Errorf("TimeOfDayHistogram() untimed = %d, want 1", untimed)
+	}
+}
+
+func TestCountsByState(t *testing.T) {
+	records := []Record{
+		{StateProvince: "US-CA"},
+		{StateProvince: "US-CA"},
+		{StateProvince: "CR-P"},
+		{StateProvince: ""},
+	}
+	want := map[string]int{"US-CA": 2, "CR-P": 1, "": 1}
+	if got := CountsByState(slices.Values(records)); !maps.Equal(got, want) {
+		t.Errorf("CountsByState() = %v, want %v", got, want)
+	}
+}
+
+func TestBirdingDays(t *testing.T) {
+	records := []Record{
+		{Date: "2024-02-28", Time: "07:00 AM"},
+		{Date: "2024-02-28", Time: "05:00 PM"},
+		{Date: "2024-02-29"},
+		{Date: "3/1/2024"},
+		{Date: "2024-03-05"},
+		{Date: "2024-03-06"},
+		{Date: ""},        // undated
+		{Date: "03-2024"}, // malformed
+	}
+	days, streak := BirdingDays(slices.Values(records))
+	if days != 5 || streak != 3 {
+		t.Errorf("BirdingDays() = %d, %d; want 5, 3", days, streak)
+	}
+	if days, streak := BirdingDays(slices.Values([]Record{})); days != 0 || streak != 0 {
+		t.Errorf("BirdingDays(no records) = %d, %d; want 0, 0", days, streak)
+	}
+}
@@ -0,0 +1,105 @@
+package ebird
+
+import "strings"
+
+// Taxon is the structure of an eBird scientific name.
+type Taxon struct {
+	Name string // the scientific name, with surrounding space trimmed
+
+	// Category is CategorySpecies, CategorySpuh, CategorySlash,
+	// CategoryHybrid, CategoryDomestic, or CategoryISSF, for subspecies
+	// and other names qualified by a parenthetical or bracketed group.
+	Category string
+
+	// Components are the names of the taxa that make up this one:
+	//   - for a species, domestic, or ISSF, the species, like "Cairina moschata";
+	//   - for a spuh, the higher taxa, like "Melanitta" or "Aythya" and "Anas";
+	//   - for a slash or hybrid, each alternative or parent, with the genus
+	//     filled in when eBird abbreviates it, like "Aythya marila" and "Aythya affinis".
+	Components []string
+
+	// Qualifier is the parenthetical or bracketed text at the end of
+	// the name, if any, like "Domestic type" or "oreganus Group".
+	Qualifier string
+}
+
+// ParseTaxon classifies an eBird scientific name, such as
+// "Aythya marila/affinis" (slash), "Melanitta sp." (spuh),
+// "Anas platyrhynchos x rubripes" (hybrid), or
+// "Cairina moschata (Domestic type)" (domestic).
+// It only looks at the name's form; use a Taxonomy to look it up.
+func ParseTaxon(scientificName string) Taxon {
+	t := Taxon{Name: strings.TrimSpace(scientificName)}
+	base := t.Name
+	for _, brackets := range []string{"()", "[]"} {
+		if strings.HasSuffix(base, brackets[1:]) {
+			if i := strings.LastIndex(base, brackets[:1]); i > 0 {
+				t.Qualifier = strings.TrimSpace(base[i+1 : len(base)-1])
+				base = strings.TrimSpace(base[:i])
+				break
+			}
+		}
+	}
+
+	switch {
+	case strings.HasSuffix(base, " sp."):
+		t.Category = CategorySpuh
+		t.Components = strings.Split(strings.TrimSuffix(base, " sp."), "/")
+	case strings.Contains(base, " x "):
+		t.Category = CategoryHybrid
+		t.Components = withGenus(strings.Split(base, " x "))
+	case strings.Contains(base, "/"):
+		t.Category = CategorySlash
+		t.Components = withGenus(strings.Split(base, "/"))
+	default:
+		t.Components = []string{base}
+		switch q := strings.ToLower(t.Qualifier); {
+		case strings.Contains(q, "domestic") || strings.Contains(q, "feral"):
+			t.Category = CategoryDomestic
+		case t.Qualifier != "" || len(strings.Fields(base)) > 2:
+			t.Category = CategoryISSF
+		default:
+			t.Category = CategorySpecies
+		}
+	}
+	for i, c := range t.Components {
+		t.Components[i] = strings.TrimSpace(c)
+	}
+	return t
+}
+
+// withGenus fills in the genus of abbreviated names in names,
+// like "rubripes" in "Anas platyrhynchos x rubripes",
+// using the genus of the first name.
+func withGenus(names []string) []string {
+	genus, _, ok := strings.Cut(strings.TrimSpace(names[0]), " ")
+	if !ok {
+		return names
+	}
+	for i, n := range names[1:] {
+		n = strings.TrimSpace(n)
+		if n != "" && !strings.Contains(n, " ") && strings.ToLower(n) == n {
+			names[i+1] = genus + " " + n
+		}
+	}
+	return names
+}
+
+// Genus returns the genus of the taxon's first component,
+// or the empty string if it's a spuh above genus, like "Anatidae sp.".
+func (t Taxon) Genus() string {
+	if len(t.Components) == 0 {
+		return ""
+	}
+	first, _, _ := strings.Cut(t.Components[0], " ")
+	if t.Category == CategorySpuh {
+		// Names of families, subfamilies, tribes, and orders
+		// have standard endings.
+		for _, suffix := range []string{"idae", "inae", "ini", "formes"} {
+			if strings.HasSuffix(first, suffix) {
+				return ""
+			}
+		}
+	}
+	return first
+}
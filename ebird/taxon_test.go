@@ -0,0 +1,36 @@
+package ebird
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTaxon(t *testing.T) {
+	testCases := []struct {
+		name       string
+		category   string
+		components []string
+		qualifier  string
+		genus      string
+	}{
+		{"Turdus migratorius", CategorySpecies, []string{"Turdus migratorius"}, "", "Turdus"},
+		{" Aythya marila/affinis ", CategorySlash, []string{"Aythya marila", "Aythya affinis"}, "", "Aythya"},
+		{"Tringa flavipes/Tringa melanoleuca", CategorySlash, []string{"Tringa flavipes", "Tringa melanoleuca"}, "", "Tringa"},
+		{"Melanitta sp.", CategorySpuh, []string{"Melanitta"}, "", "Melanitta"},
+		{"Aythya/Anas sp.", CategorySpuh, []string{"Aythya", "Anas"}, "", "Aythya"},
+		{"Anatidae sp.", CategorySpuh, []string{"Anatidae"}, "", ""},
+		{"Anas platyrhynchos x rubripes", CategoryHybrid, []string{"Anas platyrhynchos", "Anas rubripes"}, "", "Anas"},
+		{"Cairina moschata (Domestic type)", CategoryDomestic, []string{"Cairina moschata"}, "Domestic type", "Cairina"},
+		{"Columba livia (Feral Pigeon)", CategoryDomestic, []string{"Columba livia"}, "Feral Pigeon", "Columba"},
+		{"Junco hyemalis [oreganus Group]", CategoryISSF, []string{"Junco hyemalis"}, "oreganus Group", "Junco"},
+		{"Buteo jamaicensis calurus", CategoryISSF, []string{"Buteo jamaicensis calurus"}, "", "Buteo"},
+	}
+	for _, tc := range testCases {
+		got := ParseTaxon(tc.name)
+		if got.Category != tc.category || !slices.Equal(got.Components, tc.components) ||
+			got.Qualifier != tc.qualifier || got.Genus() != tc.genus {
+			t.Errorf("ParseTaxon(%q) = %+v with genus %q; want %s %q %q with genus %q",
+				tc.name, got, got.Genus(), tc.category, tc.components, tc.qualifier, tc.genus)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package ebird
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Taxon categories in the eBird taxonomy.
+const (
+	CategorySpecies    = "species"
+	CategorySpuh       = "spuh"   // genus or broader, like "Melanitta sp."
+	CategorySlash      = "slash"  // one of two or more taxa, like "Aythya marila/affinis"
+	CategoryHybrid     = "hybrid" // like "Anas platyrhynchos x rubripes"
+	CategoryDomestic   = "domestic"
+	CategoryISSF       = "issf" // identifiable subspecies or group of subspecies
+	CategoryForm       = "form"
+	CategoryIntergrade = "intergrade"
+)
+
+// TaxonomyEntry is a taxon in the eBird taxonomy.
+type TaxonomyEntry struct {
+	ScientificName string
+	CommonName     string
+	SpeciesCode    string // like "amerob"
+	Category       string // see CategorySpecies and friends
+	TaxonomicOrder float64
+	Order          string // taxonomic order, like "Passeriformes"
+	Family         string // scientific name, like "Turdidae"
+	ReportAs       string // species code of the taxon this is reported as, if any
+}
+
+// Taxonomy is an index of the eBird taxonomy.
+type Taxonomy struct {
+	entries      []TaxonomyEntry // in the order read, which for eBird's file is taxonomic
+	byScientific map[string]int
+	byCommon     map[string]int
+	byCode       map[string]int
+	byOrder      map[float64]int
+}
+
+// ReadTaxonomy reads the eBird taxonomy from r, in the CSV format of
+// https://api.ebird.org/v2/ref/taxonomy/ebird?fmt=csv.
+func ReadTaxonomy(r io.Reader) (*Taxonomy, error) {
+	cr := newCSVReader(r)
+	header, field, err := readHeader("taxonomy", cr)
+	if err != nil {
+		return nil, err
+	}
+	for _, col := range []string{"SCIENTIFIC_NAME", "COMMON_NAME", "SPECIES_CODE", "CATEGORY", "TAXON_ORDER"} {
+		if _, ok := field[col]; !ok {
+			return nil, fmt.Errorf("ReadTaxonomy: no %s column in header %q", col, header)
+		}
+	}
+	t := &Taxonomy{
+		byScientific: map[string]int{},
+		byCommon:     map[string]int{},
+		byCode:       map[string]int{},
+		byOrder:      map[float64]int{},
+	}
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ReadTaxonomy: %w", err)
+		}
+		col := func(name string) string {
+			if i, ok := field[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		order, err := strconv.ParseFloat(col("TAXON_ORDER"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("ReadTaxonomy: line %d: bad TAXON_ORDER: %w", line, err)
+		}
+		e := TaxonomyEntry{
+			ScientificName: col("SCIENTIFIC_NAME"),
+			CommonName:     col("COMMON_NAME"),
+			SpeciesCode:    col("SPECIES_CODE"),
+			Category:       col("CATEGORY"),
+			TaxonomicOrder: order,
+			Order:          col("ORDER"),
+			Family:         col("FAMILY_SCI_NAME"),
+			ReportAs:       col("REPORT_AS"),
+		}
+		i := len(t.entries)
+		t.entries = append(t.entries, e)
+		t.byScientific[strings.ToLower(e.ScientificName)] = i
+		t.byCommon[strings.ToLower(e.CommonName)] = i
+		t.byCode[e.SpeciesCode] = i
+		t.byOrder[e.TaxonomicOrder] = i
+	}
+	return t, nil
+}
+
+// Taxonomy downloads the current eBird taxonomy.
+// It's a few megabytes, so callers should save it (see ReadTaxonomy)
+// rather than download it every time.
+func (c *APIClient) Taxonomy(ctx context.Context) (*Taxonomy, error) {
+	q := url.Values{}
+	q.Set("fmt", "csv")
+	b, err := c.fetch(ctx, "/ref/taxonomy/ebird", q)
+	if err != nil {
+		return nil, fmt.Errorf("Taxonomy: %w", err)
+	}
+	return ReadTaxonomy(bytes.NewReader(b))
+}
+
+func (t *Taxonomy) lookup(index map[string]int, key string) (TaxonomyEntry, bool) {
+	i, ok := index[key]
+	if !ok {
+		return TaxonomyEntry{}, false
+	}
+	return t.entries[i], true
+}
+
+// ByScientificName returns the taxon with the provided scientific name,
+// ignoring case.
+func (t *Taxonomy) ByScientificName(name string) (TaxonomyEntry, bool) {
+	return t.lookup(t.byScientific, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// ByCommonName returns the taxon with the provided English common name,
+// ignoring case.
+func (t *Taxonomy) ByCommonName(name string) (TaxonomyEntry, bool) {
+	return t.lookup(t.byCommon, strings.ToLower(strings.TrimSpace(name)))
+}
+
+// BySpeciesCode returns the taxon with the provided species code, like "amerob".
+func (t *Taxonomy) BySpeciesCode(code string) (TaxonomyEntry, bool) {
+	return t.lookup(t.byCode, strings.TrimSpace(code))
+}
+
+// ByTaxonomicOrder returns the taxon at the provided position in the taxonomy.
+func (t *Taxonomy) ByTaxonomicOrder(order float64) (TaxonomyEntry, bool) {
+	i, ok := t.byOrder[order]
+	if !ok {
+		return TaxonomyEntry{}, false
+	}
+	return t.entries[i], true
+}
+
+// ByCategory returns the taxa in the provided category, such as
+// CategorySpuh, in the order they were read.
+func (t *Taxonomy) ByCategory(category string) []TaxonomyEntry {
+	var entries []TaxonomyEntry
+	for _, e := range t.entries {
+		if e.Category == category {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Len returns the number of taxa in the taxonomy.
+func (t *Taxonomy) Len() int {
+	return len(t.entries)
+}
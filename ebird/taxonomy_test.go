@@ -0,0 +1,75 @@
+package ebird
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func readTestTaxonomy(t *testing.T) *Taxonomy {
+	t.Helper()
+	f, err := os.Open("testdata/taxonomy.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tax, err := ReadTaxonomy(f)
+	if err != nil {
+		t.Fatalf("ReadTaxonomy() error: %v", err)
+	}
+	return tax
+}
+
+func TestTaxonomy(t *testing.T) {
+	tax := readTestTaxonomy(t)
+	if tax.Len() != 6 {
+		t.Errorf("Len() = %d, want 6", tax.Len())
+	}
+	if e, ok := tax.ByScientificName("turdus MIGRATORIUS"); !ok || e.CommonName != "American Robin" || e.Family != "Turdidae" {
+		t.Errorf("ByScientificName(Turdus migratorius) = %+v, %v", e, ok)
+	}
+	if e, ok := tax.ByCommonName("Greater/Lesser Scaup"); !ok || e.Category != CategorySlash {
+		t.Errorf("ByCommonName(Greater/Lesser Scaup) = %+v, %v", e, ok)
+	}
+	if e, ok := tax.BySpeciesCode("musduc3"); !ok || e.Category != CategoryDomestic || e.ReportAs != "musduc" {
+		t.Errorf("BySpeciesCode(musduc3) = %+v, %v", e, ok)
+	}
+	if e, ok := tax.ByTaxonomicOrder(583); !ok || e.ScientificName != "Melanitta sp." {
+		t.Errorf("ByTaxonomicOrder(583) = %+v, %v", e, ok)
+	}
+	if _, ok := tax.BySpeciesCode("nosuch"); ok {
+		t.Errorf("BySpeciesCode(nosuch) found a taxon")
+	}
+	if got := tax.ByCategory(CategorySpecies); len(got) != 2 || got[0].SpeciesCode != "musduc" {
+		t.Errorf("ByCategory(species) = %+v, want Muscovy Duck and American Robin", got)
+	}
+
+	if _, err := ReadTaxonomy(strings.NewReader("SCIENTIFIC_NAME,COMMON_NAME\n")); err == nil {
+		t.Errorf("ReadTaxonomy() without required columns succeeded, want an error")
+	}
+}
+
+func TestAPIClient_Taxonomy(t *testing.T) {
+	data, err := os.ReadFile("testdata/taxonomy.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ref/taxonomy/ebird" || r.URL.Query().Get("fmt") != "csv" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	tax, err := NewAPIClient(server.URL, "key", "test").Taxonomy(context.Background())
+	if err != nil {
+		t.Fatalf("Taxonomy() error: %v", err)
+	}
+	if _, ok := tax.BySpeciesCode("amerob"); !ok {
+		t.Errorf("Downloaded taxonomy has no amerob")
+	}
+}
@@ -0,0 +1,143 @@
+package ebird
+
+import (
+	"strings"
+	"time"
+)
+
+// regionTimeZones maps eBird region codes to IANA time zone names,
+// for regions that lie entirely in one time zone. Regions split between
+// zones, such as US-TX, CA-ON, and AU, are deliberately missing: for
+// them, only the exact location could tell, and a wrong zone is worse
+// than none.
+var regionTimeZones = map[string]string{
+	// United States
+	"US-AL": "America/Chicago",
+	"US-AR": "America/Chicago",
+	"US-CA": "America/Los_Angeles",
+	"US-CO": "America/Denver",
+	"US-CT": "America/New_York",
+	"US-DC": "America/New_York",
+	"US-DE": "America/New_York",
+	"US-GA": "America/New_York",
+	"US-HI": "Pacific/Honolulu",
+	"US-IA": "America/Chicago",
+	"US-IL": "America/Chicago",
+	"US-LA": "America/Chicago",
+	"US-MA": "America/New_York",
+	"US-MD": "America/New_York",
+	"US-ME": "America/New_York",
+	"US-MN": "America/Chicago",
+	"US-MO": "America/Chicago",
+	"US-MS": "America/Chicago",
+	"US-MT": "America/Denver",
+	"US-NC": "America/New_York",
+	"US-NH": "America/New_York",
+	"US-NJ": "America/New_York",
+	"US-NM": "America/Denver",
+	"US-NY": "America/New_York",
+	"US-OH": "America/New_York",
+	"US-OK": "America/Chicago",
+	"US-PA": "America/New_York",
+	"US-RI": "America/New_York",
+	"US-SC": "America/New_York",
+	"US-UT": "America/Denver",
+	"US-VA": "America/New_York",
+	"US-VT": "America/New_York",
+	"US-WA": "America/Los_Angeles",
+	"US-WI": "America/Chicago",
+	"US-WV": "America/New_York",
+	"US-WY": "America/Denver",
+
+	// Canada
+	"CA-AB": "America/Edmonton",
+	"CA-MB": "America/Winnipeg",
+	"CA-NB": "America/Moncton",
+	"CA-NS": "America/Halifax",
+	"CA-PE": "America/Halifax",
+	"CA-NT": "America/Yellowknife",
+	"CA-YT": "America/Whitehorse",
+
+	// Countries
+	"AR": "America/Argentina/Buenos_Aires",
+	"AT": "Europe/Vienna",
+	"BE": "Europe/Brussels",
+	"BZ": "America/Belize",
+	"CH": "Europe/Zurich",
+	"CN": "Asia/Shanghai",
+	"CO": "America/Bogota",
+	"CR": "America/Costa_Rica",
+	"CU": "America/Havana",
+	"CZ": "Europe/Prague",
+	"DE": "Europe/Berlin",
+	"DK": "Europe/Copenhagen",
+	"DO": "America/Santo_Domingo",
+	"FI": "Europe/Helsinki",
+	"FR": "Europe/Paris",
+	"GB": "Europe/London",
+	"GR": "Europe/Athens",
+	"GT": "America/Guatemala",
+	"HN": "America/Tegucigalpa",
+	"IE": "Europe/Dublin",
+	"IL": "Asia/Jerusalem",
+	"IN": "Asia/Kolkata",
+	"IS": "Atlantic/Reykjavik",
+	"IT": "Europe/Rome",
+	"JM": "America/Jamaica",
+	"JP": "Asia/Tokyo",
+	"KE": "Africa/Nairobi",
+	"KR": "Asia/Seoul",
+	"NI": "America/Managua",
+	"NL": "Europe/Amsterdam",
+	"NO": "Europe/Oslo",
+	"PA": "America/Panama",
+	"PE": "America/Lima",
+	"PH": "Asia/Manila",
+	"PL": "Europe/Warsaw",
+	"PR": "America/Puerto_Rico",
+	"SE": "Europe/Stockholm",
+	"SG": "Asia/Singapore",
+	"SV": "America/El_Salvador",
+	"TH": "Asia/Bangkok",
+	"TT": "America/Port_of_Spain",
+	"TW": "Asia/Taipei",
+	"TZ": "Africa/Dar_es_Salaam",
+	"UY": "America/Montevideo",
+	"VE": "America/Caracas",
+	"VN": "Asia/Ho_Chi_Minh",
+	"ZA": "Africa/Johannesburg",
+}
+
+// RegionTimeZone returns the time zone of the record's State/Province
+// region code, or of its country. It doesn't look at the record's
+// coordinates, so ok is false if the region is unknown or spans several
+// time zones, or if the zone can't be loaded (for example, if the system
+// has no time zone database).
+func (r Record) RegionTimeZone() (loc *time.Location, ok bool) {
+	region := strings.ToUpper(strings.TrimSpace(r.StateProvince))
+	name, ok := regionTimeZones[region]
+	if !ok {
+		country, _, _ := strings.Cut(region, "-")
+		name, ok = regionTimeZones[country]
+	}
+	if !ok {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	return loc, true
+}
+
+// ObservedInRegion is like Observed but returns the time in the record's
+// region's time zone, if RegionTimeZone knows it, so times on checklists
+// from different zones can be compared. Otherwise, it's the same as
+// Observed, whose result is the local date and time labeled UTC.
+func (r Record) ObservedInRegion() (time.Time, error) {
+	loc, ok := r.RegionTimeZone()
+	if !ok {
+		loc = time.UTC
+	}
+	return r.ObservedIn(loc)
+}
@@ -0,0 +1,56 @@
+package ebird
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecord_RegionTimeZone(t *testing.T) {
+	testCases := []struct {
+		region string
+		want   string // "" means unknown
+	}{
+		{"US-VA", "America/New_York"},
+		{"US-CA", "America/Los_Angeles"},
+		{"us-ca", "America/Los_Angeles"},
+		{"US-TX", ""}, // several zones
+		{"CR-P", "America/Costa_Rica"},
+		{"GB", "Europe/London"},
+		{"CA-ON", ""},
+		{"AU-NSW", ""},
+		{"", ""},
+	}
+	for _, tc := range testCases {
+		loc, ok := Record{StateProvince: tc.region}.RegionTimeZone()
+		got := ""
+		if ok {
+			got = loc.String()
+		}
+		if got != tc.want {
+			t.Errorf("RegionTimeZone(%q) = %q, want %q", tc.region, got, tc.want)
+		}
+	}
+}
+
+func TestRecord_ObservedInRegion(t *testing.T) {
+	va := Record{StateProvince: "US-VA", Date: "2024-06-01", Time: "07:00 AM"}
+	ca := Record{StateProvince: "US-CA", Date: "2024-06-01", Time: "07:00 AM"}
+	vaTime, err := va.ObservedInRegion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTime, err := ca.ObservedInRegion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := caTime.Sub(vaTime); d != 3*time.Hour {
+		t.Errorf("7 AM in California is %v after 7 AM in Virginia, want 3h", d)
+	}
+
+	unknown := Record{StateProvince: "US-TX", Date: "2024-06-01", Time: "07:00 AM"}
+	got, err := unknown.ObservedInRegion()
+	want, _ := unknown.Observed()
+	if err != nil || !got.Equal(want) {
+		t.Errorf("ObservedInRegion() in US-TX = %v, %v; want Observed() = %v", got, err, want)
+	}
+}
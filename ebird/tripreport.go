@@ -0,0 +1,96 @@
+package ebird
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TripReportBaseURL is the base URL of the eBird web service that lists
+// the checklists in a trip report. It isn't part of the eBird API 2.0,
+// which has no trip reports; it's the undocumented service behind
+// eBird's trip report pages. TripReportChecklists sends it the API key
+// like other requests, but it's untested whether the service accepts
+// or needs the key, and eBird may change or remove the service.
+var TripReportBaseURL = "https://ebird.org/tripreport-internal/v1"
+
+// ParseTripReportID returns the ID of the eBird trip report at the
+// provided URL, like "123456" for https://ebird.org/tripreport/123456.
+// s may also be just the ID.
+func ParseTripReportID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	id := s
+	if strings.Contains(s, "/") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return "", fmt.Errorf("ParseTripReportID(%q): %w", s, err)
+		}
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		i := 0
+		for i < len(parts) && parts[i] != "tripreport" {
+			i++
+		}
+		if i+1 >= len(parts) {
+			return "", fmt.Errorf("ParseTripReportID(%q): not an eBird trip report URL", s)
+		}
+		id = parts[i+1]
+	}
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return "", fmt.Errorf("ParseTripReportID(%q): bad trip report ID %q", s, id)
+	}
+	return id, nil
+}
+
+// TripReportChecklists returns the checklists in the eBird trip report
+// with the provided URL or ID (see ParseTripReportID), in the order
+// eBird lists them.
+func (c *APIClient) TripReportChecklists(ctx context.Context, tripReport string) ([]ChecklistSummary, error) {
+	id, err := ParseTripReportID(tripReport)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.fetchURL(ctx, TripReportBaseURL+"/checklists/"+id)
+	if err != nil {
+		return nil, fmt.Errorf("TripReportChecklists(%s): %w", id, err)
+	}
+	var lists []ChecklistSummary
+	if err := json.Unmarshal(b, &lists); err != nil {
+		return nil, fmt.Errorf("TripReportChecklists(%s): %w", id, err)
+	}
+	return lists, nil
+}
+
+// TripReportRecords returns the records on the checklists in the eBird
+// trip report with the provided URL or ID, so that one trip can be synced
+// without an export. They have the location from the list of checklists,
+// when it has one, and, like the records of Checklist, no Macaulay
+// Library catalog numbers. If a request fails, the iteration yields the
+// error and stops.
+func (c *APIClient) TripReportRecords(ctx context.Context, tripReport string) iter.Seq2[Record, error] {
+	return func(yield func(Record, error) bool) {
+		lists, err := c.TripReportChecklists(ctx, tripReport)
+		if err != nil {
+			yield(Record{}, err)
+			return
+		}
+		for _, l := range lists {
+			records, err := c.Checklist(ctx, l.SubmissionID)
+			if err != nil {
+				yield(Record{}, err)
+				return
+			}
+			for _, rec := range records {
+				if l.Loc.LocationID != "" {
+					l.setLocation(&rec)
+				}
+				if !yield(rec, nil) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package ebird
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTripReportID(t *testing.T) {
+	for _, tc := range []struct {
+		s, want string
+		wantErr bool
+	}{
+		{"https://ebird.org/tripreport/123456", "123456", false},
+		{"https://ebird.org/tripreport/123456/", "123456", false},
+		{"https://ebird.org/tripreport/123456?view=checklists", "123456", false},
+		{"https://ebird.org/region/US/tripreport/123456", "123456", false},
+		{" 123456 ", "123456", false},
+		{"https://ebird.org/checklist/S123", "", true},
+		{"https://ebird.org/tripreport/", "", true},
+		{"S123", "", true},
+	} {
+		got, err := ParseTripReportID(tc.s)
+		if got != tc.want || (err != nil) != tc.wantErr {
+			t.Errorf("ParseTripReportID(%q) = %q, %v; want %q, error %v", tc.s, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestTripReportRecords(t *testing.T) {
+	taxonomyRequests := 0
+	server := newTestAPIServer(t, &taxonomyRequests)
+	defer func(u string) { TripReportBaseURL = u }(TripReportBaseURL)
+	TripReportBaseURL = server.URL
+	client := NewAPIClient(server.URL, "key", "test")
+
+	var got []Record
+	for rec, err := range client.TripReportRecords(context.Background(), "https://ebird.org/tripreport/77") {
+		if err != nil {
+			t.Fatalf("TripReportRecords() error: %v", err)
+		}
+		got = append(got, rec)
+	}
+	if len(got) != 2 {
+		t.Fatalf("TripReportRecords() returned %d records, want 2", len(got))
+	}
+	for _, rec := range got {
+		if rec.SubmissionID != "S1" || rec.Location != "Some Park" || rec.County != "Santa Clara" || rec.Latitude != "37.5" {
+			t.Errorf("TripReportRecords() record = %+v, want S1 at Some Park", rec)
+		}
+	}
+
+	for _, err := range client.TripReportRecords(context.Background(), "https://ebird.org/tripreport/78") {
+		if err == nil {
+			t.Error("TripReportRecords() of a missing trip report succeeded, want an error")
+		}
+	}
+}
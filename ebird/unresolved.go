@@ -0,0 +1,52 @@
+package ebird
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteUnresolved writes a report of eBird scientific names that
+// couldn't be resolved to iNaturalist taxa. The format is one of:
+//   - "csv": a header line "scientific_name" followed by one name per line;
+//   - "json": an array of objects like {"scientific_name": "Aythya sp."};
+//   - "txt": one name per line.
+//
+// These column and field names are stable, so other tools can rely on them.
+func WriteUnresolved(w io.Writer, format string, names []string) error {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"scientific_name"})
+		for _, name := range names {
+			cw.Write([]string{name})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("WriteUnresolved: %w", err)
+		}
+	case "json":
+		type unresolved struct {
+			ScientificName string `json:"scientific_name"`
+		}
+		list := []unresolved{}
+		for _, name := range names {
+			list = append(list, unresolved{name})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(list); err != nil {
+			return fmt.Errorf("WriteUnresolved: %w", err)
+		}
+	case "txt":
+		for _, name := range names {
+			if _, err := fmt.Fprintln(w, name); err != nil {
+				return fmt.Errorf("WriteUnresolved: %w", err)
+			}
+		}
+	default:
+		return fmt.Errorf("WriteUnresolved: unsupported format %q (want csv, json, or txt)", format)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package ebird
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteUnresolved(t *testing.T) {
+	names := []string{"Aythya marila/affinis", "Anas platyrhynchos x rubripes"}
+	testCases := []struct {
+		format string
+		want   string
+	}{
+		{"csv", "scientific_name\nAythya marila/affinis\nAnas platyrhynchos x rubripes\n"},
+		{"json", `[
+  {
+    "scientific_name": "Aythya marila/affinis"
+  },
+  {
+    "scientific_name": "Anas platyrhynchos x rubripes"
+  }
+]
+`},
+		{"txt", "Aythya marila/affinis\nAnas platyrhynchos x rubripes\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteUnresolved(&buf, tc.format, names); err != nil {
+				t.Fatalf("WriteUnresolved() error = %v", err)
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("WriteUnresolved() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := WriteUnresolved(&buf, "xml", names); err == nil {
+		t.Error("WriteUnresolved(xml) succeeded, want error")
+	}
+	var empty bytes.Buffer
+	if err := WriteUnresolved(&empty, "json", nil); err != nil || empty.String() != "[]\n" {
+		t.Errorf("WriteUnresolved(json, nil) = %q, %v; want empty array", empty.String(), err)
+	}
+}
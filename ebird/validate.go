@@ -0,0 +1,139 @@
+package ebird
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// Issue is a problem with a record found by Validate.
+type Issue struct {
+	Line         int    // line in the CSV file
+	SubmissionID string // the record's checklist, if it has one
+	Column       string // the export column, like "Latitude"
+	Value        string // the column's value, if it's the problem
+	Problem      string // like "beyond ±90"
+}
+
+func (i Issue) String() string {
+	s := fmt.Sprintf("line %d", i.Line)
+	if i.SubmissionID != "" {
+		s += " (" + i.SubmissionID + ")"
+	}
+	if i.Value != "" {
+		return fmt.Sprintf("%s: %s %q: %s", s, i.Column, i.Value, i.Problem)
+	}
+	return fmt.Sprintf("%s: %s: %s", s, i.Column, i.Problem)
+}
+
+// Validate checks a record for problems that would keep it from syncing
+// correctly: a missing submission ID or scientific name, a missing or
+// unparseable date or one outside PlausibleDate's range, bad coordinates
+// (see SuspiciousCoordinates), impossible counts such as zero or negative
+// ones, an unknown Exotic Code, and malformed numbers in the effort
+// columns (see Parse).
+// It returns nil if the record has no problems.
+func Validate(r Record) []Issue {
+	var issues []Issue
+	add := func(column, value, problem string) {
+		issues = append(issues, Issue{r.Line, r.SubmissionID, column, value, problem})
+	}
+	if strings.TrimSpace(r.SubmissionID) == "" {
+		add("Submission ID", "", "missing")
+	} else if id := CanonicalSubmissionID(r.SubmissionID); !strings.HasPrefix(id, "S") || id != r.SubmissionID {
+		add("Submission ID", r.SubmissionID, "not like S123456789")
+	}
+	if strings.TrimSpace(r.ScientificName) == "" {
+		add("Scientific Name", "", "missing")
+	}
+	switch _, err := r.Observed(); {
+	case strings.TrimSpace(r.Date) == "":
+		add("Date", "", "missing")
+	case err != nil:
+		add("Date", strings.TrimSpace(r.Date+" "+r.Time), "unparseable")
+	case !r.PlausibleDate():
+		add("Date", r.Date, "before 1800 or in the future")
+	}
+
+	if _, ok := ParseExoticCategory(r.ExoticCode); !ok {
+		add("Exotic Code", r.ExoticCode, "not N, P, or X")
+	}
+
+	p, errs := r.parse()
+	for _, e := range errs {
+		add(e.column, e.value, e.err.Error())
+	}
+	if p.HasCoordinates && p.Latitude == 0 && p.Longitude == 0 {
+		add("Latitude", r.Latitude+","+r.Longitude, "(0, 0) is almost always a missing location")
+	}
+	if !p.Present && p.Count == 0 && !slices.ContainsFunc(errs, func(e fieldError) bool { return e.column == "Count" }) {
+		if strings.TrimSpace(r.Count) == "" {
+			add("Count", "", "missing")
+		} else {
+			add("Count", r.Count, "eBird counts are at least 1, or X")
+		}
+	}
+	return issues
+}
+
+// ValidationSummary is the result of validating an export with ValidateRecords.
+type ValidationSummary struct {
+	Records int     // number of records checked
+	Issues  []Issue // in the order of the records
+}
+
+// ValidateRecords validates every record and summarizes the issues,
+// for checking an entire export before syncing it.
+func ValidateRecords(records iter.Seq[Record]) ValidationSummary {
+	var s ValidationSummary
+	for rec := range records {
+		s.Records++
+		s.Issues = append(s.Issues, Validate(rec)...)
+	}
+	return s
+}
+
+// ByColumn counts the issues by column.
+func (s ValidationSummary) ByColumn() map[string]int {
+	counts := map[string]int{}
+	for _, i := range s.Issues {
+		counts[i.Column]++
+	}
+	return counts
+}
+
+// String summarizes the issues: their counts by column, followed by
+// the first 20 issues.
+func (s ValidationSummary) String() string {
+	const maxIssues = 20
+	lines := map[int]bool{}
+	for _, i := range s.Issues {
+		lines[i.Line] = true
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Checked %d eBird records: %d issues in %d records\n", s.Records, len(s.Issues), len(lines))
+	type columnCount struct {
+		column string
+		count  int
+	}
+	var counts []columnCount
+	for column, count := range s.ByColumn() {
+		counts = append(counts, columnCount{column, count})
+	}
+	slices.SortFunc(counts, func(a, b columnCount) int {
+		return cmp.Or(b.count-a.count, strings.Compare(a.column, b.column))
+	})
+	for _, c := range counts {
+		fmt.Fprintf(&b, "  %s: %d\n", c.column, c.count)
+	}
+	for n, i := range s.Issues {
+		if n == maxIssues {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(s.Issues)-maxIssues)
+			break
+		}
+		fmt.Fprintf(&b, "  %s\n", i)
+	}
+	return b.String()
+}
@@ -0,0 +1,79 @@
+package ebird
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	good := Record{
+		Line: 2, SubmissionID: "S100", ScientificName: "Turdus migratorius", Count: "2",
+		Date: "2023-01-02", Time: "07:00 AM", Latitude: "37.1", Longitude: "-122.1",
+	}
+	if issues := Validate(good); issues != nil {
+		t.Errorf("Validate(good) = %v, want nil", issues)
+	}
+	present := good
+	present.Count = "X"
+	if issues := Validate(present); issues != nil {
+		t.Errorf("Validate(count X) = %v, want nil", issues)
+	}
+
+	testCases := []struct {
+		name   string
+		edit   func(*Record)
+		column string
+	}{
+		{"missing submission ID", func(r *Record) { r.SubmissionID = "" }, "Submission ID"},
+		{"malformed submission ID", func(r *Record) { r.SubmissionID = "checklist 1" }, "Submission ID"},
+		{"missing scientific name", func(r *Record) { r.ScientificName = " " }, "Scientific Name"},
+		{"missing date", func(r *Record) { r.Date = "" }, "Date"},
+		{"unparseable date", func(r *Record) { r.Date = "Jan 2, 2023" }, "Date"},
+		{"implausible date", func(r *Record) { r.Date = "0019-01-02" }, "Date"},
+		{"latitude out of range", func(r *Record) { r.Latitude = "95" }, "Latitude"},
+		{"missing longitude", func(r *Record) { r.Longitude = "" }, "Longitude"},
+		{"null island", func(r *Record) { r.Latitude, r.Longitude = "0", "0" }, "Latitude"},
+		{"zero count", func(r *Record) { r.Count = "0" }, "Count"},
+		{"negative count", func(r *Record) { r.Count = "-3" }, "Count"},
+		{"fractional count", func(r *Record) { r.Count = "1.5" }, "Count"},
+		{"missing count", func(r *Record) { r.Count = "" }, "Count"},
+		{"bad duration", func(r *Record) { r.DurationMin = "an hour" }, "Duration (Min)"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := good
+			tc.edit(&r)
+			issues := Validate(r)
+			if len(issues) != 1 || issues[0].Column != tc.column || issues[0].Line != 2 {
+				t.Errorf("Validate() = %v, want one issue in %s on line 2", issues, tc.column)
+			}
+		})
+	}
+}
+
+func TestValidateRecords(t *testing.T) {
+	records := []Record{
+		{Line: 2, SubmissionID: "S1", ScientificName: "Turdus migratorius", Count: "1", Date: "2023-01-02"},
+		{Line: 3, SubmissionID: "S1", ScientificName: "Cardinalis cardinalis", Count: "0", Date: "2023-01-02"},
+		{Line: 4, ScientificName: "Cardinalis cardinalis", Count: "-1", Date: "2023-01-02"},
+	}
+	s := ValidateRecords(slices.Values(records))
+	if s.Records != 3 || len(s.Issues) != 3 {
+		t.Fatalf("ValidateRecords() = %+v, want 3 records and 3 issues", s)
+	}
+	if got := s.ByColumn(); got["Count"] != 2 || got["Submission ID"] != 1 {
+		t.Errorf("ByColumn() = %v, want 2 Count and 1 Submission ID", got)
+	}
+	got := s.String()
+	for _, want := range []string{
+		"Checked 3 eBird records: 3 issues in 2 records\n",
+		"  Count: 2\n  Submission ID: 1\n",
+		`line 3 (S1): Count "0": eBird counts are at least 1, or X`,
+		"line 4: Submission ID: missing",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() = %q, want it to contain %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package ebird
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+)
+
+// exportHeader is the header of MyEBirdData.csv, in eBird's column order.
+var exportHeader = []string{
+	"Submission ID",
+	"Common Name",
+	"Scientific Name",
+	"Taxonomic Order",
+	"Count",
+	"State/Province",
+	"County",
+	"Location ID",
+	"Location",
+	"Latitude",
+	"Longitude",
+	"Date",
+	"Time",
+	"Protocol",
+	"Duration (Min)",
+	"All Obs Reported",
+	"Distance Traveled (km)",
+	"Area Covered (ha)",
+	"Number of Observers",
+	"Breeding Code",
+	"Observation Details",
+	"Checklist Comments",
+	"ML Catalog Numbers",
+}
+
+// optionalColumns are the columns of newer exports that older ones lack.
+// Records reads them if they're there, and WriteRecords writes them after
+// exportHeader.
+var optionalColumns = []string{
+	"Exotic Code",
+}
+
+// row returns the record's fields in the order of exportHeader
+// followed by optionalColumns.
+func (r Record) row() []string {
+	return []string{
+		r.SubmissionID,
+		r.CommonName,
+		r.ScientificName,
+		r.TaxonomicOrder,
+		r.Count,
+		r.StateProvince,
+		r.County,
+		r.LocationID,
+		r.Location,
+		r.Latitude,
+		r.Longitude,
+		r.Date,
+		r.Time,
+		r.Protocol,
+		r.DurationMin,
+		r.AllObsReported,
+		r.DistanceTraveledKm,
+		r.AreaCoveredHa,
+		r.NumberOfObservers,
+		r.BreedingCode,
+		r.ObservationDetails,
+		r.ChecklistComments,
+		r.MLCatalogNumbers,
+		r.ExoticCode,
+	}
+}
+
+// WriteRecords writes records to w as CSV in the format of MyEBirdData.csv,
+// with a header, so that Records can read them back. Use it to save a
+// filtered, split, or anonymized export, or to make test fixtures;
+// for a slice of records, pass slices.Values(records).
+// Record.Line isn't written: records read back are numbered by their
+// new position.
+func WriteRecords(w io.Writer, records iter.Seq[Record]) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(slices.Concat(exportHeader, optionalColumns)); err != nil {
+		return fmt.Errorf("WriteRecords: %w", err)
+	}
+	for rec := range records {
+		if err := cw.Write(rec.row()); err != nil {
+			return fmt.Errorf("WriteRecords: line %d: %w", rec.Line, err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("WriteRecords: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package ebird
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWriteRecords(t *testing.T) {
+	records := []Record{
+		{
+			Line: 10, SubmissionID: "S100", CommonName: "American Robin", ScientificName: "Turdus migratorius",
+			TaxonomicOrder: "23000", Count: "X", StateProvince: "US-CA", County: "Santa Clara",
+			LocationID: "L123", Location: "Some Park, \"North\" Lot", Latitude: "37.123", Longitude: "-122.123",
+			Date: "2023-01-02", Time: "03:04 PM", Protocol: "eBird - Stationary Count", DurationMin: "30",
+			AllObsReported: "1", NumberOfObservers: "2", BreedingCode: "NY Nest with Young",
+			ObservationDetails: "singing", ChecklistComments: "windy,\ncold", MLCatalogNumbers: "ML100 ML200", ExoticCode: "X",
+		},
+		{Line: 20, SubmissionID: "S101", CommonName: "Northern Cardinal", ScientificName: "Cardinalis cardinalis", Count: "2"},
+	}
+	var b bytes.Buffer
+	if err := WriteRecords(&b, slices.Values(records)); err != nil {
+		t.Fatalf("WriteRecords() error: %v", err)
+	}
+	if header, _, _ := strings.Cut(b.String(), "\n"); header != strings.Join(exportHeader, ",")+",Exotic Code" {
+		t.Errorf("WriteRecords() header = %q", header)
+	}
+
+	seq, err := RecordsFromReader(context.Background(), &b)
+	if err != nil {
+		t.Fatalf("RecordsFromReader() error: %v", err)
+	}
+	var got []Record
+	for rec, err := range seq {
+		if err != nil {
+			t.Fatalf("RecordsFromReader() iteration error: %v", err)
+		}
+		got = append(got, rec)
+	}
+	// Lines are renumbered after the header.
+	records[0].Line, records[1].Line = 2, 3
+	if !slices.Equal(got, records) {
+		t.Errorf("Read back %+v, want %+v", got, records)
+	}
+}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"iter"
+	"log"
+	"slices"
 	"time"
 
 	"github.com/Sajmani/birdsync/ebird"
 	"github.com/Sajmani/birdsync/inat"
+	"github.com/google/uuid"
 )
 
 // dateTimeFlag validates a command line flag containing a date or a date & time.
@@ -33,30 +37,128 @@ func (f *dateTimeFlag) Time() time.Time {
 	return f.t
 }
 
+// timeZoneFlag is a flag.Value for an IANA time zone name.
+type timeZoneFlag struct {
+	loc *time.Location
+}
+
+func (f *timeZoneFlag) String() string {
+	if f.loc == nil {
+		return ""
+	}
+	return f.loc.String()
+}
+
+func (f *timeZoneFlag) Set(s string) error {
+	if s == "" {
+		f.loc = nil
+		return nil
+	}
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		return err
+	}
+	f.loc = loc
+	return nil
+}
+
+// Location returns the time zone, or nil if it's not set.
+func (f *timeZoneFlag) Location() *time.Location {
+	return f.loc
+}
+
 // ebirdClient encapsulates the ebird package functions for testing.
 type ebirdClient interface {
-	Records(string) (iter.Seq[ebird.Record], error)
-	DownloadMLAsset(string) (string, bool, error)
+	Records(context.Context, string) (iter.Seq[ebird.Record], error)
+	DownloadMLAssets(context.Context, []string, int, func(ebird.MLDownload, int, int)) []ebird.MLDownload
+	RemoveMLDownloads([]ebird.MLDownload)
+	ValidateMediaFile(string) (ebird.MediaKind, error)
+	MLAssetInfo(context.Context, string) (ebird.MLAssetInfo, error)
+	LocationAccuracy(context.Context, string, int) int
 }
 
-type ebirdClientImpl struct{}
+type ebirdClientImpl struct {
+	api *ebird.APIClient // for --location_accuracy and --trip_report; nil if they're off
+}
 
-func (ebirdClientImpl) Records(path string) (iter.Seq[ebird.Record], error) {
-	return ebird.Records(path)
+// Records returns the records in the eBird export at path, or with
+// --trip_report, the records in the trip report at the URL path.
+// birdsync can't continue without its records, so an error reading
+// them partway through is fatal.
+func (c ebirdClientImpl) Records(ctx context.Context, path string) (iter.Seq[ebird.Record], error) {
+	if tripReport != "" {
+		// Fetch the trip report and its checklists from the eBird API
+		// once, since birdsync reads the records more than once.
+		var recs []ebird.Record
+		for rec, err := range c.api.TripReportRecords(ctx, path) {
+			if err != nil {
+				return nil, err
+			}
+			recs = append(recs, rec)
+		}
+		return slices.Values(recs), nil
+	}
+	records, err := ebird.Records(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(ebird.Record) bool) {
+		for rec, err := range records {
+			if err != nil {
+				fatalf("%v", err)
+			}
+			if !yield(rec) {
+				return
+			}
+		}
+	}, nil
 }
 
-func (ebirdClientImpl) DownloadMLAsset(id string) (string, bool, error) {
-	return ebird.DownloadMLAsset(id)
+func (ebirdClientImpl) DownloadMLAssets(ctx context.Context, ids []string, workers int, progress func(ebird.MLDownload, int, int)) []ebird.MLDownload {
+	return ebird.DownloadMLAssets(ctx, ids, workers, progress)
+}
+
+func (ebirdClientImpl) RemoveMLDownloads(downloads []ebird.MLDownload) {
+	if err := ebird.RemoveMLDownloads(downloads); err != nil {
+		log.Printf("Couldn't remove downloaded ML assets: %v", err)
+	}
+}
+
+func (ebirdClientImpl) ValidateMediaFile(path string) (ebird.MediaKind, error) {
+	return ebird.ValidateMediaFile(path)
+}
+
+func (ebirdClientImpl) MLAssetInfo(ctx context.Context, id string) (ebird.MLAssetInfo, error) {
+	return ebird.GetMLAssetInfo(ctx, id)
+}
+
+// LocationAccuracy returns the positional accuracy of the location with
+// the provided ID, or hotspotAccuracy without --location_accuracy or if
+// the location can't be looked up.
+func (c ebirdClientImpl) LocationAccuracy(ctx context.Context, locationID string, hotspotAccuracy int) int {
+	if !locationAccuracy {
+		return hotspotAccuracy
+	}
+	accuracy, err := c.api.LocationAccuracy(ctx, locationID, hotspotAccuracy)
+	if err != nil {
+		log.Printf("Using positional accuracy of %d meters for location %s: %v", hotspotAccuracy, locationID, err)
+	}
+	return accuracy
 }
 
 // inatClient encapsulates the inat package functions for testing.
 type inatClient interface {
 	GetUserID() string
 	GetAPIToken() string
-	DownloadObservations(string, time.Time, time.Time, ...string) []inat.Result
+	DownloadObservations(string, time.Time, time.Time, ...string) ([]inat.Result, error)
 	CreateObservation(inat.Observation) error
 	UpdateObservation(inat.Observation) error
 	UploadMedia(string, bool, string, string) error
+	LookupTaxon(string) (inat.Taxon, error)
+	MatchTaxon(string) ([]inat.Taxon, error)
+	Ping(context.Context) error
+	LookupProject(string) (inat.Project, error)
+	AddObservationToProject(int, uuid.UUID) error
 }
 
 type inatClientImpl struct {
@@ -71,18 +173,40 @@ func (c inatClientImpl) GetAPIToken() string {
 	return inat.GetAPIToken()
 }
 
-func (c inatClientImpl) DownloadObservations(userID string, after, before time.Time, fields ...string) []inat.Result {
+func (c inatClientImpl) DownloadObservations(userID string, after, before time.Time, fields ...string) ([]inat.Result, error) {
 	return c.client.DownloadObservations(userID, after, before, fields...)
 }
 
 func (c inatClientImpl) CreateObservation(obs inat.Observation) error {
-	return c.client.CreateObservation(obs)
+	_, err := c.client.CreateObservation(obs)
+	return err
 }
 
 func (c inatClientImpl) UpdateObservation(obs inat.Observation) error {
-	return c.client.UpdateObservation(obs)
+	_, err := c.client.UpdateObservation(obs)
+	return err
 }
 
 func (c inatClientImpl) UploadMedia(filename string, isPhoto bool, assetID, obsUUID string) error {
 	return c.client.UploadMedia(filename, isPhoto, assetID, obsUUID)
 }
+
+func (c inatClientImpl) LookupTaxon(name string) (inat.Taxon, error) {
+	return c.client.LookupTaxon(name)
+}
+
+func (c inatClientImpl) MatchTaxon(name string) ([]inat.Taxon, error) {
+	return c.client.MatchTaxon(name)
+}
+
+func (c inatClientImpl) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx)
+}
+
+func (c inatClientImpl) LookupProject(idOrSlug string) (inat.Project, error) {
+	return c.client.LookupProject(idOrSlug)
+}
+
+func (c inatClientImpl) AddObservationToProject(projectID int, obsUUID uuid.UUID) error {
+	return c.client.AddObservationToProject(projectID, obsUUID)
+}
@@ -0,0 +1,140 @@
+package inat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ControlledTerm is an attribute of iNaturalist's annotation vocabulary,
+// like "Life Stage", with its values, like "Adult" and "Juvenile", or one
+// of those values.
+type ControlledTerm struct {
+	ID          int              `json:"id,omitempty"`
+	Label       string           `json:"label,omitempty"`
+	Multivalued bool             `json:"multivalued,omitempty"` // observations may have several of its values
+	Values      []ControlledTerm `json:"values,omitempty"`
+}
+
+// ControlledTerms is returned by https://api.inaturalist.org/v2/controlled_terms
+type ControlledTerms struct {
+	Results      []ControlledTerm `json:"results,omitempty"`
+	TotalResults int              `json:"total_results,omitempty"`
+}
+
+// Annotation is an annotation on an observation: a value of a controlled
+// attribute, like Life Stage: Adult.
+type Annotation struct {
+	UUID                  uuid.UUID `json:"uuid,omitempty"`
+	ControlledAttributeID int       `json:"controlled_attribute_id,omitempty"`
+	ControlledValueID     int       `json:"controlled_value_id,omitempty"`
+}
+
+// ControlledTerms returns iNaturalist's annotation vocabulary: the
+// attributes, like "Life Stage", "Sex", and "Alive or Dead", and their
+// values. It's cached for the lifetime of the client, and saved by SaveCache.
+func (c *Client) ControlledTerms() ([]ControlledTerm, error) {
+	c.mu.Lock()
+	terms := c.terms.Value
+	c.mu.Unlock()
+	if terms != nil {
+		return terms, nil
+	}
+	req, err := http.NewRequest("GET", c.baseURL+"/controlled_terms?fields=all", nil)
+	if err != nil {
+		return nil, fmt.Errorf("ControlledTerms: %w", err)
+	}
+	body, err := c.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("ControlledTerms: %w", err)
+	}
+	var results ControlledTerms
+	if err := json.Unmarshal([]byte(body), &results); err != nil {
+		return nil, fmt.Errorf("ControlledTerms: decoding response: %w", err)
+	}
+	terms = results.Results
+	if terms == nil {
+		terms = []ControlledTerm{}
+	}
+	c.mu.Lock()
+	c.terms = cached[[]ControlledTerm]{terms, c.now()}
+	c.mu.Unlock()
+	return terms, nil
+}
+
+// FindAnnotation returns the annotation with the attribute and value
+// labeled attribute and value in terms, ignoring case, like
+// FindAnnotation(terms, "Sex", "Female"). ok is false if there's none.
+func FindAnnotation(terms []ControlledTerm, attribute, value string) (a Annotation, ok bool) {
+	for _, t := range terms {
+		if !strings.EqualFold(t.Label, attribute) {
+			continue
+		}
+		for _, v := range t.Values {
+			if strings.EqualFold(v.Label, value) {
+				return Annotation{ControlledAttributeID: t.ID, ControlledValueID: v.ID}, true
+			}
+		}
+	}
+	return Annotation{}, false
+}
+
+// AddAnnotation annotates the observation obsUUID with a, which needs just
+// its attribute and value IDs, such as from FindAnnotation. iNaturalist
+// rejects a second value of an attribute that isn't Multivalued.
+func (c *Client) AddAnnotation(obsUUID uuid.UUID, a Annotation) error {
+	if a.ControlledAttributeID <= 0 || a.ControlledValueID <= 0 {
+		return fmt.Errorf("AddAnnotation(%s): %w: bad attribute or value ID", obsUUID, ErrInvalidObservation)
+	}
+	buf := &bytes.Buffer{}
+	err := json.NewEncoder(buf).Encode(struct {
+		Annotation any `json:"annotation"`
+	}{
+		struct {
+			ResourceType          string    `json:"resource_type"`
+			ResourceID            uuid.UUID `json:"resource_id"`
+			ControlledAttributeID int       `json:"controlled_attribute_id"`
+			ControlledValueID     int       `json:"controlled_value_id"`
+		}{"Observation", obsUUID, a.ControlledAttributeID, a.ControlledValueID},
+	})
+	if err != nil {
+		return fmt.Errorf("AddAnnotation(%s): %w", obsUUID, err)
+	}
+	req, err := http.NewRequest("POST", c.baseURL+"/annotations", buf)
+	if err != nil {
+		return fmt.Errorf("AddAnnotation(%s): %w", obsUUID, err)
+	}
+	if _, err := c.roundTrip(req); err != nil {
+		return fmt.Errorf("AddAnnotation(%s): %w", obsUUID, err)
+	}
+	log.Printf("Annotated %s with %d=%d", ObservationURL(obsUUID), a.ControlledAttributeID, a.ControlledValueID)
+	return nil
+}
+
+// DeleteAnnotation deletes the annotation with the provided UUID,
+// as returned by Annotations.
+func (c *Client) DeleteAnnotation(annotationUUID uuid.UUID) error {
+	req, err := http.NewRequest("DELETE", c.baseURL+"/annotations/"+annotationUUID.String(), nil)
+	if err != nil {
+		return fmt.Errorf("DeleteAnnotation(%s): %w", annotationUUID, err)
+	}
+	if _, err := c.roundTrip(req); err != nil {
+		return fmt.Errorf("DeleteAnnotation(%s): %w", annotationUUID, err)
+	}
+	return nil
+}
+
+// Annotations returns the annotations of the observation identified by
+// its numeric ID or UUID, as GetObservation does.
+func (c *Client) Annotations(id string) ([]Annotation, error) {
+	r, err := c.GetObservation(id, "uuid", "annotations.all")
+	if err != nil {
+		return nil, fmt.Errorf("Annotations: %w", err)
+	}
+	return r.Annotations, nil
+}
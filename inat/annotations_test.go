@@ -0,0 +1,92 @@
+package inat
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestClient_Annotations(t *testing.T) {
+	obsUUID := uuid.New()
+	annotationUUID := uuid.New()
+	var termRequests int
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/controlled_terms":
+			termRequests++
+			json.NewEncoder(w).Encode(ControlledTerms{Results: []ControlledTerm{
+				{ID: 1, Label: "Life Stage", Values: []ControlledTerm{{ID: 2, Label: "Adult"}, {ID: 8, Label: "Juvenile"}}},
+				{ID: 9, Label: "Sex", Values: []ControlledTerm{{ID: 10, Label: "Female"}, {ID: 11, Label: "Male"}}},
+			}})
+		case r.Method == http.MethodPost && r.URL.Path == "/annotations":
+			var req struct {
+				Annotation struct {
+					ResourceType          string    `json:"resource_type"`
+					ResourceID            uuid.UUID `json:"resource_id"`
+					ControlledAttributeID int       `json:"controlled_attribute_id"`
+					ControlledValueID     int       `json:"controlled_value_id"`
+				} `json:"annotation"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decoding request: %v", err)
+			}
+			if a := req.Annotation; a.ResourceType != "Observation" || a.ResourceID != obsUUID || a.ControlledAttributeID != 9 || a.ControlledValueID != 10 {
+				t.Errorf("annotation = %+v, want Sex: Female on %s", a, obsUUID)
+			}
+			w.Write([]byte("{}"))
+		case r.URL.Path == "/observations":
+			json.NewEncoder(w).Encode(Observations{TotalResults: 1, Results: []Result{{
+				UUID:        obsUUID,
+				Annotations: []Annotation{{UUID: annotationUUID, ControlledAttributeID: 9, ControlledValueID: 10}},
+			}}})
+		case r.Method == http.MethodDelete:
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+
+	var terms []ControlledTerm
+	for range 2 {
+		var err error
+		if terms, err = client.ControlledTerms(); err != nil || len(terms) != 2 {
+			t.Fatalf("ControlledTerms() = %+v, %v; want 2 terms", terms, err)
+		}
+	}
+	if termRequests != 1 {
+		t.Errorf("ControlledTerms() made %d requests, want 1 (cached)", termRequests)
+	}
+	a, ok := FindAnnotation(terms, "sex", "female")
+	if !ok || a.ControlledAttributeID != 9 || a.ControlledValueID != 10 {
+		t.Errorf("FindAnnotation(sex, female) = %+v, %v; want 9, 10", a, ok)
+	}
+	if _, ok := FindAnnotation(terms, "Sex", "Adult"); ok {
+		t.Error("FindAnnotation(Sex, Adult) found an annotation, want none")
+	}
+	if err := client.AddAnnotation(obsUUID, a); err != nil {
+		t.Fatalf("AddAnnotation() error = %v", err)
+	}
+	if err := client.AddAnnotation(obsUUID, Annotation{}); !errors.Is(err, ErrInvalidObservation) {
+		t.Errorf("AddAnnotation() with no IDs error = %v, want ErrInvalidObservation", err)
+	}
+	annotations, err := client.Annotations(obsUUID.String())
+	if err != nil || len(annotations) != 1 || annotations[0].UUID != annotationUUID {
+		t.Errorf("Annotations() = %+v, %v; want %s", annotations, err, annotationUUID)
+	}
+	if err := client.DeleteAnnotation(annotationUUID); err != nil {
+		t.Errorf("DeleteAnnotation() error = %v", err)
+	}
+	if want := "DELETE /annotations/" + annotationUUID.String(); requests[len(requests)-1] != want {
+		t.Errorf("DeleteAnnotation() requested %s, want %s", requests[len(requests)-1], want)
+	}
+}
@@ -0,0 +1,319 @@
+package inat
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuthBaseURL is the base URL of iNaturalist's OAuth2 provider, which is
+// the website rather than the API. It also issues API tokens.
+var OAuthBaseURL = "https://www.inaturalist.org"
+
+// DefaultCallbackAddr is the local address on which OAuthConfig.Authorize
+// listens for the OAuth2 callback if CallbackAddr is empty.
+const DefaultCallbackAddr = "localhost:4567"
+
+// apiTokenRefresh is how long before an API token expires that the client
+// replaces it, so that requests in flight don't use an expired token.
+const apiTokenRefresh = 5 * time.Minute
+
+// OAuthConfig identifies an iNaturalist OAuth application. Register one
+// at https://www.inaturalist.org/oauth/applications/new with the redirect
+// URI http://localhost:4567/callback (or your CallbackAddr), and mark it
+// confidential only if you set ClientSecret.
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string // optional; the flow always uses PKCE
+	CallbackAddr string // host:port of the redirect URI; DefaultCallbackAddr if empty
+}
+
+func (cfg OAuthConfig) callbackAddr() string {
+	if cfg.CallbackAddr == "" {
+		return DefaultCallbackAddr
+	}
+	return cfg.CallbackAddr
+}
+
+func (cfg OAuthConfig) redirectURI() string {
+	return "http://" + cfg.callbackAddr() + "/callback"
+}
+
+// randomString returns n random bytes, base64url-encoded.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Authorize runs the OAuth2 authorization-code flow with PKCE and returns
+// an access token. It listens for the callback on CallbackAddr, calls open
+// with the URL at which the user signs in to iNaturalist and approves the
+// application (open may launch a browser or just print the URL), waits for
+// the callback, and exchanges its code for the access token.
+//
+// iNaturalist's access tokens don't expire, so save the token with
+// SaveAccessToken rather than authorizing every run, and use it with
+// NewOAuthClient.
+func (cfg OAuthConfig) Authorize(ctx context.Context, open func(authURL string) error) (string, error) {
+	state, err := randomString(16)
+	if err != nil {
+		return "", fmt.Errorf("Authorize: %w", err)
+	}
+	verifier, err := randomString(32)
+	if err != nil {
+		return "", fmt.Errorf("Authorize: %w", err)
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	ln, err := net.Listen("tcp", cfg.callbackAddr())
+	if err != nil {
+		return "", fmt.Errorf("Authorize: listening for the callback: %w", err)
+	}
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var res result
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "Unexpected state; try signing in again.", http.StatusBadRequest)
+			return // not our request; keep waiting
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("iNaturalist denied authorization: %s %s", q.Get("error"), q.Get("error_description"))
+			fmt.Fprintln(w, "Authorization failed. You can close this window.")
+		case q.Get("code") == "":
+			res.err = errors.New("callback has no authorization code")
+			fmt.Fprintln(w, "Authorization failed. You can close this window.")
+		default:
+			res.code = q.Get("code")
+			fmt.Fprintln(w, "Signed in to iNaturalist. You can close this window.")
+		}
+		select {
+		case results <- res:
+		default: // already answered
+		}
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	q := url.Values{}
+	q.Set("client_id", cfg.ClientID)
+	q.Set("redirect_uri", cfg.redirectURI())
+	q.Set("response_type", "code")
+	q.Set("state", state)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	if err := open(OAuthBaseURL + "/oauth/authorize?" + q.Encode()); err != nil {
+		return "", fmt.Errorf("Authorize: %w", err)
+	}
+
+	var res result
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("Authorize: waiting for sign-in: %w", ctx.Err())
+	case res = <-results:
+	}
+	if res.err != nil {
+		return "", fmt.Errorf("Authorize: %w", res.err)
+	}
+	token, err := cfg.exchange(ctx, res.code, verifier)
+	if err != nil {
+		return "", fmt.Errorf("Authorize: %w", err)
+	}
+	return token, nil
+}
+
+// exchange exchanges an authorization code for an access token.
+func (cfg OAuthConfig) exchange(ctx context.Context, code, verifier string) (string, error) {
+	form := url.Values{}
+	form.Set("client_id", cfg.ClientID)
+	if cfg.ClientSecret != "" {
+		form.Set("client_secret", cfg.ClientSecret)
+	}
+	form.Set("code", code)
+	form.Set("code_verifier", verifier)
+	form.Set("redirect_uri", cfg.redirectURI())
+	form.Set("grant_type", "authorization_code")
+	req, err := http.NewRequestWithContext(ctx, "POST", OAuthBaseURL+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSON(http.DefaultClient, req, &resp); err != nil {
+		return "", fmt.Errorf("exchanging code for access token: %w", err)
+	}
+	if resp.AccessToken == "" {
+		return "", errors.New("exchanging code for access token: no token in response")
+	}
+	return resp.AccessToken, nil
+}
+
+// doJSON makes the request and decodes its JSON response into v.
+func doJSON(httpClient *http.Client, req *http.Request, v any) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("making HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%s: %w", resp.Status, ErrUnauthorized)
+	}
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("bad HTTP status: %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// FetchAPIToken returns a new API token, the JWT that authorizes API
+// requests, for the user who authorized the OAuth access token.
+// API tokens expire after 24 hours.
+func FetchAPIToken(ctx context.Context, accessToken string) (string, error) {
+	return fetchAPIToken(ctx, http.DefaultClient, accessToken)
+}
+
+func fetchAPIToken(ctx context.Context, httpClient *http.Client, accessToken string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", OAuthBaseURL+"/users/api_token", nil)
+	if err != nil {
+		return "", fmt.Errorf("FetchAPIToken: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	var resp struct {
+		APIToken string `json:"api_token"`
+	}
+	if err := doJSON(httpClient, req, &resp); err != nil {
+		return "", fmt.Errorf("FetchAPIToken: %w", err)
+	}
+	if resp.APIToken == "" {
+		return "", errors.New("FetchAPIToken: no token in response")
+	}
+	return resp.APIToken, nil
+}
+
+// jwtExpiry returns the expiration time in the claims of the JWT token.
+// It doesn't verify the token; iNaturalist does that.
+func jwtExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(b, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
+
+// apiTokenSource provides API tokens for an OAuth access token,
+// fetching a new one when the current one is about to expire.
+type apiTokenSource struct {
+	accessToken string
+	httpClient  *http.Client
+	now         func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// get returns the current API token, fetching a new one if there's none,
+// it's about to expire, or refresh is set because iNaturalist rejected it.
+func (s *apiTokenSource) get(ctx context.Context, refresh bool) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && !refresh && s.now().Before(s.expiry.Add(-apiTokenRefresh)) {
+		return s.token, nil
+	}
+	token, err := fetchAPIToken(ctx, s.httpClient, s.accessToken)
+	if err != nil {
+		return "", err
+	}
+	expiry, ok := jwtExpiry(token)
+	if !ok {
+		expiry = s.now().Add(24 * time.Hour)
+	}
+	s.token, s.expiry = token, expiry
+	return token, nil
+}
+
+// NewOAuthClient is like NewClient but authenticates with an OAuth access
+// token from OAuthConfig.Authorize instead of an API token. The client
+// fetches an API token when it first needs one and fetches a new one
+// before it expires, or if iNaturalist rejects it, so long runs don't
+// fail after 24 hours.
+func NewOAuthClient(baseURL, accessToken, userAgent string) *Client {
+	c := NewClient(baseURL, "", userAgent)
+	c.tokens = &apiTokenSource{
+		accessToken: accessToken,
+		httpClient:  c.httpClient,
+		now:         c.now,
+	}
+	return c
+}
+
+// DefaultAccessTokenFile returns the file in which birdsync saves the
+// OAuth access token, in the user's configuration directory.
+func DefaultAccessTokenFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "birdsync", "inat_access_token"), nil
+}
+
+// SaveAccessToken saves the OAuth access token in the file path, creating
+// its directory if needed. Only the user can read the file, since the
+// token grants access to their iNaturalist account.
+func SaveAccessToken(path, accessToken string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("SaveAccessToken: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(accessToken+"\n"), 0o600); err != nil {
+		return fmt.Errorf("SaveAccessToken: %w", err)
+	}
+	return nil
+}
+
+// LoadAccessToken returns the OAuth access token saved in the file path
+// by SaveAccessToken. If there's no file, the error wraps fs.ErrNotExist.
+func LoadAccessToken(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("LoadAccessToken: %w", err)
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("LoadAccessToken: %s is empty", path)
+	}
+	return token, nil
+}
@@ -0,0 +1,185 @@
+package inat
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testJWT returns a fake API token that expires at exp.
+func testJWT(n int, exp time.Time) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	return enc([]byte(`{"alg":"none"}`)) + "." +
+		enc([]byte(fmt.Sprintf(`{"user_id":1,"n":%d,"exp":%d}`, n, exp.Unix()))) + ".sig"
+}
+
+func TestAuthorize(t *testing.T) {
+	var challenge string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth/token" {
+			http.NotFound(w, r)
+			return
+		}
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if r.Form.Get("code") != "the-code" || r.Form.Get("client_id") != "app" ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"access_token":"access","token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+	defer func(u string) { OAuthBaseURL = u }(OAuthBaseURL)
+	OAuthBaseURL = server.URL
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	cfg := OAuthConfig{ClientID: "app", CallbackAddr: addr}
+
+	// The "browser" approves the request by following the redirect.
+	approve := func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		challenge = q.Get("code_challenge")
+		if q.Get("redirect_uri") != "http://"+addr+"/callback" || q.Get("code_challenge_method") != "S256" {
+			t.Errorf("authorization URL %s has the wrong redirect URI or challenge method", authURL)
+		}
+		go func() {
+			// A stray request with the wrong state is ignored.
+			if resp, err := http.Get(q.Get("redirect_uri") + "?code=evil&state=wrong"); err == nil {
+				resp.Body.Close()
+			}
+			resp, err := http.Get(q.Get("redirect_uri") + "?code=the-code&state=" + url.QueryEscape(q.Get("state")))
+			if err != nil {
+				t.Errorf("callback error: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	token, err := cfg.Authorize(ctx, approve)
+	if err != nil || token != "access" {
+		t.Errorf("Authorize() = %q, %v; want access", token, err)
+	}
+
+	// Denying authorization fails.
+	deny := func(authURL string) error {
+		u, _ := url.Parse(authURL)
+		q := u.Query()
+		go func() {
+			resp, err := http.Get(q.Get("redirect_uri") + "?error=access_denied&state=" + url.QueryEscape(q.Get("state")))
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+	if _, err := cfg.Authorize(ctx, deny); err == nil {
+		t.Error("Authorize() denied succeeded, want an error")
+	}
+}
+
+func TestOAuthClient(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fetches := 0
+	rejectNext := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/api_token":
+			if r.Header.Get("Authorization") != "Bearer access" {
+				http.Error(w, "bad access token", http.StatusUnauthorized)
+				return
+			}
+			fetches++
+			fmt.Fprintf(w, `{"api_token":%q}`, testJWT(fetches, now.Add(24*time.Hour)))
+		case "/users/me":
+			if rejectNext || r.Header.Get("Authorization") != testJWT(fetches, now.Add(24*time.Hour)) {
+				rejectNext = false
+				http.Error(w, "bad API token", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"results":[{"id":1,"login":"birder"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(u string) { OAuthBaseURL = u }(OAuthBaseURL)
+	OAuthBaseURL = server.URL
+
+	client := NewOAuthClient(server.URL, "access", "test")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now)
+	client.tokens.now = func() time.Time { return now }
+	ctx := context.Background()
+	for range 2 {
+		if err := client.Ping(ctx); err != nil {
+			t.Fatalf("Ping() error = %v", err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("fetched %d API tokens, want 1", fetches)
+	}
+
+	// Tokens are replaced before they expire.
+	now = now.Add(24*time.Hour - time.Minute)
+	if err := client.Ping(ctx); err != nil || fetches != 2 {
+		t.Errorf("Ping() near expiry = %v after %d fetches, want a new token", err, fetches)
+	}
+
+	// A rejected token is replaced and the request retried.
+	rejectNext = true
+	if err := client.Ping(ctx); err != nil || fetches != 3 {
+		t.Errorf("Ping() with rejected token = %v after %d fetches, want a retry with a new token", err, fetches)
+	}
+
+	// A revoked access token fails.
+	client = NewOAuthClient(server.URL, "revoked", "test")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now)
+	if err := client.Ping(ctx); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Ping() with revoked access token = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Unix(1750000000, 0)
+	if got, ok := jwtExpiry(testJWT(1, exp)); !ok || !got.Equal(exp) {
+		t.Errorf("jwtExpiry() = %v, %v; want %v", got, ok, exp)
+	}
+	if _, ok := jwtExpiry("not-a-jwt"); ok {
+		t.Error("jwtExpiry(not-a-jwt) succeeded")
+	}
+}
+
+func TestAccessTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "birdsync", "token")
+	if _, err := LoadAccessToken(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadAccessToken() of missing file = %v, want fs.ErrNotExist", err)
+	}
+	if err := SaveAccessToken(path, "access"); err != nil {
+		t.Fatalf("SaveAccessToken() error = %v", err)
+	}
+	if got, err := LoadAccessToken(path); err != nil || got != "access" {
+		t.Errorf("LoadAccessToken() = %q, %v; want access", got, err)
+	}
+}
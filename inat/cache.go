@@ -0,0 +1,115 @@
+package inat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheTTL is how long lookups loaded by LoadCache stay fresh.
+// iNaturalist's taxonomy, places, and annotation vocabulary change
+// slowly, but they do change, so older entries are dropped and looked
+// up again.
+var CacheTTL = 30 * 24 * time.Hour
+
+// cached is a cached API result and when it was fetched.
+type cached[T any] struct {
+	Value   T         `json:"value"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// cacheFile is the format of the files written by SaveCache.
+type cacheFile struct {
+	Ancestry map[int]cached[[]Taxon]            `json:"ancestry,omitempty"`
+	Taxa     map[string]cached[[]Taxon]         `json:"taxa,omitempty"`
+	Places   map[string]cached[json.RawMessage] `json:"places,omitempty"`
+	Terms    *cached[[]ControlledTerm]          `json:"terms,omitempty"`
+}
+
+// SaveCache writes the client's taxon lookups (from TaxonAncestry,
+// LookupTaxon, MatchTaxon, and SearchTaxa), place lookups (from
+// LookupPlace, SearchPlaces, and NearbyPlaces), and ControlledTerms to
+// the file path as JSON, so that LoadCache can reuse them in a later run.
+// The client caches lookups in memory whether or not it's saved.
+func (c *Client) SaveCache(path string) error {
+	c.mu.Lock()
+	f := cacheFile{Ancestry: c.ancestry, Taxa: c.taxa, Places: c.places}
+	if c.terms.Value != nil {
+		terms := c.terms
+		f.Terms = &terms
+	}
+	b, err := json.Marshal(f)
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("SaveCache: %w", err)
+	}
+	// Write atomically so a crash doesn't leave a corrupt cache.
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("SaveCache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("SaveCache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("SaveCache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("SaveCache: %w", err)
+	}
+	return nil
+}
+
+// LoadCache adds the lookups saved by SaveCache in the file path
+// to the client's cache, skipping any fetched more than CacheTTL ago.
+// Lookups already in the client's cache take precedence. If the file
+// doesn't exist, the error satisfies errors.Is(err, fs.ErrNotExist).
+func (c *Client) LoadCache(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("LoadCache: %w", err)
+	}
+	var f cacheFile
+	if err := json.Unmarshal(b, &f); err != nil {
+		return fmt.Errorf("LoadCache: %s: %w", path, err)
+	}
+	now := c.now()
+	fresh := func(fetched time.Time) bool {
+		return now.Sub(fetched) <= CacheTTL
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ancestry == nil {
+		c.ancestry = map[int]cached[[]Taxon]{}
+	}
+	for id, e := range f.Ancestry {
+		if _, ok := c.ancestry[id]; !ok && fresh(e.Fetched) {
+			c.ancestry[id] = e
+		}
+	}
+	if c.taxa == nil {
+		c.taxa = map[string]cached[[]Taxon]{}
+	}
+	for q, e := range f.Taxa {
+		if _, ok := c.taxa[q]; !ok && fresh(e.Fetched) {
+			c.taxa[q] = e
+		}
+	}
+	if c.places == nil {
+		c.places = map[string]cached[json.RawMessage]{}
+	}
+	for key, e := range f.Places {
+		if _, ok := c.places[key]; !ok && fresh(e.Fetched) {
+			c.places[key] = e
+		}
+	}
+	if f.Terms != nil && c.terms.Value == nil && f.Terms.Value != nil && fresh(f.Terms.Fetched) {
+		c.terms = *f.Terms
+	}
+	return nil
+}
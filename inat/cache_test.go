@@ -0,0 +1,145 @@
+package inat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClient_SaveLoadCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(Taxa{Results: []Taxon{
+			{ID: 12727, Name: "Turdus migratorius", Rank: "species", PreferredCommonName: "American Robin"},
+		}})
+	}))
+	defer server.Close()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newClient := func() *Client {
+		c := NewClient(server.URL, "", "")
+		c.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+		c.now = func() time.Time { return now }
+		return c
+	}
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c := newClient()
+	if err := c.LoadCache(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadCache(missing) error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := c.LookupTaxon("Turdus migratorius"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.TaxonAncestry(context.Background(), 12727); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SaveCache(path); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("Expected 2 requests, got %d", requests)
+	}
+
+	// A new client with the saved cache doesn't make any requests.
+	now = now.Add(CacheTTL - time.Hour)
+	c = newClient()
+	if err := c.LoadCache(path); err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	taxon, err := c.LookupTaxon("Turdus migratorius")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if taxon.ID != 12727 {
+		t.Errorf("LookupTaxon() = %+v, want 12727", taxon)
+	}
+	if _, err := c.TaxonAncestry(context.Background(), 12727); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected no more requests with a fresh cache, got %d", requests-2)
+	}
+
+	// After the TTL, the cached lookups are stale and are made again.
+	now = now.Add(2 * time.Hour)
+	c = newClient()
+	if err := c.LoadCache(path); err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	if _, err := c.LookupTaxon("Turdus migratorius"); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 1 more request with a stale cache, got %d", requests-2)
+	}
+}
+
+func TestClient_SaveLoadCachePlacesAndTerms(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/places/2015":
+			json.NewEncoder(w).Encode(Places{TotalResults: 1, Results: []Place{{ID: 2015, Name: "Fairfax"}}})
+		case "/controlled_terms":
+			json.NewEncoder(w).Encode(ControlledTerms{Results: []ControlledTerm{
+				{ID: 1, Label: "Life Stage", Values: []ControlledTerm{{ID: 2, Label: "Adult"}}},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	newClient := func() *Client {
+		c := NewClient(server.URL, "", "")
+		c.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+		return c
+	}
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	c := newClient()
+	if _, err := c.LookupPlace(2015); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ControlledTerms(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SaveCache(path); err != nil {
+		t.Fatalf("SaveCache() error = %v", err)
+	}
+	if requests != 2 {
+		t.Fatalf("Expected 2 requests, got %d", requests)
+	}
+
+	// A new client with the saved cache doesn't make any requests.
+	c = newClient()
+	if err := c.LoadCache(path); err != nil {
+		t.Fatalf("LoadCache() error = %v", err)
+	}
+	place, err := c.LookupPlace(2015)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if place.Name != "Fairfax" {
+		t.Errorf("LookupPlace() = %+v, want Fairfax", place)
+	}
+	terms, err := c.ControlledTerms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := FindAnnotation(terms, "Life Stage", "Adult"); !ok {
+		t.Errorf("ControlledTerms() = %+v, want Life Stage: Adult", terms)
+	}
+	if requests != 2 {
+		t.Errorf("Expected no more requests with a fresh cache, got %d", requests-2)
+	}
+}
@@ -2,14 +2,23 @@ package inat
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"net/url"
 	"os"
 	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,19 +27,45 @@ const (
 	debug = false
 	// BaseURL is the standard base URL for the iNaturalist API.
 	BaseURL = "https://api.inaturalist.org/v2"
+	// Timeout limits how long each iNaturalist API request may take,
+	// including uploading media files.
+	Timeout = 2 * time.Minute
 )
 
+// ErrUnauthorized is returned (wrapped) by Client methods when iNaturalist
+// rejects the API token, usually because it has expired.
+var ErrUnauthorized = errors.New("iNaturalist API token is missing, invalid, or expired")
+
+// ErrNotFound is returned (wrapped) by Client methods that look up
+// something iNaturalist doesn't have, or won't show the user.
+// An APIError for a 404 response matches it too; see IsNotFound.
+var ErrNotFound = errors.New("not found on iNaturalist")
+
 type Client struct {
-	apiToken  string
-	userAgent string
-	baseURL   string
+	apiToken   string
+	userAgent  string
+	baseURL    string
+	now        func() time.Time // replaceable for testing
+	limiter    *rateLimiter
+	httpClient *http.Client
+
+	tokens *apiTokenSource // for clients from NewOAuthClient; otherwise nil
+
+	mu       sync.Mutex
+	ancestry map[int]cached[[]Taxon]            // taxon ID to ancestors
+	taxa     map[string]cached[[]Taxon]         // taxon search query to results
+	places   map[string]cached[json.RawMessage] // place request to response
+	terms    cached[[]ControlledTerm]           // annotation vocabulary; Value is nil until fetched
 }
 
 func NewClient(baseURL, apiToken, userAgent string) *Client {
 	return &Client{
-		baseURL:   baseURL,
-		apiToken:  apiToken,
-		userAgent: userAgent,
+		baseURL:    baseURL,
+		apiToken:   apiToken,
+		userAgent:  userAgent,
+		now:        time.Now,
+		limiter:    newRateLimiter(requestInterval, 1, DailyRequestLimit, time.Now),
+		httpClient: &http.Client{Timeout: Timeout},
 	}
 }
 
@@ -39,23 +74,78 @@ func (c *Client) BaseURL() string {
 }
 
 func (c *Client) roundTrip(req *http.Request) (string, error) {
+	body, err := c.send(req, false)
+	if c.tokens != nil && errors.Is(err, ErrUnauthorized) && rewind(req) {
+		// The API token may have expired early. Get a new one and try again.
+		return c.send(req, true)
+	}
+	return body, err
+}
+
+// send makes the request, retrying it when iNaturalist throttles it or
+// fails in a way that may be temporary. See MaxAttempts.
+func (c *Client) send(req *http.Request, refreshToken bool) (string, error) {
+	for attempt := 1; ; attempt++ {
+		body, err := c.roundTripOnce(req, refreshToken && attempt == 1)
+		var r retryableError
+		if err == nil || !errors.As(err, &r) || attempt >= MaxAttempts || !rewind(req) {
+			return body, err
+		}
+		d := retryDelay(attempt)
+		if r.after > 0 {
+			d = r.after
+		}
+		if d > MaxRetryWait {
+			return "", fmt.Errorf("%w; iNaturalist asked to wait %v before retrying", err, d)
+		}
+		log.Printf("iNaturalist API %s %s: %v; retrying in %v", req.Method, req.URL.Path, err, d.Round(time.Millisecond))
+		if err := sleep(req.Context(), d); err != nil {
+			return "", fmt.Errorf("waiting to retry: %w", err)
+		}
+	}
+}
+
+// roundTripOnce makes the request once. For OAuth clients, refreshToken
+// fetches a new API token first.
+func (c *Client) roundTripOnce(req *http.Request, refreshToken bool) (string, error) {
 	req.Header.Set("User-Agent", c.userAgent)
-	req.Header.Set("Authorization", c.apiToken)
+	apiToken := c.apiToken
+	if c.tokens != nil {
+		var err error
+		apiToken, err = c.tokens.get(req.Context(), refreshToken)
+		if err != nil {
+			return "", fmt.Errorf("getting API token: %w", err)
+		}
+	}
+	req.Header.Set("Authorization", apiToken)
 
+	if err := c.Wait(req.Context()); err != nil {
+		return "", fmt.Errorf("waiting for rate limiter: %w", err)
+	}
 	if debug {
 		log.Printf("\nREQUEST: %+v\n", req)
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("making HTTP request: %w", err)
+		err = fmt.Errorf("making HTTP request: %w", err)
+		if req.Context().Err() == nil && (idempotent(req) || neverSent(err)) {
+			err = retryableError{err: err}
+		}
+		return "", err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusUnauthorized {
-		return "", fmt.Errorf("%s: refresh your INAT_API_TOKEN from https://www.inaturalist.org/users/api_token",
-			resp.Status)
-	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("bad HTTP status: %s", resp.Status)
+		apiErr := newAPIError(req, resp, c.now())
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized && c.tokens != nil:
+			return "", fmt.Errorf("%w: %w: sign in to iNaturalist again", apiErr, ErrUnauthorized)
+		case resp.StatusCode == http.StatusUnauthorized:
+			return "", fmt.Errorf("%w: %w: refresh your INAT_API_TOKEN from https://www.inaturalist.org/users/api_token",
+				apiErr, ErrUnauthorized)
+		case resp.StatusCode == http.StatusTooManyRequests || apiErr.temporary() && idempotent(req):
+			return "", retryableError{apiErr, apiErr.RetryAfter}
+		}
+		return "", apiErr
 	}
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -68,45 +158,140 @@ func (c *Client) roundTrip(req *http.Request) (string, error) {
 	return body, nil
 }
 
-func (c *Client) CreateObservation(obs Observation) error {
+// Ping checks that the client can reach the iNaturalist API and that
+// its API token is valid by fetching the current user. Call it before
+// starting a long sync to fail fast on misconfiguration. If the token
+// is rejected, the error wraps ErrUnauthorized; any other error means
+// the API couldn't be reached or failed.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/users/me?fields=id,login", nil)
+	if err != nil {
+		return fmt.Errorf("Ping: %w", err)
+	}
+	if _, err := c.roundTrip(req); err != nil {
+		if errors.Is(err, ErrUnauthorized) {
+			return fmt.Errorf("Ping: %w", err)
+		}
+		return fmt.Errorf("Ping: iNaturalist API at %s is unavailable: %w", c.baseURL, err)
+	}
+	return nil
+}
+
+// CreateObservation creates obs, which must pass Validate, and returns
+// the new observation as iNaturalist stored it, including its ID.
+// If iNaturalist's response doesn't include the observation, the result
+// has just obs.UUID.
+func (c *Client) CreateObservation(obs Observation) (Result, error) {
+	if err := obs.Validate(); err != nil {
+		return Result{}, fmt.Errorf("CreateObservation: %w", err)
+	}
 	buf := &bytes.Buffer{}
 	err := json.NewEncoder(buf).Encode(CreateObservation{
 		Observation: obs,
 	})
 	if err != nil {
-		return fmt.Errorf("CreateObservation: %w", err)
+		return Result{}, fmt.Errorf("CreateObservation: %w", err)
 	}
 	req, err := http.NewRequest("POST", c.baseURL+"/observations", buf)
 	if err != nil {
-		return fmt.Errorf("CreateObservation: %w", err)
+		return Result{}, fmt.Errorf("CreateObservation: %w", err)
 	}
-	_, err = c.roundTrip(req)
+	body, err := c.roundTrip(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("CreateObservation: %w", err)
+	}
+	r, err := observationResult(body, obs.UUID)
 	if err != nil {
-		return fmt.Errorf("CreateObservation: %w", err)
+		return Result{}, fmt.Errorf("CreateObservation: %w", err)
 	}
 	log.Printf("Created %s\n", obs.URLWithSpecies())
-	return nil
+	return r, nil
 }
 
-func (c *Client) UpdateObservation(obs Observation) error {
+// UpdateObservation replaces the fields of the observation obs.UUID that are
+// set in obs, which must pass Validate, and returns the updated observation
+// like CreateObservation. To add to an existing description rather than
+// replace it, set obs.Description using AppendDescription.
+func (c *Client) UpdateObservation(obs Observation) (Result, error) {
+	if err := obs.Validate(); err != nil {
+		return Result{}, fmt.Errorf("UpdateObservation: %w", err)
+	}
 	buf := &bytes.Buffer{}
 	err := json.NewEncoder(buf).Encode(UpdateObservation{
 		IgnorePhotos: true, // don't clobber photos!
 		Observation:  obs,
 	})
 	if err != nil {
-		return fmt.Errorf("UpdateObservation: %w", err)
+		return Result{}, fmt.Errorf("UpdateObservation: %w", err)
 	}
 	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/observations/%s", c.baseURL, obs.UUID), buf)
 	if err != nil {
-		return fmt.Errorf("UpdateObservation: %w", err)
+		return Result{}, fmt.Errorf("UpdateObservation: %w", err)
 	}
-	_, err = c.roundTrip(req)
+	body, err := c.roundTrip(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("UpdateObservation: %w", err)
+	}
+	r, err := observationResult(body, obs.UUID)
 	if err != nil {
-		return fmt.Errorf("UpdateObservation: %w", err)
+		return Result{}, fmt.Errorf("UpdateObservation: %w", err)
 	}
 	log.Printf("Updated %s\n", obs.URLWithSpecies())
-	return nil
+	return r, nil
+}
+
+// observationResult returns the observation in body, the response to
+// creating or updating the observation u, or a Result with just u if
+// the response has no observation.
+func observationResult(body string, u uuid.UUID) (Result, error) {
+	if strings.TrimSpace(body) == "" {
+		return Result{UUID: u}, nil
+	}
+	var obs Observations
+	if err := json.Unmarshal([]byte(body), &obs); err != nil {
+		return Result{}, fmt.Errorf("decoding response: %w", err)
+	}
+	if len(obs.Results) == 0 {
+		return Result{UUID: u}, nil
+	}
+	return obs.Results[0], nil
+}
+
+// GetObservation returns the current state of one observation, identified
+// by its numeric ID or its UUID, so callers can check a single observation
+// without downloading the user's others. The fields list specifies which
+// fields are populated, as for DownloadObservations; if it's empty, they
+// all are. If there's no such observation, the error wraps ErrNotFound.
+func (c *Client) GetObservation(id string, fields ...string) (Result, error) {
+	q := url.Values{}
+	id = strings.TrimSpace(id)
+	if u, err := uuid.Parse(id); err == nil {
+		q.Set("uuid", u.String())
+	} else if n, err := strconv.Atoi(id); err == nil && n > 0 {
+		q.Set("id", id)
+	} else {
+		return Result{}, fmt.Errorf("GetObservation(%q): not an observation ID or UUID", id)
+	}
+	if len(fields) == 0 {
+		fields = FullFields
+	}
+	q.Set("fields", strings.Join(fields, ","))
+	req, err := http.NewRequest("GET", c.baseURL+"/observations?"+q.Encode(), nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("GetObservation(%s): %w", id, err)
+	}
+	body, err := c.roundTrip(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("GetObservation(%s): %w", id, err)
+	}
+	var obs Observations
+	if err := json.Unmarshal([]byte(body), &obs); err != nil {
+		return Result{}, fmt.Errorf("GetObservation(%s): decoding response: %w", id, err)
+	}
+	if len(obs.Results) == 0 {
+		return Result{}, fmt.Errorf("GetObservation(%s): %w", id, ErrNotFound)
+	}
+	return obs.Results[0], nil
 }
 
 func (c *Client) DeleteObservation(id uuid.UUID) error {
@@ -122,52 +307,150 @@ func (c *Client) DeleteObservation(id uuid.UUID) error {
 	return nil
 }
 
+// Size limits of media uploads. iNaturalist rejects larger files,
+// so the client does too, before uploading them.
+var (
+	MaxPhotoBytes int64 = 20 << 20 // 20 MB
+	MaxSoundBytes int64 = 50 << 20 // 50 MB
+)
+
+var (
+	// ErrTooLarge is returned (wrapped) by the upload methods for files
+	// over MaxPhotoBytes or MaxSoundBytes.
+	ErrTooLarge = errors.New("file is too large for iNaturalist")
+	// ErrWrongMediaType is returned (wrapped) by the upload methods for
+	// files whose contents aren't the kind of media being uploaded.
+	ErrWrongMediaType = errors.New("file is the wrong type of media")
+)
+
+// UploadMedia uploads the Macaulay Library asset mlAssetID, downloaded
+// to filename, to the observation with UUID obsUUID, as a photo if
+// isPhoto and otherwise as a sound.
+// See UploadObservationPhoto and UploadObservationSound.
 func (c *Client) UploadMedia(filename string, isPhoto bool, mlAssetID string, obsUUID string) error {
+	u, err := uuid.Parse(obsUUID)
+	if err != nil {
+		return fmt.Errorf("UploadMedia: %w", err)
+	}
+	if isPhoto {
+		return c.UploadObservationPhoto(filename, mlAssetID, u)
+	}
+	return c.UploadObservationSound(filename, mlAssetID, u)
+}
+
+// UploadObservationPhoto uploads the photo in filename, the Macaulay Library
+// asset mlAssetID, to the observation obsUUID. The file must be an image
+// no larger than MaxPhotoBytes. It's uploaded as ML<mlAssetID>.<ext>.
+func (c *Client) UploadObservationPhoto(filename, mlAssetID string, obsUUID uuid.UUID) error {
+	if err := c.uploadMedia(filename, mlAssetID, obsUUID, true); err != nil {
+		return fmt.Errorf("UploadObservationPhoto: %w", err)
+	}
+	return nil
+}
+
+// UploadObservationSound is like UploadObservationPhoto for sounds,
+// which must be audio no larger than MaxSoundBytes.
+func (c *Client) UploadObservationSound(filename, mlAssetID string, obsUUID uuid.UUID) error {
+	if err := c.uploadMedia(filename, mlAssetID, obsUUID, false); err != nil {
+		return fmt.Errorf("UploadObservationSound: %w", err)
+	}
+	return nil
+}
+
+// mediaType returns the content type of the media file f, named filename,
+// from its contents, or from its extension if they aren't recognized,
+// and checks that it's an image if isPhoto or audio otherwise.
+// It leaves f at its start.
+func mediaType(f *os.File, filename string, isPhoto bool) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	contentType := http.DetectContentType(head[:n])
+	if contentType == "application/octet-stream" {
+		if t := mime.TypeByExtension(path.Ext(filename)); t != "" {
+			contentType = t
+		}
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	ok := strings.HasPrefix(mediaType, "image/")
+	if !isPhoto {
+		ok = strings.HasPrefix(mediaType, "audio/") || mediaType == "application/ogg"
+	}
+	if !ok {
+		return "", fmt.Errorf("%s is %s: %w", filename, mediaType, ErrWrongMediaType)
+	}
+	return mediaType, nil
+}
+
+// uploadMedia uploads filename to obsUUID as a photo if isPhoto and
+// otherwise as a sound.
+func (c *Client) uploadMedia(filename, mlAssetID string, obsUUID uuid.UUID, isPhoto bool) error {
 	destFilename := "ML" + mlAssetID + path.Ext(filename)
-	var fieldName string
-	var postURL string
+	fieldName := "observation_sound[observation_id]"
+	postURL := c.baseURL + "/observation_sounds"
+	maxBytes := MaxSoundBytes
 	if isPhoto {
 		fieldName = "observation_photo[observation_id]"
 		postURL = c.baseURL + "/observation_photos"
-		log.Println("Uploading photo as", destFilename)
-	} else {
-		fieldName = "observation_sound[observation_id]"
-		postURL = c.baseURL + "/observation_sounds"
-		log.Println("Uploading sound as", destFilename)
-	}
-	var requestBody bytes.Buffer
-	writer := multipart.NewWriter(&requestBody)
-	fileWriter, err := writer.CreateFormFile("file", destFilename)
-	if err != nil {
-		return fmt.Errorf("UploadMedia: %w", err)
+		maxBytes = MaxPhotoBytes
 	}
 	f, err := os.Open(filename)
 	if err != nil {
-		return fmt.Errorf("UploadMedia: %w", err)
+		return err
 	}
 	defer f.Close()
-	_, err = io.Copy(fileWriter, f)
+	fi, err := f.Stat()
 	if err != nil {
-		return fmt.Errorf("UploadMedia: %w", err)
+		return err
 	}
-	err = writer.WriteField(fieldName, obsUUID)
+	if fi.Size() > maxBytes {
+		return fmt.Errorf("%s is %d bytes, over the limit of %d: %w", filename, fi.Size(), maxBytes, ErrTooLarge)
+	}
+	contentType, err := mediaType(f, filename, isPhoto)
 	if err != nil {
-		return fmt.Errorf("UploadMedia: %w", err)
+		return err
+	}
+	if isPhoto {
+		log.Println("Uploading photo as", destFilename)
+	} else {
+		log.Println("Uploading sound as", destFilename)
 	}
-	err = writer.Close()
+
+	var requestBody bytes.Buffer
+	writer := multipart.NewWriter(&requestBody)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     "file",
+		"filename": destFilename,
+	}))
+	header.Set("Content-Type", contentType)
+	fileWriter, err := writer.CreatePart(header)
 	if err != nil {
-		return fmt.Errorf("UploadMedia: %w", err)
+		return err
+	}
+	if _, err := io.Copy(fileWriter, f); err != nil {
+		return err
+	}
+	if err := writer.WriteField(fieldName, obsUUID.String()); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
 	}
 
 	req, err := http.NewRequest("POST", postURL, &requestBody)
 	if err != nil {
-		return fmt.Errorf("UploadMedia: %w", err)
+		return err
 	}
 	// Set the Content-Type header to the multipart writer's boundary.
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	_, err = c.roundTrip(req)
-	if err != nil {
-		return fmt.Errorf("UploadMedia: %w", err)
+	if _, err := c.roundTrip(req); err != nil {
+		return err
 	}
 	// TODO: log the media URL from the response body
 	return nil
@@ -1,15 +1,51 @@
 package inat
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+func TestClient_Ping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/me" {
+			t.Errorf("Expected path /users/me, got %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"results":[{"id":1,"login":"birder"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	if err := NewClient(server.URL, "good-token", "").Ping(ctx); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	if err := NewClient(server.URL, "bad-token", "").Ping(ctx); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Ping() with bad token error = %v, want ErrUnauthorized", err)
+	}
+	server.Close()
+	defer func(n int) { MaxAttempts = n }(MaxAttempts)
+	MaxAttempts = 1
+	err := NewClient(server.URL, "good-token", "").Ping(ctx)
+	if err == nil || errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Ping() with server down error = %v, want network error", err)
+	}
+}
+
 func TestClient_CreateObservation(t *testing.T) {
+	obsUUID := uuid.New()
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			t.Errorf("Expected POST, got %s", r.Method)
@@ -17,16 +53,63 @@ func TestClient_CreateObservation(t *testing.T) {
 		if r.URL.Path != "/observations" {
 			t.Errorf("Expected path /observations, got %s", r.URL.Path)
 		}
-		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"total_results": 1, "results": [{"id": 42, "uuid": %q}]}`, obsUUID)
 	}))
 	defer server.Close()
 
 	client := NewClient(server.URL, "test-token", "test-user-agent")
 
-	obs := Observation{UUID: uuid.New()}
-	if err := client.CreateObservation(obs); err != nil {
+	obs := Observation{UUID: obsUUID}
+	r, err := client.CreateObservation(obs)
+	if err != nil {
 		t.Errorf("CreateObservation() error = %v", err)
 	}
+	if r.ID != 42 || r.UUID != obsUUID {
+		t.Errorf("CreateObservation() = %+v, want ID 42 and UUID %s", r, obsUUID)
+	}
+}
+
+func TestObservation_Validate(t *testing.T) {
+	valid := Observation{
+		UUID:                             uuid.New(),
+		Latitude:                         38.5,
+		Longitude:                        -77.25,
+		PositionalAccuracy:               1000,
+		Geoprivacy:                       "obscured",
+		ObservationFieldValuesAttributes: []ObservationFieldValue{{ObservationFieldID: CountField, Value: "2"}},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		edit func(*Observation)
+	}{
+		{"no UUID", func(o *Observation) { o.UUID = uuid.Nil }},
+		{"latitude", func(o *Observation) { o.Latitude = 91 }},
+		{"longitude", func(o *Observation) { o.Longitude = -181 }},
+		{"accuracy", func(o *Observation) { o.PositionalAccuracy = -1 }},
+		{"geoprivacy", func(o *Observation) { o.Geoprivacy = "secret" }},
+		{"field ID", func(o *Observation) {
+			o.ObservationFieldValuesAttributes = []ObservationFieldValue{{Value: "2"}}
+		}},
+	} {
+		obs := valid
+		tc.edit(&obs)
+		if err := obs.Validate(); !errors.Is(err, ErrInvalidObservation) {
+			t.Errorf("%s: Validate() error = %v, want ErrInvalidObservation", tc.name, err)
+		}
+	}
+
+	// Invalid observations aren't sent.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "test-token", "test-user-agent")
+	if _, err := client.CreateObservation(Observation{Latitude: 100}); !errors.Is(err, ErrInvalidObservation) {
+		t.Errorf("CreateObservation() of invalid observation error = %v, want ErrInvalidObservation", err)
+	}
 }
 
 func TestClient_UpdateObservation(t *testing.T) {
@@ -46,7 +129,7 @@ func TestClient_UpdateObservation(t *testing.T) {
 	client := NewClient(server.URL, "test-token", "test-user-agent")
 
 	obs := Observation{UUID: obsUUID}
-	if err := client.UpdateObservation(obs); err != nil {
+	if _, err := client.UpdateObservation(obs); err != nil {
 		t.Errorf("UpdateObservation() error = %v", err)
 	}
 }
@@ -71,3 +154,64 @@ func TestClient_DeleteObservation(t *testing.T) {
 		t.Errorf("DeleteObservation() error = %v", err)
 	}
 }
+
+func TestClient_UploadObservationMedia(t *testing.T) {
+	obsUUID := uuid.New()
+	var gotPath, gotType, gotName, gotObs string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm() error = %v", err)
+			return
+		}
+		for _, fh := range r.MultipartForm.File["file"] {
+			gotName, gotType = fh.Filename, fh.Header.Get("Content-Type")
+		}
+		for name, values := range r.MultipartForm.Value {
+			if strings.HasSuffix(name, "[observation_id]") {
+				gotObs = values[0]
+			}
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "test-token", "test-user-agent")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now)
+
+	dir := t.TempDir()
+	write := func(name, contents string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	photo := write("100.png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	sound := write("200.mp3", "\xff\xfb\x90\x64\x00\x00") // no ID3 tag, so typed by extension
+	text := write("300.mp3", "<html>not found</html>")
+
+	if err := client.UploadObservationPhoto(photo, "100", obsUUID); err != nil {
+		t.Fatalf("UploadObservationPhoto() error = %v", err)
+	}
+	if gotPath != "/observation_photos" || gotType != "image/png" || gotName != "ML100.png" || gotObs != obsUUID.String() {
+		t.Errorf("UploadObservationPhoto() sent %s %s %s for %s", gotPath, gotType, gotName, gotObs)
+	}
+	if err := client.UploadObservationSound(sound, "200", obsUUID); err != nil {
+		t.Fatalf("UploadObservationSound() error = %v", err)
+	}
+	if gotPath != "/observation_sounds" || gotType != "audio/mpeg" || gotName != "ML200.mp3" {
+		t.Errorf("UploadObservationSound() sent %s %s %s", gotPath, gotType, gotName)
+	}
+
+	if err := client.UploadObservationSound(text, "300", obsUUID); !errors.Is(err, ErrWrongMediaType) {
+		t.Errorf("UploadObservationSound() of HTML error = %v, want ErrWrongMediaType", err)
+	}
+	if err := client.UploadObservationSound(photo, "100", obsUUID); !errors.Is(err, ErrWrongMediaType) {
+		t.Errorf("UploadObservationSound() of a photo error = %v, want ErrWrongMediaType", err)
+	}
+	defer func(n int64) { MaxPhotoBytes = n }(MaxPhotoBytes)
+	MaxPhotoBytes = 10
+	if err := client.UploadObservationPhoto(photo, "100", obsUUID); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("UploadObservationPhoto() of large photo error = %v, want ErrTooLarge", err)
+	}
+}
@@ -0,0 +1,32 @@
+package inat
+
+import "strings"
+
+// DescriptionMarker separates the part of an observation description that
+// users may edit on iNaturalist from the part that birdsync appends to
+// when it resyncs an observation. AppendDescription never changes anything
+// above the marker, and it adds the marker the first time it appends.
+const DescriptionMarker = "--- Updated by birdsync ---"
+
+// AppendDescription returns the description desc with addition appended
+// below DescriptionMarker, preserving everything in desc, including any
+// edits made on iNaturalist. If desc already contains addition,
+// AppendDescription returns desc unchanged, so resyncing is idempotent.
+//
+// Use it to build the Description of an Observation passed to
+// Client.UpdateObservation, which otherwise replaces the description.
+func AppendDescription(desc, addition string) string {
+	if addition == "" || strings.Contains(desc, addition) {
+		return desc
+	}
+	if !strings.HasSuffix(addition, "\n") {
+		addition += "\n"
+	}
+	if desc != "" && !strings.HasSuffix(desc, "\n") {
+		desc += "\n"
+	}
+	if !strings.Contains(desc, DescriptionMarker) {
+		desc += DescriptionMarker + "\n"
+	}
+	return desc + addition
+}
@@ -0,0 +1,26 @@
+package inat
+
+import "testing"
+
+func TestAppendDescription(t *testing.T) {
+	const m = DescriptionMarker
+	testCases := []struct {
+		name     string
+		desc     string
+		addition string
+		want     string
+	}{
+		{"first append", "Created by birdsync\nMy edit", "Windy", "Created by birdsync\nMy edit\n" + m + "\nWindy\n"},
+		{"second append", "Created\n" + m + "\nWindy\n", "Cold", "Created\n" + m + "\nWindy\nCold\n"},
+		{"already present", "Created\n" + m + "\nWindy\n", "Windy", "Created\n" + m + "\nWindy\n"},
+		{"empty addition", "Created\n", "", "Created\n"},
+		{"empty description", "", "Windy\n", m + "\nWindy\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := AppendDescription(tc.desc, tc.addition); got != tc.want {
+				t.Errorf("AppendDescription(%q, %q) = %q, want %q", tc.desc, tc.addition, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package inat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommunityFields are the fields of an observation's community taxon,
+// for downloads that compare it with the taxon originally reported.
+// Don't modify this slice; copy it before appending more fields.
+var CommunityFields = []string{"community_taxon.all"}
+
+// DiscussionFields are the fields of an observation's identifications
+// and comments, in addition to CommunityFields.
+var DiscussionFields = []string{"community_taxon.all", "identifications.all", "comments.all"}
+
+// IdentificationsAndComments returns the identifications and comments on
+// the observation identified by its numeric ID or UUID, as GetObservation
+// does, in the order they were made. Identifications that their
+// identifiers have withdrawn or replaced aren't Current.
+func (c *Client) IdentificationsAndComments(id string) ([]Identification, []Comment, error) {
+	r, err := c.GetObservation(id, append([]string{"uuid"}, DiscussionFields...)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("IdentificationsAndComments: %w", err)
+	}
+	return r.Identifications, r.Comments, nil
+}
+
+// CommunityDisagrees reports whether the observation's community taxon,
+// which needs CommunityFields, differs from its own taxon, which the
+// observer chose. A subspecies of the observation's taxon doesn't differ,
+// and neither does an observation without a community taxon, since too
+// few people have identified it, or without a taxon. A coarser taxon,
+// like the genus, does differ: the community isn't sure of the species.
+func (r Result) CommunityDisagrees() bool {
+	name, taxon := r.CommunityTaxon.Name, r.Taxon.Name
+	if r.CommunityTaxon.ID == 0 || name == "" || r.Taxon.ID == 0 || taxon == "" {
+		return false
+	}
+	return !strings.EqualFold(name, taxon) &&
+		!(len(name) > len(taxon) && strings.EqualFold(name[:len(taxon)+1], taxon+" "))
+}
@@ -0,0 +1,68 @@
+package inat
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_IdentificationsAndComments(t *testing.T) {
+	// iNaturalist returns numeric user IDs, unlike the /users/me login.
+	const body = `{"total_results": 1, "results": [{
+		"uuid": "5b5e9b5e-0f0a-4a8e-9b0a-2f6b0d6a7c11",
+		"community_taxon": {"id": 8229, "name": "Corvus corax"},
+		"identifications": [
+			{"id": 1, "current": false, "category": "improving", "taxon": {"id": 8021, "name": "Corvus brachyrhynchos"}, "user": {"id": 42, "login": "me"}},
+			{"id": 2, "current": true, "category": "maverick", "taxon": {"id": 8229, "name": "Corvus corax"}, "user": {"id": 7, "login": "expert"}, "body": "Wedge-shaped tail"}
+		],
+		"comments": [{"id": 3, "body": "Listen to the call", "user": {"id": 7, "login": "expert"}}]
+	}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/observations" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+
+	ids, comments, err := client.IdentificationsAndComments("5b5e9b5e-0f0a-4a8e-9b0a-2f6b0d6a7c11")
+	if err != nil {
+		t.Fatalf("IdentificationsAndComments() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0].Current || !ids[1].Current || ids[1].User.ID != 7 || ids[1].Taxon.Name != "Corvus corax" {
+		t.Errorf("identifications = %+v, want a withdrawn one and a current Corvus corax by user 7", ids)
+	}
+	if len(comments) != 1 || comments[0].Body != "Listen to the call" || comments[0].User.Login != "expert" {
+		t.Errorf("comments = %+v, want 1 by expert", comments)
+	}
+}
+
+func TestResult_CommunityDisagrees(t *testing.T) {
+	for _, tt := range []struct {
+		community, taxon string
+		want             bool
+	}{
+		{"", "Corvus corax", false}, // no community taxon yet
+		{"Corvus corax", "", false}, // no taxon
+		{"Corvus corax", "Corvus corax", false},
+		{"Corvus corax", "corvus Corax", false},
+		{"Corvus corax principalis", "Corvus corax", false},
+		{"Corvus corax", "Corvus brachyrhynchos", true},
+		{"Corvus", "Corvus corax", true},
+		{"Corvus coraxx", "Corvus corax", true},
+	} {
+		r := Result{}
+		if tt.community != "" {
+			r.CommunityTaxon = Taxon{ID: 1, Name: tt.community}
+		}
+		if tt.taxon != "" {
+			r.Taxon = Taxon{ID: 2, Name: tt.taxon}
+		}
+		if got := r.CommunityDisagrees(); got != tt.want {
+			t.Errorf("community %q, taxon %q: CommunityDisagrees() = %v, want %v", tt.community, tt.taxon, got, tt.want)
+		}
+	}
+}
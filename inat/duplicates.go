@@ -0,0 +1,61 @@
+package inat
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// FindDuplicates downloads userID's observations and returns the groups of
+// two or more that birdsync created from the same eBird observation:
+// the same eBird submission ID and eBird scientific name in EBirdField
+// and EBirdScientificNameField. It only finds duplicates created by
+// birdsync (or by tools that set the same observation fields);
+// observations entered by hand aren't grouped, even if they're for the
+// same bird. Groups are sorted by submission ID and scientific name,
+// and each group is in download order.
+//
+// The results include the fields in DedupFields, plus created_at and
+// identifications_count for choosing which duplicate to keep.
+// Canceling ctx stops the download.
+func (c *Client) FindDuplicates(ctx context.Context, userID string) ([][]Result, error) {
+	log.Printf("Downloading observations for %s", userID)
+	results, err := c.download(ctx, observationQuery{userID: userID},
+		append(slices.Clone(DedupFields), "created_at", "identifications_count"))
+	if err != nil {
+		return nil, fmt.Errorf("FindDuplicates: %w", err)
+	}
+
+	type key struct{ submissionID, scientificName string }
+	groups := map[key][]Result{}
+	for _, r := range results {
+		k := key{
+			strings.TrimSpace(r.ObservationFieldValue(EBirdField)),
+			strings.TrimSpace(r.ObservationFieldValue(EBirdScientificNameField)),
+		}
+		if k.submissionID == "" || k.scientificName == "" {
+			continue // not created by birdsync
+		}
+		groups[k] = append(groups[k], r)
+	}
+	var keys []key
+	for k, rs := range groups {
+		if len(rs) > 1 {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b key) int {
+		return cmp.Or(
+			cmp.Compare(a.submissionID, b.submissionID),
+			cmp.Compare(a.scientificName, b.scientificName),
+		)
+	})
+	var dups [][]Result
+	for _, k := range keys {
+		dups = append(dups, groups[k])
+	}
+	return dups, nil
+}
@@ -0,0 +1,57 @@
+package inat_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Sajmani/birdsync/inat"
+	"github.com/Sajmani/birdsync/inat/inattest"
+)
+
+func TestClient_FindDuplicates(t *testing.T) {
+	synced := func(id int, submissionID, name string) inat.Result {
+		return inat.Result{ID: id, Ofvs: []inat.Ofv{
+			{FieldID: inat.EBirdField, Value: submissionID},
+			{FieldID: inat.EBirdScientificNameField, Value: name},
+		}}
+	}
+	results := []inat.Result{
+		synced(1, "S2", "Turdus migratorius"),
+		synced(2, "S1", "Corvus brachyrhynchos"),
+		synced(3, "S2", "Turdus migratorius"),
+		synced(4, "S1", "Zenaida macroura"),
+		synced(5, "S1", "Corvus brachyrhynchos"),
+		synced(6, "S2", "Turdus migratorius"),
+		{ID: 7, Taxon: inat.Taxon{Name: "Turdus migratorius"}}, // added by hand
+		{ID: 8, Taxon: inat.Taxon{Name: "Turdus migratorius"}},
+	}
+	server := inattest.NewServer(results)
+	defer server.Close()
+
+	client := inat.NewClient(server.URL, "test-token", "")
+	client.SetRequestLimits(0, 0) // don't slow down the test
+	dups, err := client.FindDuplicates(context.Background(), "testuser")
+	if err != nil {
+		t.Fatalf("FindDuplicates() error = %v", err)
+	}
+	var got [][]int
+	for _, group := range dups {
+		var ids []int
+		for _, r := range group {
+			ids = append(ids, r.ID)
+		}
+		got = append(got, ids)
+	}
+	want := [][]int{{2, 5}, {1, 3, 6}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("FindDuplicates() = %v, want %v", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.FindDuplicates(ctx, "testuser"); !errors.Is(err, context.Canceled) {
+		t.Errorf("FindDuplicates() with canceled context error = %v, want %v", err, context.Canceled)
+	}
+}
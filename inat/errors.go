@@ -0,0 +1,91 @@
+package inat
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBody is the most of a response body that an APIError keeps.
+const maxErrorBody = 512
+
+// APIError is an unsuccessful HTTP response from the iNaturalist API.
+// Client methods return it (wrapped) so that callers can branch on the
+// kind of failure; see IsThrottled and IsNotFound. A 401 response also
+// matches ErrUnauthorized, and a 404 response ErrNotFound, with errors.Is.
+type APIError struct {
+	StatusCode int    // like 404
+	Status     string // like "404 Not Found"
+	URL        string // of the request
+	Body       string // the start of the response, which may explain the error
+
+	// RetryAfter is how long iNaturalist asked the client to wait before
+	// trying again, from the Retry-After header, or zero.
+	RetryAfter time.Duration
+
+	// RateLimit has the response's rate limit headers: Retry-After and
+	// any starting with X-RateLimit-.
+	RateLimit http.Header
+}
+
+// newAPIError returns the APIError for resp, the response to req,
+// reading the start of its body.
+func newAPIError(req *http.Request, resp *http.Response, now time.Time) *APIError {
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		URL:        req.URL.String(),
+		Body:       strings.TrimSpace(string(b)),
+		RateLimit:  http.Header{},
+	}
+	e.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	for k, v := range resp.Header {
+		if k == "Retry-After" || strings.HasPrefix(k, "X-Ratelimit-") {
+			e.RateLimit[k] = v
+		}
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	if e.Body == "" {
+		return "bad HTTP status: " + e.Status
+	}
+	return "bad HTTP status: " + e.Status + ": " + e.Body
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// temporary reports whether the request may succeed if it's made again.
+func (e *APIError) temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// IsThrottled reports whether err means that the client made too many
+// requests: iNaturalist answered 429 Too Many Requests (even after the
+// client retried it; see MaxAttempts), or the client reached
+// DailyRequestLimit. Wait before making more requests.
+func IsThrottled(err error) bool {
+	var e *APIError
+	return errors.Is(err, ErrDailyLimit) ||
+		(errors.As(err, &e) && e.StatusCode == http.StatusTooManyRequests)
+}
+
+// IsNotFound reports whether err means that iNaturalist doesn't have
+// what was requested, or won't show it to the user: either the API
+// answered 404 Not Found, or a lookup found nothing. It's the same as
+// errors.Is(err, ErrNotFound).
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
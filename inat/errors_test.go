@@ -0,0 +1,71 @@
+package inat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAPIError(t *testing.T) {
+	defer func(orig int) { MaxAttempts = orig }(MaxAttempts)
+	MaxAttempts = 1 // don't retry the throttled and failed requests
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("X-Other", "ignored")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"status": %d}`, status)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+
+	for _, tt := range []struct {
+		status                                   int
+		throttled, notFound, unauthorized, retry bool
+	}{
+		{status: http.StatusTooManyRequests, throttled: true, retry: true},
+		{status: http.StatusNotFound, notFound: true},
+		{status: http.StatusUnauthorized, unauthorized: true},
+		{status: http.StatusBadRequest},
+		{status: http.StatusBadGateway, retry: true},
+	} {
+		status = tt.status
+		err := client.Ping(context.Background())
+		var e *APIError
+		if !errors.As(err, &e) {
+			t.Errorf("status %d: Ping() error = %v, want an APIError", tt.status, err)
+			continue
+		}
+		if e.StatusCode != tt.status || e.URL != server.URL+"/users/me?fields=id,login" {
+			t.Errorf("status %d: APIError = %+v, want status %d for %s/users/me", tt.status, e, tt.status, server.URL)
+		}
+		if want := `{"status": ` + strconv.Itoa(tt.status) + `}`; e.Body != want {
+			t.Errorf("status %d: Body = %q, want %q", tt.status, e.Body, want)
+		}
+		if e.RetryAfter != 30*time.Second || e.RateLimit.Get("X-RateLimit-Remaining") != "0" || e.RateLimit.Get("X-Other") != "" {
+			t.Errorf("status %d: RetryAfter = %v, RateLimit = %v; want 30s and X-RateLimit-Remaining only", tt.status, e.RetryAfter, e.RateLimit)
+		}
+		if got := IsThrottled(err); got != tt.throttled {
+			t.Errorf("status %d: IsThrottled() = %v, want %v", tt.status, got, tt.throttled)
+		}
+		if got := IsNotFound(err); got != tt.notFound {
+			t.Errorf("status %d: IsNotFound() = %v, want %v", tt.status, got, tt.notFound)
+		}
+		if got := errors.Is(err, ErrUnauthorized); got != tt.unauthorized {
+			t.Errorf("status %d: errors.Is(ErrUnauthorized) = %v, want %v", tt.status, got, tt.unauthorized)
+		}
+		if got := errors.As(err, new(retryableError)); got != tt.retry {
+			t.Errorf("status %d: retryable = %v, want %v", tt.status, got, tt.retry)
+		}
+	}
+	if err := fmt.Errorf("Sync: %w", ErrDailyLimit); !IsThrottled(err) {
+		t.Errorf("IsThrottled(%v) = false, want true", err)
+	}
+}
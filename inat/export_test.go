@@ -0,0 +1,4 @@
+package inat
+
+// PerPage is perPage, for external tests.
+const PerPage = perPage
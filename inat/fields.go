@@ -0,0 +1,81 @@
+package inat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// SetObservationFieldValue sets the observation field fieldID to value on
+// the observation obsUUID, which must already exist. iNaturalist replaces
+// the field's value if the observation already has one. Use it to record
+// provenance, such as a checklist URL, as structured data that searches
+// and other tools can read, rather than only in the description.
+func (c *Client) SetObservationFieldValue(obsUUID uuid.UUID, fieldID int, value string) error {
+	if fieldID <= 0 {
+		return fmt.Errorf("SetObservationFieldValue(%s, %d): %w: bad field ID", obsUUID, fieldID, ErrInvalidObservation)
+	}
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("SetObservationFieldValue(%s, %d): %w: empty value", obsUUID, fieldID, ErrInvalidObservation)
+	}
+	buf := &bytes.Buffer{}
+	err := json.NewEncoder(buf).Encode(struct {
+		ObservationFieldValue any `json:"observation_field_value"`
+	}{
+		struct {
+			ObservationID      uuid.UUID `json:"observation_id"`
+			ObservationFieldID int       `json:"observation_field_id"`
+			Value              string    `json:"value"`
+		}{obsUUID, fieldID, value},
+	})
+	if err != nil {
+		return fmt.Errorf("SetObservationFieldValue(%s, %d): %w", obsUUID, fieldID, err)
+	}
+	req, err := http.NewRequest("POST", c.baseURL+"/observation_field_values", buf)
+	if err != nil {
+		return fmt.Errorf("SetObservationFieldValue(%s, %d): %w", obsUUID, fieldID, err)
+	}
+	if _, err := c.roundTrip(req); err != nil {
+		return fmt.Errorf("SetObservationFieldValue(%s, %d): %w", obsUUID, fieldID, err)
+	}
+	log.Printf("Set field %d of %s to %q", fieldID, ObservationURL(obsUUID), value)
+	return nil
+}
+
+// ObservationFieldValues returns the observation field values of the
+// observation identified by its numeric ID or UUID, as GetObservation does.
+// Use Result.ObservationFieldValue to read one field from a Result
+// that's already been downloaded.
+func (c *Client) ObservationFieldValues(id string) ([]Ofv, error) {
+	r, err := c.GetObservation(id, "uuid", "ofvs.all")
+	if err != nil {
+		return nil, fmt.Errorf("ObservationFieldValues: %w", err)
+	}
+	return r.Ofvs, nil
+}
+
+// DownloadObservationsWithField is like DownloadObservations, but returns
+// just inatUserID's observations whose observation field named fieldName
+// (not its ID; see https://www.inaturalist.org/observation_fields) has
+// value, or any value if value is "". iNaturalist does the matching, so
+// finding the observations synced from one checklist takes a request or
+// two rather than a download of the user's entire history.
+func (c *Client) DownloadObservationsWithField(inatUserID, fieldName, value string, fields ...string) ([]Result, error) {
+	if strings.TrimSpace(fieldName) == "" {
+		return nil, fmt.Errorf("DownloadObservationsWithField(%s): empty field name", inatUserID)
+	}
+	log.Printf("Downloading observations for %s with %s=%s", inatUserID, fieldName, value)
+	oq := observationQuery{userID: inatUserID, filter: url.Values{"field:" + fieldName: {value}}}
+	results, err := c.download(context.Background(), oq, fields)
+	if err != nil {
+		return nil, fmt.Errorf("DownloadObservationsWithField(%s, %q, %q): %w", inatUserID, fieldName, value, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,58 @@
+package inat
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestClient_SetObservationFieldValue(t *testing.T) {
+	obsUUID := uuid.New()
+	var got struct {
+		ObservationFieldValue struct {
+			ObservationID      uuid.UUID `json:"observation_id"`
+			ObservationFieldID int       `json:"observation_field_id"`
+			Value              string    `json:"value"`
+		} `json:"observation_field_value"`
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != http.MethodPost || r.URL.Path != "/observation_field_values" {
+			t.Errorf("request = %s %s, want POST /observation_field_values", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+
+	url := "https://ebird.org/checklist/S123"
+	if err := client.SetObservationFieldValue(obsUUID, EBirdField, url); err != nil {
+		t.Fatalf("SetObservationFieldValue() error = %v", err)
+	}
+	ofv := got.ObservationFieldValue
+	if ofv.ObservationID != obsUUID || ofv.ObservationFieldID != EBirdField || ofv.Value != url {
+		t.Errorf("SetObservationFieldValue() sent %+v, want %s, %d, %s", ofv, obsUUID, EBirdField, url)
+	}
+
+	for _, tc := range []struct {
+		fieldID int
+		value   string
+	}{{0, url}, {EBirdField, " "}} {
+		if err := client.SetObservationFieldValue(obsUUID, tc.fieldID, tc.value); !errors.Is(err, ErrInvalidObservation) {
+			t.Errorf("SetObservationFieldValue(%d, %q) error = %v, want ErrInvalidObservation", tc.fieldID, tc.value, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("made %d requests, want 1", requests)
+	}
+}
@@ -2,22 +2,74 @@
 package inat
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
 	"log"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const dateFormat = "2006-01-02"
+
+// From https://www.inaturalist.org/pages/api+recommended+practices:
+// If using the API to fetch a lot of results, please use the highest supported per_page value.
+// For example you can get up to 200 observations in a single request,
+// which would be faster and more efficient than fetching the default 30 results at a time.
+const perPage = 200
+
+// Field lists for DownloadObservations. These presets cover common uses;
+// callers may pass any other list of fields instead.
+// Don't modify these slices; copy them before appending more fields.
+var (
+	// MinimalFields identifies observations and nothing else.
+	MinimalFields = []string{"id", "uuid"}
+
+	// DedupFields includes what's needed to match observations
+	// with eBird records: the taxon, when and where the bird was observed
+	// (including private locations; see Result.TrueLocation),
+	// the description, and observation field values.
+	DedupFields = []string{"id", "uuid", "taxon.all", "observed_on", "time_observed_at", "location",
+		"geoprivacy", "obscured", "private_location", "description", "ofvs.all"}
+
+	// FullFields includes every field. The results are large,
+	// so prefer a smaller list for big downloads.
+	FullFields = []string{"all"}
+)
+
+// DownloadWorkers is the most requests for pages of observations that
+// DownloadObservations makes at once. The client's rate limiter still
+// spaces out the requests, but overlapping them hides the time iNaturalist
+// takes to answer each one, which dominates downloads of large accounts.
+// One or less downloads one page at a time.
+var DownloadWorkers = 4
+
 // DownloadObservations downloads and returns all observations for inatUserID.
 // The dates d1 and d2 specify the start and end of the observation date range if nonzero.
-// The fields list specifies which fields are populated in the results.
-func (c *Client) DownloadObservations(inatUserID string, d1, d2 time.Time, fields ...string) []Result {
-	const dateFormat = "2006-01-02"
+// The fields list specifies which fields are populated in the results; it
+// always includes "id".
+// If a request fails, DownloadObservations returns the error and no results;
+// use DownloadObservationsResumable to resume large downloads instead.
+// To process observations as they arrive, use StreamObservations.
+//
+// Downloads are authenticated with the client's API token, so when the
+// client belongs to inatUserID, the results include the private locations
+// of observations whose locations are obscured to everyone else.
+//
+// The results are in increasing ID order. After the first page,
+// DownloadObservations splits the remaining IDs into up to DownloadWorkers
+// ranges and downloads them in parallel.
+func (c *Client) DownloadObservations(inatUserID string, d1, d2 time.Time, fields ...string) ([]Result, error) {
 	var d1str, d2str string
 	if !d1.IsZero() {
 		d1str = " after " + d1.Format(dateFormat)
@@ -26,63 +78,348 @@ func (c *Client) DownloadObservations(inatUserID string, d1, d2 time.Time, field
 		d2str = " before " + d2.Format(dateFormat)
 	}
 	log.Printf("Downloading observations for %s%s%s", inatUserID, d1str, d2str)
+	results, err := c.download(context.Background(), observationQuery{userID: inatUserID, d1: d1, d2: d2}, fields)
+	if err != nil {
+		return nil, fmt.Errorf("DownloadObservations(%s): %w", inatUserID, err)
+	}
+	return results, nil
+}
 
-	// From https://www.inaturalist.org/pages/api+recommended+practices:
-	// If using the API to fetch a lot of results, please use the highest supported per_page value.
-	// For example you can get up to 200 observations in a single request,
-	// which would be faster and more efficient than fetching the default 30 results at a time.
-	const perPage = 200
+// Quality grades of observations.
+const (
+	ResearchGrade = "research"
+	NeedsID       = "needs_id"
+	Casual        = "casual"
+)
 
-	var results []Result
-	var totalResults int
-	for page := 1; ; page++ {
-		u, err := url.Parse(c.baseURL + "/observations")
-		if err != nil {
-			log.Fatal(err)
+// ObservationFilter restricts DownloadFilteredObservations to some
+// observations. The zero filter matches every observation, of any taxon.
+type ObservationFilter struct {
+	QualityGrades []string // ResearchGrade, NeedsID, or Casual; any if empty
+	Captive       *bool    // captive or cultivated if true, wild if false, either if nil
+	Verifiable    *bool    // research grade or needs ID if true, casual if false, either if nil
+
+	// IconicTaxa, like "Aves", and TaxonIDs, like a family's, restrict
+	// the observations to those taxa and their descendants. Observations
+	// must match both if both are set.
+	IconicTaxa []string
+	TaxonIDs   []int
+
+	// UpdatedSince, if nonzero, restricts the observations to those
+	// created or changed since then, for refreshing an earlier download
+	// with MergeUpdates. iNaturalist doesn't report deleted observations.
+	UpdatedSince time.Time
+}
+
+// values returns the search parameters for f.
+func (f ObservationFilter) values() (url.Values, error) {
+	v := url.Values{}
+	for _, g := range f.QualityGrades {
+		if g != ResearchGrade && g != NeedsID && g != Casual {
+			return nil, fmt.Errorf("unknown quality grade %q", g)
 		}
-		q := u.Query()
-		q.Set("user_id", inatUserID)
-		q.Set("page", strconv.Itoa(page))
-		q.Set("per_page", strconv.Itoa(perPage))
-		if !d1.IsZero() {
-			q.Set("d1", d1.Format(dateFormat))
+	}
+	if len(f.QualityGrades) > 0 {
+		v.Set("quality_grade", strings.Join(f.QualityGrades, ","))
+	}
+	if f.Captive != nil {
+		v.Set("captive", strconv.FormatBool(*f.Captive))
+	}
+	if f.Verifiable != nil {
+		v.Set("verifiable", strconv.FormatBool(*f.Verifiable))
+	}
+	if len(f.IconicTaxa) > 0 {
+		v.Set("iconic_taxa", strings.Join(f.IconicTaxa, ","))
+	}
+	if len(f.TaxonIDs) > 0 {
+		ids := make([]string, len(f.TaxonIDs))
+		for i, id := range f.TaxonIDs {
+			ids[i] = strconv.Itoa(id)
 		}
-		if !d2.IsZero() {
-			q.Set("d2", d2.Format(dateFormat))
+		v.Set("taxon_id", strings.Join(ids, ","))
+	}
+	if !f.UpdatedSince.IsZero() {
+		v.Set("updated_since", f.UpdatedSince.Format(time.RFC3339))
+	}
+	return v, nil
+}
+
+// MergeUpdates returns the observations in previous, an earlier download
+// in increasing ID order, with those in updated replacing the ones with
+// the same IDs and adding the rest. The results are in increasing ID
+// order. Both must include "id" in their fields.
+//
+// To download only what changed since an earlier download, record when it
+// started, then pass that time as ObservationFilter.UpdatedSince:
+//
+//	updated, err := c.DownloadFilteredObservations(user, d1, d2,
+//		inat.ObservationFilter{UpdatedSince: lastRun}, fields...)
+//	results = inat.MergeUpdates(results, updated)
+func MergeUpdates(previous, updated []Result) []Result {
+	byID := make(map[int]Result, len(updated))
+	for _, r := range updated {
+		byID[r.ID] = r
+	}
+	results := make([]Result, 0, len(previous)+len(updated))
+	for _, r := range previous {
+		if u, ok := byID[r.ID]; ok {
+			r = u
+			delete(byID, r.ID)
 		}
-		if len(fields) > 0 {
-			q.Set("fields", strings.Join(fields, ","))
+		results = append(results, r)
+	}
+	for _, r := range updated {
+		if _, ok := byID[r.ID]; ok {
+			results = append(results, r)
 		}
-		u.RawQuery = q.Encode()
+	}
+	slices.SortStableFunc(results, func(a, b Result) int { return a.ID - b.ID })
+	return results
+}
 
-		req, err := http.NewRequest("GET", u.String(), nil)
-		if err != nil {
-			log.Fatal(err)
-		}
-		body, err := c.roundTrip(req)
+// DownloadFilteredObservations is like DownloadObservations, but it
+// downloads only the observations that match f. For example, to compare
+// eBird with research-grade wild observations:
+//
+//	wild := false
+//	results, err := c.DownloadFilteredObservations(user, d1, d2,
+//		inat.ObservationFilter{QualityGrades: []string{inat.ResearchGrade}, Captive: &wild})
+func (c *Client) DownloadFilteredObservations(inatUserID string, d1, d2 time.Time, f ObservationFilter, fields ...string) ([]Result, error) {
+	filter, err := f.values()
+	if err != nil {
+		return nil, fmt.Errorf("DownloadFilteredObservations(%s): %w", inatUserID, err)
+	}
+	log.Printf("Downloading observations for %s matching %s", inatUserID, filter.Encode())
+	results, err := c.download(context.Background(), observationQuery{userID: inatUserID, d1: d1, d2: d2, filter: filter}, fields)
+	if err != nil {
+		return nil, fmt.Errorf("DownloadFilteredObservations(%s): %w", inatUserID, err)
+	}
+	return results, nil
+}
+
+// observationQuery selects observations to download.
+type observationQuery struct {
+	userID string
+	d1, d2 time.Time  // if nonzero
+	filter url.Values // more search parameters, like "field:Name"
+}
+
+// download implements DownloadObservations for the observations that match oq.
+func (c *Client) download(ctx context.Context, oq observationQuery, fields []string) ([]Result, error) {
+	start := c.now()
+	first, err := c.observationsBetween(ctx, oq, 0, 0, fields)
+	if err != nil {
+		return nil, err
+	}
+	results := first.Results
+	if len(results) > 0 && len(results) < first.TotalResults {
+		log.Printf("Downloaded %d of %d observations", len(results), first.TotalResults)
+		rest, err := c.downloadRest(ctx, oq, results[len(results)-1].ID, len(results), first.TotalResults, fields)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
+		results = append(results, rest...)
+	}
+	log.Printf("Downloaded %d observations in %s", len(results), c.now().Sub(start).Round(time.Second))
+	return results, nil
+}
 
-		var observations Observations
-		err = json.Unmarshal([]byte(body), &observations)
+// downloadRest downloads the observations with IDs above cursor, after
+// done of total observations have been downloaded. It splits the IDs into
+// ranges for up to DownloadWorkers workers. The ranges are equal spans of
+// IDs, so they may not have equal numbers of observations.
+func (c *Client) downloadRest(ctx context.Context, oq observationQuery, cursor, done, total int, fields []string) ([]Result, error) {
+	type idRange struct{ above, below int } // below is 0 for the last range
+	ranges := []idRange{{cursor, 0}}
+	if workers := min(DownloadWorkers, (total-done+perPage-1)/perPage); workers > 1 {
+		last, err := c.lastObservationID(ctx, oq)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
+		if last-cursor >= workers {
+			ranges = nil
+			for i := range workers {
+				ranges = append(ranges, idRange{cursor + (last-cursor)*i/workers, cursor + (last-cursor)*(i+1)/workers + 1})
+			}
+			ranges[workers-1].below = 0 // include any observations added since
+		}
+	}
+
+	results := make([][]Result, len(ranges))
+	errs := make([]error, len(ranges))
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Bool // stops the other workers
+		mu     sync.Mutex  // serializes progress
+	)
+	for i, r := range ranges {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page, err := range c.pagesBetween(ctx, oq, r.above, r.below, fields) {
+				if err != nil {
+					errs[i] = err
+					failed.Store(true)
+					return
+				}
+				results[i] = append(results[i], page.Results...)
+				mu.Lock()
+				done += len(page.Results)
+				log.Printf("Downloaded %d of %d observations", done, total)
+				mu.Unlock()
+				if failed.Load() {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return slices.Concat(results...), nil
+}
 
-		if observations.TotalResults == 0 {
-			break
+// StreamObservations is like DownloadObservations, but yields the
+// observations as each page arrives instead of collecting them, so callers
+// can start on them before the download finishes without holding them all
+// in memory. The download stops when the caller stops iterating.
+// If a request fails, the sequence yields the error and ends.
+// It requests one page at a time.
+//
+// Observations are downloaded in increasing ID order using id_above cursors
+// rather than page numbers, since iNaturalist won't return results past
+// the first 10,000 by page.
+func (c *Client) StreamObservations(inatUserID string, d1, d2 time.Time, fields ...string) iter.Seq2[Result, error] {
+	return func(yield func(Result, error) bool) {
+		n, totalResults := 0, 0
+		oq := observationQuery{userID: inatUserID, d1: d1, d2: d2}
+		for page, err := range c.pagesBetween(context.Background(), oq, 0, 0, fields) {
+			if err != nil {
+				yield(Result{}, err)
+				return
+			}
+			if totalResults == 0 { // first page
+				totalResults = page.TotalResults
+			}
+			for _, r := range page.Results {
+				if !yield(r, nil) {
+					return
+				}
+			}
+			n += len(page.Results)
+			log.Printf("Downloaded %d of %d observations", n, totalResults)
 		}
-		if totalResults == 0 { // first loop
-			totalResults = observations.TotalResults
+	}
+}
+
+// pagesBetween yields the pages of the observations that match oq with IDs
+// above above and, if below is nonzero, below below, in increasing ID order,
+// until there are no more or a request fails.
+func (c *Client) pagesBetween(ctx context.Context, oq observationQuery, above, below int, fields []string) iter.Seq2[Observations, error] {
+	return func(yield func(Observations, error) bool) {
+		for {
+			page, err := c.observationsBetween(ctx, oq, above, below, fields)
+			if err != nil {
+				yield(Observations{}, err)
+				return
+			}
+			if len(page.Results) == 0 || !yield(page, nil) {
+				return
+			}
+			above = page.Results[len(page.Results)-1].ID
 		}
-		results = append(results, observations.Results...)
-		log.Printf("Downloaded %d of %d observations", len(results), totalResults)
-		if len(results) >= totalResults {
-			break
+	}
+}
+
+// observationsBetween returns the first page of the observations that
+// match oq with IDs above above and, if below is nonzero, below below, in increasing
+// ID order. Its TotalResults counts just those observations.
+func (c *Client) observationsBetween(ctx context.Context, oq observationQuery, above, below int, fields []string) (Observations, error) {
+	q := url.Values{}
+	q.Set("per_page", strconv.Itoa(perPage))
+	q.Set("order_by", "id")
+	q.Set("order", "asc")
+	if above > 0 {
+		q.Set("id_above", strconv.Itoa(above))
+	}
+	if below > 0 {
+		q.Set("id_below", strconv.Itoa(below))
+	}
+	// The cursor needs observation IDs, so make sure they're included.
+	if len(fields) > 0 && !slices.Contains(fields, "id") {
+		fields = append(slices.Clip(fields), "id")
+	}
+	if len(fields) > 0 {
+		q.Set("fields", strings.Join(fields, ","))
+	}
+	what := fmt.Sprintf("observations above %d", above)
+	if below > 0 {
+		what += fmt.Sprintf(" and below %d", below)
+	}
+	observations, err := c.getObservations(ctx, oq, q)
+	if err != nil {
+		return Observations{}, fmt.Errorf("%s: %w", what, err)
+	}
+	for _, r := range observations.Results {
+		if r.ID <= above || (below > 0 && r.ID >= below) {
+			return Observations{}, fmt.Errorf("%s: got observation %d out of order", what, r.ID)
 		}
+		above = r.ID
 	}
-	return results
+	return observations, nil
+}
+
+// lastObservationID returns the highest ID of the observations that match
+// oq, or 0 if there are none.
+func (c *Client) lastObservationID(ctx context.Context, oq observationQuery) (int, error) {
+	q := url.Values{}
+	q.Set("per_page", "1")
+	q.Set("order_by", "id")
+	q.Set("order", "desc")
+	q.Set("fields", "id")
+	observations, err := c.getObservations(ctx, oq, q)
+	if err != nil {
+		return 0, fmt.Errorf("last observation: %w", err)
+	}
+	if len(observations.Results) == 0 {
+		return 0, nil
+	}
+	return observations.Results[0].ID, nil
+}
+
+// getObservations requests the observations that match oq,
+// with the other query parameters in q.
+func (c *Client) getObservations(ctx context.Context, oq observationQuery, q url.Values) (Observations, error) {
+	u, err := url.Parse(c.baseURL + "/observations")
+	if err != nil {
+		return Observations{}, err
+	}
+	q.Set("user_id", oq.userID)
+	if !oq.d1.IsZero() {
+		q.Set("d1", oq.d1.Format(dateFormat))
+	}
+	if !oq.d2.IsZero() {
+		q.Set("d2", oq.d2.Format(dateFormat))
+	}
+	for k, v := range oq.filter {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return Observations{}, err
+	}
+	body, err := c.roundTrip(req)
+	if err != nil {
+		return Observations{}, err
+	}
+	var observations Observations
+	if err := json.Unmarshal([]byte(body), &observations); err != nil {
+		return Observations{}, fmt.Errorf("parsing response: %w", err)
+	}
+	return observations, nil
 }
 
 func TestObservation() Observation {
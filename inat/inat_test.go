@@ -2,53 +2,138 @@ package inat
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestDownloadObservations(t *testing.T) {
+	// More observations than iNaturalist returns by page number,
+	// with IDs 3, 6, 9, ... like a user's observations among others'.
+	const total = 10050
+	var (
+		mu                 sync.Mutex
+		requests, inFlight int
+		maxInFlight        int
+	)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		page := r.URL.Query().Get("page")
-		switch page {
-		case "1":
-			resp := Observations{
-				TotalResults: 2,
-				Page:         1,
-				PerPage:      1,
-				Results:      []Result{{Description: "obs 1"}},
-			}
-			json.NewEncoder(w).Encode(resp)
-		case "2":
-			resp := Observations{
-				TotalResults: 2,
-				Page:         2,
-				PerPage:      1,
-				Results:      []Result{{Description: "obs 2"}},
+		mu.Lock()
+		requests++
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(time.Millisecond) // let requests overlap
+
+		q := r.URL.Query()
+		if q.Get("page") != "" || q.Get("order_by") != "id" {
+			t.Errorf("query = %s, want order_by=id and no page", r.URL.RawQuery)
+		}
+		if q.Get("order") == "desc" {
+			json.NewEncoder(w).Encode(Observations{TotalResults: total, Results: []Result{{ID: 3 * total}}})
+			return
+		}
+		above, _ := strconv.Atoi(q.Get("id_above"))
+		below, _ := strconv.Atoi(q.Get("id_below"))
+		if below == 0 {
+			below = 3*total + 1
+		}
+		var resp Observations
+		for id := (above/3 + 1) * 3; id < below && id <= 3*total; id += 3 {
+			resp.TotalResults++
+			if len(resp.Results) < perPage {
+				resp.Results = append(resp.Results, Result{ID: id, Description: fmt.Sprintf("obs %d", id/3)})
 			}
-			json.NewEncoder(w).Encode(resp)
-		default:
-			resp := Observations{
-				TotalResults: 2,
-				Page:         3,
-				PerPage:      1,
-				Results:      []Result{},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+	defer func(n int) { DownloadWorkers = n }(DownloadWorkers)
+
+	client := NewClient(server.URL, "", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+	for _, workers := range []int{1, 4} {
+		DownloadWorkers = workers
+		requests, maxInFlight = 0, 0
+		results, err := client.DownloadObservations("testuser", time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("DownloadObservations() with %d workers error = %v", workers, err)
+		}
+		if len(results) != total {
+			t.Fatalf("DownloadObservations() with %d workers got %d results, want %d", workers, len(results), total)
+		}
+		for i, r := range results {
+			if want := fmt.Sprintf("obs %d", i+1); r.Description != want {
+				t.Fatalf("DownloadObservations() with %d workers: results[%d] = %s, want %s", workers, i, r.Description, want)
 			}
-			json.NewEncoder(w).Encode(resp)
 		}
+		if workers == 1 && (requests != total/perPage+2 || maxInFlight != 1) {
+			t.Errorf("DownloadObservations() with 1 worker made %d requests, %d at once; want %d, 1 at once", requests, maxInFlight, total/perPage+2)
+		}
+		if workers > 1 && maxInFlight < 2 {
+			t.Errorf("DownloadObservations() with %d workers made %d requests at once, want more", workers, maxInFlight)
+		}
+	}
+}
+
+func TestDownloadObservationsFields(t *testing.T) {
+	var gotFields string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotFields = r.URL.Query().Get("fields")
+		json.NewEncoder(w).Encode(Observations{})
 	}))
 	defer server.Close()
 
 	client := NewClient(server.URL, "", "")
-	results := client.DownloadObservations("testuser", time.Time{}, time.Time{})
-	if len(results) != 2 {
-		t.Errorf("Expected 2 results, got %d", len(results))
+	if _, err := client.DownloadObservations("testuser", time.Time{}, time.Time{}, DedupFields...); err != nil {
+		t.Fatalf("DownloadObservations() error = %v", err)
 	}
-	if results[0].Description != "obs 1" {
-		t.Errorf("Expected obs 1, got %s", results[0].Description)
+	if want := "id,uuid,taxon.all,observed_on,time_observed_at,location,geoprivacy,obscured,private_location,description,ofvs.all"; gotFields != want {
+		t.Errorf("fields = %q, want %q", gotFields, want)
 	}
-	if results[1].Description != "obs 2" {
-		t.Errorf("Expected obs 2, got %s", results[1].Description)
+}
+
+func TestResult_TrueLocation(t *testing.T) {
+	const body = `{"total_results": 3, "results": [
+		{"id": 1, "location": "37.123,-122.123"},
+		{"id": 2, "location": "37.2,-122.2", "obscured": true, "geoprivacy": "obscured", "private_location": "37.123,-122.123"},
+		{"id": 3, "location": "37.2,-122.2", "obscured": true, "private_geojson": {"type": "Point", "coordinates": [-122.123, 37.123]}}
+	]}`
+	var observations Observations
+	if err := json.Unmarshal([]byte(body), &observations); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range observations.Results {
+		if got, want := r.TrueLocation(), "37.123,-122.123"; got != want {
+			t.Errorf("observation %d: TrueLocation() = %q, want %q", r.ID, got, want)
+		}
+	}
+}
+
+func TestDownloadObservationsError(t *testing.T) {
+	defer func(n, workers int) { MaxAttempts, DownloadWorkers = n, workers }(MaxAttempts, DownloadWorkers)
+	MaxAttempts, DownloadWorkers = 1, 1
+	for _, handler := range []http.HandlerFunc{
+		func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadRequest) },
+		func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("not JSON")) },
+		func(w http.ResponseWriter, r *http.Request) { // ignores id_above
+			json.NewEncoder(w).Encode(Observations{TotalResults: 2, Results: []Result{{ID: 1}}})
+		},
+	} {
+		server := httptest.NewServer(handler)
+		client := NewClient(server.URL, "", "")
+		results, err := client.DownloadObservations("testuser", time.Time{}, time.Time{})
+		if err == nil || results != nil {
+			t.Errorf("DownloadObservations() = %v, %v; want an error", results, err)
+		}
+		server.Close()
 	}
 }
@@ -0,0 +1,180 @@
+// Package inattest provides a fake iNaturalist API for tests and local
+// experiments with inat.Client.
+package inattest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sajmani/birdsync/inat"
+)
+
+// A Server is a fake iNaturalist API.
+// It serves a fixed set of observations. Requests that would change
+// them succeed without changing anything, and the server records them.
+//
+// To preview what a sync would do without touching a real account,
+// point a Client at the server and run it in dry-run mode:
+//
+//	server := inattest.NewServer(observations)
+//	defer server.Close()
+//	client := inat.NewClient(server.URL, "any-token", userAgent)
+//	client.SetRequestLimits(0, 0) // the server doesn't need them
+//	// ... sync with client, then check server.Mutations() is empty.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	observations []inat.Result
+	mutations    []string // "METHOD /path"
+}
+
+// NewServer starts a Server serving observations.
+// The caller must call Close when done.
+//
+// The server supports GET /observations (with page and per_page, or
+// id_above, and the quality_grade, captive, verifiable, iconic_taxa,
+// taxon_id, and updated_since filters),
+// GET /users/me, and GET /taxa, which never finds any taxa.
+// It rejects requests without an Authorization header, as iNaturalist
+// does for authenticated requests. It answers every POST, PUT, and
+// DELETE request with success and records it in Mutations.
+func NewServer(observations []inat.Result) *Server {
+	s := &Server{observations: observations}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Mutations returns the POST, PUT, and DELETE requests the server has
+// received, as "METHOD /path" strings.
+func (s *Server) Mutations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.mutations)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" {
+		http.Error(w, "401 Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		s.mu.Lock()
+		s.mutations = append(s.mutations, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+		w.Write([]byte("{}"))
+		return
+	}
+	switch {
+	case r.URL.Path == "/observations":
+		json.NewEncoder(w).Encode(s.page(r))
+	case r.URL.Path == "/users/me":
+		w.Write([]byte(`{"total_results":1,"results":[{"id":1,"login":"test"}]}`))
+	case r.URL.Path == "/taxa" || strings.HasPrefix(r.URL.Path, "/taxa/"):
+		json.NewEncoder(w).Encode(inat.Taxa{})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// page returns the page of observations requested by r.
+func (s *Server) page(r *http.Request) inat.Observations {
+	q := r.URL.Query()
+	perPage, err := strconv.Atoi(q.Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 30
+	}
+	results := s.observations
+	page := 1
+	if id, u := q.Get("id"), q.Get("uuid"); id != "" || u != "" {
+		results = nil
+		for _, r := range s.observations {
+			if (id != "" && strconv.Itoa(r.ID) == id) || (u != "" && r.UUID.String() == u) {
+				results = append(results, r)
+			}
+		}
+	}
+	for k := range q {
+		name, ok := strings.CutPrefix(k, "field:")
+		if !ok {
+			continue
+		}
+		value := q.Get(k)
+		results = slices.DeleteFunc(slices.Clone(results), func(r inat.Result) bool {
+			return !slices.ContainsFunc(r.Ofvs, func(ofv inat.Ofv) bool {
+				return ofv.Name == name && (value == "" || ofv.Value == value)
+			})
+		})
+	}
+	if grades := q.Get("quality_grade"); grades != "" {
+		results = slices.DeleteFunc(slices.Clone(results), func(r inat.Result) bool {
+			return !slices.Contains(strings.Split(grades, ","), r.QualityGrade)
+		})
+	}
+	if captive, err := strconv.ParseBool(q.Get("captive")); err == nil {
+		results = slices.DeleteFunc(slices.Clone(results), func(r inat.Result) bool {
+			return r.Captive != captive
+		})
+	}
+	if verifiable, err := strconv.ParseBool(q.Get("verifiable")); err == nil {
+		results = slices.DeleteFunc(slices.Clone(results), func(r inat.Result) bool {
+			return (r.QualityGrade == inat.ResearchGrade || r.QualityGrade == inat.NeedsID) != verifiable
+		})
+	}
+	if iconic := q.Get("iconic_taxa"); iconic != "" {
+		results = slices.DeleteFunc(slices.Clone(results), func(r inat.Result) bool {
+			return !slices.Contains(strings.Split(iconic, ","), r.Taxon.IconicTaxonName)
+		})
+	}
+	if ids := q.Get("taxon_id"); ids != "" {
+		results = slices.DeleteFunc(slices.Clone(results), func(r inat.Result) bool {
+			for _, id := range strings.Split(ids, ",") {
+				if strconv.Itoa(r.Taxon.ID) == id || slices.ContainsFunc(r.Taxon.AncestorIDs, func(a int) bool {
+					return strconv.Itoa(a) == id
+				}) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+	if since, err := time.Parse(time.RFC3339, q.Get("updated_since")); err == nil {
+		results = slices.DeleteFunc(slices.Clone(results), func(r inat.Result) bool {
+			updated, err := time.Parse(time.RFC3339, r.UpdatedAt)
+			return err != nil || updated.Before(since)
+		})
+	}
+	if q.Get("order_by") == "id" {
+		above, _ := strconv.Atoi(q.Get("id_above"))
+		below, _ := strconv.Atoi(q.Get("id_below"))
+		all := results
+		results = nil
+		for _, r := range all {
+			if r.ID > above && (below == 0 || r.ID < below) {
+				results = append(results, r)
+			}
+		}
+		slices.SortFunc(results, func(a, b inat.Result) int { return a.ID - b.ID })
+		if q.Get("order") == "desc" {
+			slices.Reverse(results)
+		}
+	}
+	if p, err := strconv.Atoi(q.Get("page")); err == nil && p > 0 {
+		page = p
+	}
+	total := len(results)
+	start := min((page-1)*perPage, total)
+	end := min(start+perPage, total)
+	return inat.Observations{
+		TotalResults: total,
+		Page:         page,
+		PerPage:      perPage,
+		Results:      results[start:end],
+	}
+}
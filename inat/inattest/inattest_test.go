@@ -0,0 +1,32 @@
+package inattest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Sajmani/birdsync/inat"
+	"github.com/google/uuid"
+)
+
+func TestServer(t *testing.T) {
+	observations := []inat.Result{{ID: 1, Description: "obs 1"}, {ID: 2, Description: "obs 2"}}
+	server := NewServer(observations)
+	defer server.Close()
+
+	client := inat.NewClient(server.URL, "test-token", "")
+	client.SetRequestLimits(0, 0) // don't slow down the test
+	if err := client.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	results, err := client.DownloadObservations("testuser", time.Time{}, time.Time{})
+	if err != nil || len(results) != 2 || results[1].Description != "obs 2" {
+		t.Errorf("DownloadObservations() = %+v, want both observations", results)
+	}
+	if _, err := client.UpdateObservation(inat.Observation{UUID: uuid.New()}); err != nil {
+		t.Errorf("UpdateObservation() error = %v", err)
+	}
+	if got := server.Mutations(); len(got) != 1 || got[0][:4] != "PUT " {
+		t.Errorf("Mutations() = %v, want one PUT", got)
+	}
+}
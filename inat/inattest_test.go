@@ -0,0 +1,221 @@
+package inat_test
+
+// These tests run Client methods against an inattest.Server. They're
+// external tests, since inattest imports inat.
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Sajmani/birdsync/inat"
+	"github.com/Sajmani/birdsync/inat/inattest"
+	"github.com/google/uuid"
+)
+
+func TestClient_GetObservation(t *testing.T) {
+	u := uuid.New()
+	server := inattest.NewServer([]inat.Result{
+		{ID: 1, UUID: uuid.New(), Description: "obs 1"},
+		{ID: 2, UUID: u, Description: "obs 2"},
+	})
+	defer server.Close()
+	client := inat.NewClient(server.URL, "test-token", "")
+	client.SetRequestLimits(0, 0) // don't slow down the test
+
+	for _, id := range []string{"2", u.String(), " " + u.String() + " "} {
+		r, err := client.GetObservation(id)
+		if err != nil || r.Description != "obs 2" {
+			t.Errorf("GetObservation(%q) = %+v, %v; want obs 2", id, r, err)
+		}
+	}
+	if _, err := client.GetObservation("3"); !errors.Is(err, inat.ErrNotFound) {
+		t.Errorf("GetObservation(3) error = %v, want inat.ErrNotFound", err)
+	}
+	if _, err := client.GetObservation("obs"); err == nil || errors.Is(err, inat.ErrNotFound) {
+		t.Errorf("GetObservation(obs) error = %v, want a bad ID error", err)
+	}
+}
+
+func TestClient_ObservationFieldValues(t *testing.T) {
+	server := inattest.NewServer([]inat.Result{{ID: 1, UUID: uuid.New(), Ofvs: []inat.Ofv{
+		{FieldID: inat.EBirdField, Value: "S123"},
+		{FieldID: inat.CountField, Value: "2"},
+	}}})
+	defer server.Close()
+	client := inat.NewClient(server.URL, "test-token", "")
+	client.SetRequestLimits(0, 0) // don't slow down the test
+
+	ofvs, err := client.ObservationFieldValues("1")
+	if err != nil {
+		t.Fatalf("ObservationFieldValues() error = %v", err)
+	}
+	if r := (inat.Result{Ofvs: ofvs}); len(ofvs) != 2 || r.ObservationFieldValue(inat.EBirdField) != "S123" {
+		t.Errorf("ObservationFieldValues() = %+v, want the eBird and count fields", ofvs)
+	}
+	if _, err := client.ObservationFieldValues("2"); !errors.Is(err, inat.ErrNotFound) {
+		t.Errorf("ObservationFieldValues(2) error = %v, want inat.ErrNotFound", err)
+	}
+}
+
+func TestClient_DownloadObservationsWithField(t *testing.T) {
+	synced := func(id int, submissionID string) inat.Result {
+		return inat.Result{ID: id, Ofvs: []inat.Ofv{{FieldID: inat.EBirdField, Name: "eBird Checklist ID", Value: submissionID}}}
+	}
+	server := inattest.NewServer([]inat.Result{synced(1, "S1"), synced(2, "S2"), synced(3, "S1"), {ID: 4}})
+	defer server.Close()
+	client := inat.NewClient(server.URL, "test-token", "")
+	client.SetRequestLimits(0, 0) // don't slow down the test
+
+	for _, tc := range []struct {
+		value string
+		want  []int
+	}{
+		{"S1", []int{1, 3}},
+		{"S2", []int{2}},
+		{"S3", nil},
+		{"", []int{1, 2, 3}},
+	} {
+		results, err := client.DownloadObservationsWithField("testuser", "eBird Checklist ID", tc.value, inat.DedupFields...)
+		if err != nil {
+			t.Fatalf("DownloadObservationsWithField(%q) error = %v", tc.value, err)
+		}
+		var got []int
+		for _, r := range results {
+			got = append(got, r.ID)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("DownloadObservationsWithField(%q) = %v, want %v", tc.value, got, tc.want)
+		}
+	}
+	if _, err := client.DownloadObservationsWithField("testuser", "", "S1"); err == nil {
+		t.Error("DownloadObservationsWithField() with no field name succeeded, want error")
+	}
+}
+
+func TestDownloadFilteredObservations(t *testing.T) {
+	server := inattest.NewServer([]inat.Result{
+		{ID: 1, QualityGrade: inat.ResearchGrade, Taxon: inat.Taxon{ID: 8229, AncestorIDs: []int{3, 7823}, IconicTaxonName: "Aves"}},
+		{ID: 2, QualityGrade: inat.ResearchGrade, Captive: true, Taxon: inat.Taxon{ID: 12727, AncestorIDs: []int{3, 12705}, IconicTaxonName: "Aves"}},
+		{ID: 3, QualityGrade: inat.NeedsID, Taxon: inat.Taxon{ID: 47219, IconicTaxonName: "Insecta"}},
+		{ID: 4, QualityGrade: inat.Casual},
+	})
+	defer server.Close()
+	client := inat.NewClient(server.URL, "test-token", "")
+	client.SetRequestLimits(0, 0) // don't slow down the test
+
+	yes, no := true, false
+	for _, tt := range []struct {
+		filter inat.ObservationFilter
+		want   []int
+	}{
+		{inat.ObservationFilter{}, []int{1, 2, 3, 4}},
+		{inat.ObservationFilter{QualityGrades: []string{inat.ResearchGrade}, Captive: &no}, []int{1}},
+		{inat.ObservationFilter{QualityGrades: []string{inat.ResearchGrade, inat.NeedsID}}, []int{1, 2, 3}},
+		{inat.ObservationFilter{Captive: &yes}, []int{2}},
+		{inat.ObservationFilter{Verifiable: &no}, []int{4}},
+		{inat.ObservationFilter{IconicTaxa: []string{"Aves"}}, []int{1, 2}},
+		{inat.ObservationFilter{IconicTaxa: []string{"Aves", "Insecta"}}, []int{1, 2, 3}},
+		{inat.ObservationFilter{TaxonIDs: []int{7823}}, []int{1}},           // Corvidae
+		{inat.ObservationFilter{TaxonIDs: []int{7823, 47219}}, []int{1, 3}}, // or a honey bee
+		{inat.ObservationFilter{IconicTaxa: []string{"Aves"}, TaxonIDs: []int{12705}}, []int{2}},
+	} {
+		results, err := client.DownloadFilteredObservations("testuser", time.Time{}, time.Time{}, tt.filter, "id")
+		if err != nil {
+			t.Fatalf("DownloadFilteredObservations(%+v) error = %v", tt.filter, err)
+		}
+		var got []int
+		for _, r := range results {
+			got = append(got, r.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("DownloadFilteredObservations(%+v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+	if _, err := client.DownloadFilteredObservations("testuser", time.Time{}, time.Time{},
+		inat.ObservationFilter{QualityGrades: []string{"Research"}}); err == nil {
+		t.Error("DownloadFilteredObservations() with an unknown quality grade succeeded, want error")
+	}
+}
+
+func TestUpdatedSince(t *testing.T) {
+	previous := []inat.Result{
+		{ID: 1, UpdatedAt: "2024-01-01T00:00:00Z", Description: "old"},
+		{ID: 2, UpdatedAt: "2024-01-01T00:00:00Z", Description: "old"},
+		{ID: 4, UpdatedAt: "2024-01-01T00:00:00Z", Description: "old"},
+	}
+	server := inattest.NewServer([]inat.Result{
+		previous[0],
+		{ID: 2, UpdatedAt: "2024-03-01T12:00:00-05:00", Description: "edited"},
+		{ID: 3, UpdatedAt: "2024-03-02T00:00:00Z", Description: "new"},
+		previous[2],
+	})
+	defer server.Close()
+	client := inat.NewClient(server.URL, "test-token", "")
+	client.SetRequestLimits(0, 0) // don't slow down the test
+
+	lastRun := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	updated, err := client.DownloadFilteredObservations("testuser", time.Time{}, time.Time{},
+		inat.ObservationFilter{UpdatedSince: lastRun}, "id", "updated_at", "description")
+	if err != nil {
+		t.Fatalf("DownloadFilteredObservations() error = %v", err)
+	}
+	if len(updated) != 2 {
+		t.Fatalf("DownloadFilteredObservations(UpdatedSince) = %+v, want observations 2 and 3", updated)
+	}
+	var got []string
+	for _, r := range inat.MergeUpdates(previous, updated) {
+		got = append(got, fmt.Sprintf("%d %s", r.ID, r.Description))
+	}
+	if want := []string{"1 old", "2 edited", "3 new", "4 old"}; !slices.Equal(got, want) {
+		t.Errorf("inat.MergeUpdates() = %q, want %q", got, want)
+	}
+}
+
+func TestStreamObservations(t *testing.T) {
+	var results []inat.Result
+	for id := 1; id <= inat.PerPage+1; id++ {
+		results = append(results, inat.Result{ID: id})
+	}
+	server := inattest.NewServer(results)
+	defer server.Close()
+	client := inat.NewClient(server.URL, "test-token", "")
+	client.SetRequestLimits(0, 1000) // count requests without slowing down the test
+
+	var ids []int
+	for r, err := range client.StreamObservations("testuser", time.Time{}, time.Time{}) {
+		if err != nil {
+			t.Fatalf("StreamObservations() error = %v", err)
+		}
+		ids = append(ids, r.ID)
+	}
+	if len(ids) != len(results) || ids[0] != 1 || ids[len(ids)-1] != inat.PerPage+1 {
+		t.Errorf("StreamObservations() yielded %d observations from %v to %v, want 1 to %d", len(ids), ids[0], ids[len(ids)-1], inat.PerPage+1)
+	}
+
+	// Stopping early doesn't download the rest.
+	before := client.RequestsToday()
+	for range client.StreamObservations("testuser", time.Time{}, time.Time{}) {
+		break
+	}
+	if n := client.RequestsToday() - before; n != 1 {
+		t.Errorf("StreamObservations() stopped after the first observation made %d requests, want 1", n)
+	}
+
+	// Errors end the sequence.
+	server.Close()
+	defer func(n int) { inat.MaxAttempts = n }(inat.MaxAttempts)
+	inat.MaxAttempts = 1
+	var errs int
+	for _, err := range client.StreamObservations("testuser", time.Time{}, time.Time{}) {
+		if err == nil {
+			t.Error("StreamObservations() from a closed server yielded an observation")
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Errorf("StreamObservations() from a closed server yielded %d errors, want 1", errs)
+	}
+}
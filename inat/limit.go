@@ -0,0 +1,157 @@
+package inat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// From https://www.inaturalist.org/pages/api+recommended+practices:
+// Please keep requests to about 1 per second, and around 10k requests a day.
+const requestInterval = time.Second
+
+// DailyRequestLimit is the most API requests a client makes in a day
+// (in UTC). iNaturalist may throttle or block accounts that make many
+// more. The count is per client, so it doesn't include requests made
+// by other runs or programs. Zero means no limit.
+var DailyRequestLimit = 10000
+
+// ErrDailyLimit is returned (wrapped) by Client methods once the client
+// has made DailyRequestLimit requests today. Resume the sync tomorrow.
+var ErrDailyLimit = errors.New("reached the daily limit of iNaturalist API requests")
+
+// rateLimiter is a token bucket that limits the rate of API requests.
+// The bucket holds at most burst tokens and earns one token per interval.
+// Tokens may go negative: each waiter reserves the next available token
+// and sleeps until it has been earned.
+//
+// It also counts the requests made each UTC day and refuses any over
+// the daily limit.
+type rateLimiter struct {
+	interval time.Duration // zero means no limit
+	burst    int
+	daily    int // zero means no limit
+	now      func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time // last refill
+	day    time.Time // start of the UTC day of count
+	count  int       // requests reserved on day
+	warned bool      // logged that the day's budget is nearly spent
+}
+
+func newRateLimiter(interval time.Duration, burst, daily int, now func() time.Time) *rateLimiter {
+	return &rateLimiter{
+		interval: interval,
+		burst:    burst,
+		daily:    daily,
+		now:      now,
+		tokens:   float64(burst),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it. It fails if the day's requests are used up.
+func (l *rateLimiter) reserve() (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if l.daily > 0 {
+		day := now.UTC().Truncate(24 * time.Hour)
+		if !day.Equal(l.day) {
+			l.day, l.count, l.warned = day, 0, false
+		}
+		if l.count >= l.daily {
+			return 0, fmt.Errorf("%w (%d); try again after %s", ErrDailyLimit, l.daily, day.Add(24*time.Hour).Format(time.RFC3339))
+		}
+		l.count++
+		if !l.warned && l.count >= l.daily*9/10 {
+			l.warned = true
+			log.Printf("WARNING: made %d of the %d iNaturalist API requests allowed today", l.count, l.daily)
+		}
+	}
+	if l.interval <= 0 {
+		return 0, nil
+	}
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0, nil
+	}
+	return time.Duration(-l.tokens * float64(l.interval)), nil
+}
+
+// cancel returns a reserved token that wasn't used.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	l.tokens++
+	if l.count > 0 {
+		l.count--
+	}
+	l.mu.Unlock()
+}
+
+// requestsToday returns the number of requests reserved today.
+func (l *rateLimiter) requestsToday() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.now().UTC().Truncate(24 * time.Hour).Equal(l.day) {
+		return 0
+	}
+	return l.count
+}
+
+// wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	d, err := l.reserve()
+	if err != nil {
+		return err
+	}
+	if d == 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// Wait blocks until the client's rate limiter allows another request
+// or ctx is done. The client calls Wait before every API request;
+// programs that make their own iNaturalist API requests alongside the client
+// can call Wait too, so that together they stay within iNaturalist's limits.
+// Wait fails, with an error wrapping ErrDailyLimit, once the client has
+// made DailyRequestLimit requests today.
+func (c *Client) Wait(ctx context.Context) error {
+	return c.limiter.wait(ctx)
+}
+
+// SetRequestLimits replaces the client's rate limits: about one request
+// per interval, and at most daily requests per UTC day. Zero means no
+// limit. Clients follow iNaturalist's recommended practices by default
+// (see DailyRequestLimit); this is for servers that don't need them,
+// like an inattest.Server. Call it before making requests.
+func (c *Client) SetRequestLimits(interval time.Duration, daily int) {
+	c.limiter = newRateLimiter(interval, 1, daily, c.now)
+}
+
+// RequestsToday returns the number of API requests the client has made
+// today (in UTC), counting requests by programs that called Wait.
+func (c *Client) RequestsToday() int {
+	return c.limiter.requestsToday()
+}
@@ -0,0 +1,73 @@
+package inat
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(time.Second, 2, 0, func() time.Time { return now })
+
+	// The bucket starts full.
+	for i := range 2 {
+		if d, _ := l.reserve(); d != 0 {
+			t.Errorf("reserve() %d = %v, want 0", i, d)
+		}
+	}
+	// Then each waiter waits one more interval.
+	if d, _ := l.reserve(); d != time.Second {
+		t.Errorf("reserve() = %v, want 1s", d)
+	}
+	if d, _ := l.reserve(); d != 2*time.Second {
+		t.Errorf("reserve() = %v, want 2s", d)
+	}
+	// Time passing earns tokens back.
+	now = now.Add(3 * time.Second)
+	if d, _ := l.reserve(); d != 0 {
+		t.Errorf("reserve() after 3s = %v, want 0", d)
+	}
+}
+
+func TestRateLimiterDaily(t *testing.T) {
+	now := time.Date(2025, 7, 1, 23, 0, 0, 0, time.UTC)
+	l := newRateLimiter(0, 0, 3, func() time.Time { return now })
+	for i := range 3 {
+		if _, err := l.reserve(); err != nil {
+			t.Fatalf("reserve() %d error = %v", i, err)
+		}
+	}
+	if _, err := l.reserve(); !errors.Is(err, ErrDailyLimit) {
+		t.Errorf("reserve() over the daily limit error = %v, want ErrDailyLimit", err)
+	}
+	if n := l.requestsToday(); n != 3 {
+		t.Errorf("requestsToday() = %d, want 3", n)
+	}
+	// Canceled requests don't count.
+	l.cancel()
+	if _, err := l.reserve(); err != nil {
+		t.Errorf("reserve() after cancel error = %v", err)
+	}
+	// The budget resets at midnight UTC.
+	now = now.Add(time.Hour)
+	if n := l.requestsToday(); n != 0 {
+		t.Errorf("requestsToday() the next day = %d, want 0", n)
+	}
+	if _, err := l.reserve(); err != nil {
+		t.Errorf("reserve() the next day error = %v", err)
+	}
+}
+
+func TestClient_WaitCanceled(t *testing.T) {
+	client := NewClient("", "", "")
+	if err := client.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := client.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait() error = %v, want %v", err, context.Canceled)
+	}
+}
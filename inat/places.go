@@ -0,0 +1,151 @@
+package inat
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Place is an iNaturalist place. Standard places, like countries, states,
+// and counties, are curated by iNaturalist and have an AdminLevel;
+// community places are drawn by users.
+type Place struct {
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`         // like "Fairfax"
+	DisplayName string `json:"display_name,omitempty"` // like "Fairfax County, VA, US"
+	AdminLevel  *int   `json:"admin_level,omitempty"`  // 0 for countries, 10 for states, 20 for counties; nil for community places
+	PlaceType   int    `json:"place_type,omitempty"`
+	Location    string `json:"location,omitempty"` // "latitude,longitude" of its center
+}
+
+// Standard reports whether p is a standard place rather than a community one.
+func (p Place) Standard() bool {
+	return p.AdminLevel != nil
+}
+
+// Places is returned by https://api.inaturalist.org/v2/places
+type Places struct {
+	Results      []Place `json:"results,omitempty"`
+	TotalResults int     `json:"total_results,omitempty"`
+}
+
+// placeFields are the fields requested for places.
+const placeFields = "id,name,display_name,admin_level,place_type,location"
+
+// LookupPlace returns the place with the provided ID. If there's no such
+// place, the error wraps ErrNotFound.
+func (c *Client) LookupPlace(placeID int) (Place, error) {
+	var places Places
+	if err := c.getPlaces("/places/"+strconv.Itoa(placeID), url.Values{}, &places); err != nil {
+		return Place{}, fmt.Errorf("LookupPlace(%d): %w", placeID, err)
+	}
+	if len(places.Results) == 0 {
+		return Place{}, fmt.Errorf("LookupPlace(%d): %w", placeID, ErrNotFound)
+	}
+	return places.Results[0], nil
+}
+
+// SearchPlaces returns the places whose names start with or match name,
+// best matches first, like the place search on iNaturalist's website.
+// Use it to resolve a record without coordinates, such as one with just
+// a county and state, to a standard place.
+func (c *Client) SearchPlaces(name string) ([]Place, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("SearchPlaces: empty name")
+	}
+	var places Places
+	if err := c.getPlaces("/places/autocomplete", url.Values{"q": {name}}, &places); err != nil {
+		return nil, fmt.Errorf("SearchPlaces(%q): %w", name, err)
+	}
+	return places.Results, nil
+}
+
+// NearbyPlaces returns the standard places, smallest first, whose
+// boundaries overlap the box with the provided southwest and northeast
+// corners. Pass the same corner twice for the places containing a point.
+func (c *Client) NearbyPlaces(swLat, swLng, neLat, neLng float64) ([]Place, error) {
+	format := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	q := url.Values{
+		"swlat": {format(swLat)},
+		"swlng": {format(swLng)},
+		"nelat": {format(neLat)},
+		"nelng": {format(neLng)},
+	}
+	var places struct {
+		Results struct {
+			Standard []Place `json:"standard"`
+		} `json:"results"`
+	}
+	if err := c.getPlaces("/places/nearby", q, &places); err != nil {
+		return nil, fmt.Errorf("NearbyPlaces(%s,%s %s,%s): %w", q["swlat"][0], q["swlng"][0], q["nelat"][0], q["nelng"][0], err)
+	}
+	// Admin levels grow as places shrink.
+	standard := places.Results.Standard
+	slices.SortStableFunc(standard, func(a, b Place) int {
+		return cmp.Compare(adminLevel(b), adminLevel(a))
+	})
+	return standard, nil
+}
+
+// adminLevel returns p's admin level, or -1 for a community place.
+func adminLevel(p Place) int {
+	if p.AdminLevel == nil {
+		return -1
+	}
+	return *p.AdminLevel
+}
+
+// getPlaces requests path with the query q and decodes the response into v.
+// Responses are cached for the lifetime of the client, and saved by SaveCache.
+func (c *Client) getPlaces(path string, q url.Values, v any) error {
+	q.Set("fields", placeFields)
+	key := path + "?" + q.Encode()
+	c.mu.Lock()
+	entry, ok := c.places[key]
+	c.mu.Unlock()
+	if !ok {
+		req, err := http.NewRequest("GET", c.baseURL+key, nil)
+		if err != nil {
+			return err
+		}
+		body, err := c.roundTrip(req)
+		if err != nil {
+			return err
+		}
+		if !json.Valid([]byte(body)) {
+			return fmt.Errorf("decoding response: invalid JSON")
+		}
+		entry = cached[json.RawMessage]{json.RawMessage(body), c.now()}
+		c.mu.Lock()
+		if c.places == nil {
+			c.places = map[string]cached[json.RawMessage]{}
+		}
+		c.places[key] = entry
+		c.mu.Unlock()
+	}
+	if err := json.Unmarshal(entry.Value, v); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	return nil
+}
+
+// DownloadObservationsInPlace is like DownloadObservations, but returns
+// just inatUserID's observations in the place placeID, such as a county
+// from NearbyPlaces or SearchPlaces.
+func (c *Client) DownloadObservationsInPlace(inatUserID string, placeID int, d1, d2 time.Time, fields ...string) ([]Result, error) {
+	log.Printf("Downloading observations for %s in place %d", inatUserID, placeID)
+	oq := observationQuery{userID: inatUserID, d1: d1, d2: d2, filter: url.Values{"place_id": {strconv.Itoa(placeID)}}}
+	results, err := c.download(context.Background(), oq, fields)
+	if err != nil {
+		return nil, fmt.Errorf("DownloadObservationsInPlace(%s, %d): %w", inatUserID, placeID, err)
+	}
+	return results, nil
+}
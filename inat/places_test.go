@@ -0,0 +1,84 @@
+package inat
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Places(t *testing.T) {
+	level := func(n int) *int { return &n }
+	fairfax := Place{ID: 2015, Name: "Fairfax", DisplayName: "Fairfax County, VA, US", AdminLevel: level(20)}
+	virginia := Place{ID: 7, Name: "Virginia", DisplayName: "Virginia, US", AdminLevel: level(10)}
+	us := Place{ID: 1, Name: "United States", DisplayName: "United States", AdminLevel: level(0)}
+	var placeIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("fields") != placeFields && r.URL.Path != "/observations" {
+			t.Errorf("%s fields = %q, want %q", r.URL.Path, q.Get("fields"), placeFields)
+		}
+		switch r.URL.Path {
+		case "/places/2015":
+			json.NewEncoder(w).Encode(Places{TotalResults: 1, Results: []Place{fairfax}})
+		case "/places/autocomplete":
+			if q.Get("q") == "Fairfax" {
+				json.NewEncoder(w).Encode(Places{TotalResults: 1, Results: []Place{fairfax}})
+			} else {
+				json.NewEncoder(w).Encode(Places{})
+			}
+		case "/places/nearby":
+			if q.Get("swlat") != "38.85" || q.Get("nelng") != "-77.3" {
+				t.Errorf("nearby query = %s, want the box around Fairfax", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"results":{"standard":[`))
+			for i, p := range []Place{us, fairfax, virginia} {
+				if i > 0 {
+					w.Write([]byte(","))
+				}
+				json.NewEncoder(w).Encode(p)
+			}
+			w.Write([]byte(`],"community":[{"id":99,"name":"My yard"}]}}`))
+		case "/observations":
+			placeIDs = append(placeIDs, q.Get("place_id"))
+			json.NewEncoder(w).Encode(Observations{})
+		default:
+			json.NewEncoder(w).Encode(Places{})
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+
+	if p, err := client.LookupPlace(2015); err != nil || p.DisplayName != fairfax.DisplayName || !p.Standard() {
+		t.Errorf("LookupPlace(2015) = %+v, %v; want Fairfax County", p, err)
+	}
+	if _, err := client.LookupPlace(3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LookupPlace(3) error = %v, want ErrNotFound", err)
+	}
+	if places, err := client.SearchPlaces("Fairfax"); err != nil || len(places) != 1 || places[0].ID != 2015 {
+		t.Errorf("SearchPlaces(Fairfax) = %+v, %v; want Fairfax County", places, err)
+	}
+	if _, err := client.SearchPlaces(""); err == nil {
+		t.Error("SearchPlaces() with no name succeeded, want error")
+	}
+	places, err := client.NearbyPlaces(38.85, -77.31, 38.86, -77.3)
+	if err != nil {
+		t.Fatalf("NearbyPlaces() error = %v", err)
+	}
+	var ids []int
+	for _, p := range places {
+		ids = append(ids, p.ID)
+	}
+	if len(ids) != 3 || ids[0] != 2015 || ids[1] != 7 || ids[2] != 1 {
+		t.Errorf("NearbyPlaces() = %v, want standard places 2015, 7, 1", ids)
+	}
+	if _, err := client.DownloadObservationsInPlace("testuser", 2015, time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("DownloadObservationsInPlace() error = %v", err)
+	}
+	if len(placeIDs) != 1 || placeIDs[0] != "2015" {
+		t.Errorf("DownloadObservationsInPlace() requested place_id %q, want 2015", placeIDs)
+	}
+}
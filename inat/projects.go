@@ -0,0 +1,89 @@
+package inat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Project is an iNaturalist project, like a county birding project
+// or a personal collection of imported observations.
+type Project struct {
+	ID    int    `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+	Slug  string `json:"slug,omitempty"` // as in https://www.inaturalist.org/projects/SLUG
+}
+
+// URL returns the project's page on iNaturalist.
+func (p Project) URL() string {
+	return "https://www.inaturalist.org/projects/" + p.Slug
+}
+
+// Projects is returned by https://api.inaturalist.org/v2/projects
+type Projects struct {
+	Results      []Project `json:"results,omitempty"`
+	TotalResults int       `json:"total_results,omitempty"`
+}
+
+// LookupProject returns the project with the provided numeric ID or slug,
+// the last part of the project's URL. If there's no such project, the
+// error wraps ErrNotFound.
+func (c *Client) LookupProject(idOrSlug string) (Project, error) {
+	idOrSlug = strings.TrimSpace(idOrSlug)
+	if idOrSlug == "" || strings.Contains(idOrSlug, "/") {
+		return Project{}, fmt.Errorf("LookupProject(%q): not a project ID or slug", idOrSlug)
+	}
+	req, err := http.NewRequest("GET", c.baseURL+"/projects/"+url.PathEscape(idOrSlug)+"?fields=id,title,slug", nil)
+	if err != nil {
+		return Project{}, fmt.Errorf("LookupProject(%s): %w", idOrSlug, err)
+	}
+	body, err := c.roundTrip(req)
+	if err != nil {
+		return Project{}, fmt.Errorf("LookupProject(%s): %w", idOrSlug, err)
+	}
+	var projects Projects
+	if err := json.Unmarshal([]byte(body), &projects); err != nil {
+		return Project{}, fmt.Errorf("LookupProject(%s): decoding response: %w", idOrSlug, err)
+	}
+	if len(projects.Results) == 0 {
+		return Project{}, fmt.Errorf("LookupProject(%s): %w", idOrSlug, ErrNotFound)
+	}
+	return projects.Results[0], nil
+}
+
+// AddObservationToProject adds the observation obsUUID to the project
+// projectID. The project must accept the observation: collection projects
+// include observations by their criteria instead, and some traditional
+// projects accept only their members' observations.
+func (c *Client) AddObservationToProject(projectID int, obsUUID uuid.UUID) error {
+	if projectID <= 0 {
+		return fmt.Errorf("AddObservationToProject(%d, %s): bad project ID", projectID, obsUUID)
+	}
+	buf := &bytes.Buffer{}
+	err := json.NewEncoder(buf).Encode(struct {
+		ProjectObservation any `json:"project_observation"`
+	}{
+		struct {
+			ProjectID     int       `json:"project_id"`
+			ObservationID uuid.UUID `json:"observation_id"`
+		}{projectID, obsUUID},
+	})
+	if err != nil {
+		return fmt.Errorf("AddObservationToProject(%d, %s): %w", projectID, obsUUID, err)
+	}
+	req, err := http.NewRequest("POST", c.baseURL+"/project_observations", buf)
+	if err != nil {
+		return fmt.Errorf("AddObservationToProject(%d, %s): %w", projectID, obsUUID, err)
+	}
+	if _, err := c.roundTrip(req); err != nil {
+		return fmt.Errorf("AddObservationToProject(%d, %s): %w", projectID, obsUUID, err)
+	}
+	log.Printf("Added %s to project %d", ObservationURL(obsUUID), projectID)
+	return nil
+}
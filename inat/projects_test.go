@@ -0,0 +1,69 @@
+package inat
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestClient_Projects(t *testing.T) {
+	obsUUID := uuid.New()
+	var added []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && (r.URL.Path == "/projects/ebird-imports" || r.URL.Path == "/projects/42"):
+			json.NewEncoder(w).Encode(Projects{TotalResults: 1, Results: []Project{{ID: 42, Title: "eBird imports", Slug: "ebird-imports"}}})
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(Projects{})
+		case r.Method == http.MethodPost && r.URL.Path == "/project_observations":
+			var req struct {
+				ProjectObservation struct {
+					ProjectID     int       `json:"project_id"`
+					ObservationID uuid.UUID `json:"observation_id"`
+				} `json:"project_observation"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("decoding request: %v", err)
+			}
+			po := req.ProjectObservation
+			added = append(added, po.ObservationID.String())
+			if po.ProjectID != 42 {
+				t.Errorf("project_id = %d, want 42", po.ProjectID)
+			}
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+
+	for _, id := range []string{"ebird-imports", "42"} {
+		p, err := client.LookupProject(id)
+		if err != nil || p.ID != 42 || p.URL() != "https://www.inaturalist.org/projects/ebird-imports" {
+			t.Errorf("LookupProject(%s) = %+v, %v; want project 42", id, p, err)
+		}
+	}
+	if _, err := client.LookupProject("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LookupProject(nope) error = %v, want ErrNotFound", err)
+	}
+	if _, err := client.LookupProject("a/b"); err == nil {
+		t.Error("LookupProject(a/b) succeeded, want error")
+	}
+
+	if err := client.AddObservationToProject(42, obsUUID); err != nil {
+		t.Fatalf("AddObservationToProject() error = %v", err)
+	}
+	if err := client.AddObservationToProject(0, obsUUID); err == nil {
+		t.Error("AddObservationToProject(0) succeeded, want error")
+	}
+	if len(added) != 1 || added[0] != obsUUID.String() {
+		t.Errorf("added %q, want just %s", added, obsUUID)
+	}
+}
@@ -0,0 +1,98 @@
+package inat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DownloadObservationsResumable downloads observations for inatUserID like
+// DownloadObservations, but writes them to w as newline-delimited JSON
+// (one Result per line) instead of collecting them in memory.
+// It returns the number of observations written by this call.
+//
+// Observations are downloaded in increasing ID order using id_above cursors.
+// After each observation is written to w, its ID is saved to cursorFile,
+// which contains just that ID as a decimal number followed by a newline.
+// If cursorFile already exists, the download resumes after the ID it
+// contains, so an interrupted download of a large account can pick up where
+// it left off; at worst, the observation being written when it was
+// interrupted is written again. Callers that resume should open w in
+// append mode. If cursorFile is "", the download always starts from the
+// beginning and no cursor is saved.
+//
+// Canceling ctx stops the download; the observations written so far
+// stay in w and cursorFile.
+func (c *Client) DownloadObservationsResumable(ctx context.Context, w io.Writer, inatUserID string, d1, d2 time.Time, cursorFile string, fields ...string) (int, error) {
+	cursor, err := readCursor(cursorFile)
+	if err != nil {
+		return 0, fmt.Errorf("DownloadObservationsResumable: %w", err)
+	}
+	if cursor > 0 {
+		log.Printf("Resuming download for %s after observation %d", inatUserID, cursor)
+	}
+
+	enc := json.NewEncoder(w)
+	n := 0
+	for {
+		observations, err := c.observationsBetween(ctx, observationQuery{userID: inatUserID, d1: d1, d2: d2}, cursor, 0, fields)
+		if err != nil {
+			return n, fmt.Errorf("DownloadObservationsResumable: %w", err)
+		}
+		if len(observations.Results) == 0 {
+			break
+		}
+		for _, r := range observations.Results {
+			if err := enc.Encode(r); err != nil {
+				return n, fmt.Errorf("DownloadObservationsResumable: %w", err)
+			}
+			cursor = r.ID
+			n++
+			if err := writeCursor(cursorFile, cursor); err != nil {
+				return n, fmt.Errorf("DownloadObservationsResumable: %w", err)
+			}
+		}
+		log.Printf("Downloaded %d observations (through ID %d)", n, cursor)
+	}
+	return n, nil
+}
+
+// readCursor returns the observation ID saved in cursorFile,
+// or 0 if cursorFile is "" or doesn't exist.
+func readCursor(cursorFile string) (int, error) {
+	if cursorFile == "" {
+		return 0, nil
+	}
+	b, err := os.ReadFile(cursorFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	cursor, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, fmt.Errorf("bad cursor file %s: %w", cursorFile, err)
+	}
+	return cursor, nil
+}
+
+// writeCursor saves cursor to cursorFile, replacing it atomically
+// so that an interruption never leaves a partially written cursor.
+func writeCursor(cursorFile string, cursor int) error {
+	if cursorFile == "" {
+		return nil
+	}
+	tmp := cursorFile + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(cursor)+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cursorFile)
+}
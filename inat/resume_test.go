@@ -0,0 +1,117 @@
+package inat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadObservationsResumable(t *testing.T) {
+	ids := []int{10, 20, 30}
+	failAbove := -1 // fail requests with this id_above
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idAbove, _ := strconv.Atoi(r.URL.Query().Get("id_above"))
+		if idAbove == failAbove {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// Serve one observation per page.
+		var results []Result
+		for _, id := range ids {
+			if id > idAbove {
+				results = append(results, Result{ID: id})
+				break
+			}
+		}
+		json.NewEncoder(w).Encode(Observations{Results: results})
+	}))
+	defer server.Close()
+	defer func(n int) { MaxAttempts = n }(MaxAttempts)
+	MaxAttempts = 1 // fail without retrying
+
+	client := NewClient(server.URL, "", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now)
+	cursorFile := filepath.Join(t.TempDir(), "cursor")
+
+	// Interrupt the download after the second observation.
+	failAbove = 20
+	var buf bytes.Buffer
+	n, err := client.DownloadObservationsResumable(context.Background(), &buf, "testuser", time.Time{}, time.Time{}, cursorFile)
+	if err == nil {
+		t.Fatal("DownloadObservationsResumable() succeeded, want error")
+	}
+	if n != 2 {
+		t.Errorf("DownloadObservationsResumable() = %d, want 2", n)
+	}
+	if b, _ := os.ReadFile(cursorFile); string(b) != "20\n" {
+		t.Errorf("cursor file = %q, want %q", b, "20\n")
+	}
+
+	// Resume it.
+	failAbove = -1
+	n, err = client.DownloadObservationsResumable(context.Background(), &buf, "testuser", time.Time{}, time.Time{}, cursorFile)
+	if err != nil {
+		t.Fatalf("DownloadObservationsResumable() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("DownloadObservationsResumable() = %d, want 1", n)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines of NDJSON, got %d: %q", len(lines), buf.String())
+	}
+	var last Result
+	if err := json.Unmarshal([]byte(lines[2]), &last); err != nil || last.ID != 30 {
+		t.Errorf("last line = %q, want observation 30", lines[2])
+	}
+}
+
+// failingWriter fails every write after the first n.
+type failingWriter struct {
+	n int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.n--
+	return len(p), nil
+}
+
+func TestDownloadObservationsResumableSavesEachObservation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Serve the whole account in one page.
+		var results []Result
+		if !r.URL.Query().Has("id_above") {
+			results = []Result{{ID: 10}, {ID: 20}, {ID: 30}}
+		}
+		json.NewEncoder(w).Encode(Observations{Results: results})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now)
+	cursorFile := filepath.Join(t.TempDir(), "cursor")
+
+	// Fail partway through the page.
+	n, err := client.DownloadObservationsResumable(context.Background(), &failingWriter{n: 2}, "testuser", time.Time{}, time.Time{}, cursorFile)
+	if err == nil {
+		t.Fatal("DownloadObservationsResumable() succeeded, want error")
+	}
+	if n != 2 {
+		t.Errorf("DownloadObservationsResumable() = %d, want 2", n)
+	}
+	if b, _ := os.ReadFile(cursorFile); string(b) != "20\n" {
+		t.Errorf("cursor file = %q, want %q", b, "20\n")
+	}
+}
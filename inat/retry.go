@@ -0,0 +1,111 @@
+package inat
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxAttempts is the most times a Client makes a request when iNaturalist
+// throttles it (429 Too Many Requests) or fails in ways that may be
+// temporary, such as server errors and broken connections, so that a
+// hiccup doesn't fail an observation in the middle of a sync.
+// POST requests, like photo and sound uploads, are only retried when
+// they're throttled or never reached iNaturalist, since iNaturalist may
+// have processed one that failed later. It's at least one.
+var MaxAttempts = 5
+
+// RetryDelay is about how long a Client waits before its first retry.
+// The wait doubles for each later retry, and each wait is randomized
+// between half and all of that. If iNaturalist's response has a
+// Retry-After header, the client waits that long instead.
+var RetryDelay = 2 * time.Second
+
+// MaxRetryWait is the longest a Client waits before a retry. If
+// iNaturalist asks it to wait longer, the request fails instead.
+var MaxRetryWait = 5 * time.Minute
+
+// retryableError is an error after which a request may succeed if it's
+// made again, after waiting at least after, if it's set.
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+// idempotent reports whether making req more than once has the same
+// effect as making it once.
+func idempotent(req *http.Request) bool {
+	return req.Method != "POST"
+}
+
+// neverSent reports whether err, from sending a request, means that the
+// request never reached the server, because connecting to it failed.
+func neverSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// retryDelay returns how long to wait after the provided attempt fails.
+func retryDelay(attempt int) time.Duration {
+	d := RetryDelay << min(attempt-1, 16)
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is
+// either a number of seconds or an HTTP date, as a wait from now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
+
+// rewind prepares the request to be sent again, reporting whether it can be.
+func rewind(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
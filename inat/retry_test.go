@@ -0,0 +1,124 @@
+package inat
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestClientRetry(t *testing.T) {
+	defer func(n int, d, max time.Duration) {
+		MaxAttempts, RetryDelay, MaxRetryWait = n, d, max
+	}(MaxAttempts, RetryDelay, MaxRetryWait)
+	MaxAttempts, RetryDelay, MaxRetryWait = 3, time.Millisecond, time.Minute
+
+	var (
+		requests int
+		statuses []int  // statuses to return before succeeding
+		header   string // Retry-After header of failures
+		bodies   []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(statuses) > 0 {
+			if header != "" {
+				w.Header().Set("Retry-After", header)
+			}
+			w.WriteHeader(statuses[0])
+			statuses = statuses[1:]
+			return
+		}
+		w.Write([]byte(`{"results":[{"id":1,"login":"birder"}]}`))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "token", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name     string
+		statuses []int
+		header   string
+		wantErr  string
+		requests int
+	}{
+		{"throttled", []int{http.StatusTooManyRequests}, "0", "", 2},
+		{"server errors", []int{http.StatusBadGateway, http.StatusServiceUnavailable}, "", "", 3},
+		{"budget exhausted", []int{500, 500, 500}, "", "500 Internal Server Error", 3},
+		{"wait too long", []int{http.StatusTooManyRequests}, "3600", "wait 1h0m0s", 1},
+		{"not retried", []int{http.StatusBadRequest}, "", "400 Bad Request", 1},
+	} {
+		requests, statuses, header = 0, tc.statuses, tc.header
+		err := client.Ping(ctx)
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("%s: Ping() error = %v", tc.name, err)
+		}
+		if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("%s: Ping() error = %v, want %q", tc.name, err, tc.wantErr)
+		}
+		if requests != tc.requests {
+			t.Errorf("%s: Ping() made %d requests, want %d", tc.name, requests, tc.requests)
+		}
+	}
+
+	// A retried request sends its body again.
+	requests, statuses, header, bodies = 0, []int{http.StatusTooManyRequests}, "", nil
+	obs := Observation{UUID: uuid.New(), SpeciesGuess: "Mallard"}
+	client.CreateObservation(obs)
+	if len(bodies) != 2 || bodies[0] == "" || bodies[1] != bodies[0] {
+		t.Errorf("CreateObservation() sent bodies %q, want the same body twice", bodies)
+	}
+
+	// A POST that may have reached iNaturalist isn't made again.
+	requests, statuses, header = 0, []int{http.StatusBadGateway}, ""
+	if _, err := client.CreateObservation(obs); err == nil {
+		t.Error("CreateObservation() succeeded, want error")
+	}
+	if requests != 1 {
+		t.Errorf("CreateObservation() made %d requests after a server error, want 1", requests)
+	}
+}
+
+func TestNeverSent(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close() // refuse connections
+	_, err := http.Post(server.URL, "text/plain", strings.NewReader("body"))
+	if err == nil {
+		t.Fatal("Post() to a closed server succeeded")
+	}
+	if !neverSent(err) {
+		t.Errorf("neverSent(%v) = false, want true", err)
+	}
+	if neverSent(io.ErrUnexpectedEOF) {
+		t.Errorf("neverSent(%v) = true, want false", io.ErrUnexpectedEOF)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"Sun, 01 Jun 2025 12:00:30 GMT", 30 * time.Second, true},
+		{"Sun, 01 Jun 2025 11:00:00 GMT", 0, true},
+		{"soon", 0, false},
+	} {
+		got, ok := parseRetryAfter(tc.value, now)
+		if got != tc.want || ok != tc.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tc.value, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package inat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Taxa is returned by https://api.inaturalist.org/v2/taxa
+type Taxa struct {
+	Page         int     `json:"page,omitempty"`
+	PerPage      int     `json:"per_page,omitempty"`
+	Results      []Taxon `json:"results,omitempty"`
+	TotalResults int     `json:"total_results,omitempty"`
+}
+
+// TaxonAncestry returns the ancestors of the taxon with the given ID,
+// ordered from the root of the tree of life down to the taxon's parent.
+// Callers can walk this list backwards to find the nearest taxon
+// at a higher rank, such as the genus of an unmatched species.
+// Results are cached for the lifetime of the client.
+// Canceling ctx abandons the request.
+func (c *Client) TaxonAncestry(ctx context.Context, taxonID int) ([]Taxon, error) {
+	c.mu.Lock()
+	entry, ok := c.ancestry[taxonID]
+	c.mu.Unlock()
+	if ok {
+		return entry.Value, nil
+	}
+
+	u, err := url.Parse(c.baseURL + "/taxa/" + strconv.Itoa(taxonID))
+	if err != nil {
+		return nil, fmt.Errorf("TaxonAncestry: %w", err)
+	}
+	q := u.Query()
+	q.Set("fields", "id,name,rank,ancestors.id,ancestors.name,ancestors.rank,ancestors.preferred_common_name")
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("TaxonAncestry: %w", err)
+	}
+	body, err := c.roundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("TaxonAncestry(%d): %w", taxonID, err)
+	}
+	var taxa Taxa
+	if err := json.Unmarshal([]byte(body), &taxa); err != nil {
+		return nil, fmt.Errorf("TaxonAncestry(%d): %w", taxonID, err)
+	}
+	if len(taxa.Results) == 0 {
+		return nil, fmt.Errorf("TaxonAncestry(%d): taxon not found", taxonID)
+	}
+	ancestors := taxa.Results[0].Ancestors
+
+	c.mu.Lock()
+	if c.ancestry == nil {
+		c.ancestry = map[int]cached[[]Taxon]{}
+	}
+	c.ancestry[taxonID] = cached[[]Taxon]{ancestors, c.now()}
+	c.mu.Unlock()
+	return ancestors, nil
+}
+
+// LookupTaxon returns the taxon with the given scientific name.
+// If iNaturalist has no such taxon, LookupTaxon returns the zero Taxon.
+// Results are cached for the lifetime of the client.
+func (c *Client) LookupTaxon(scientificName string) (Taxon, error) {
+	taxa, err := c.searchTaxa(TaxonSearch{Query: scientificName})
+	if err != nil {
+		return Taxon{}, fmt.Errorf("LookupTaxon(%q): %w", scientificName, err)
+	}
+	// The search also matches common names and partial names,
+	// so look for an exact match.
+	for _, t := range taxa {
+		if strings.EqualFold(t.Name, scientificName) {
+			return t, nil
+		}
+	}
+	return Taxon{}, nil
+}
+
+// MatchTaxon returns the taxa that match the given scientific name:
+// the taxon with exactly that name, if any, followed by its subspecies
+// and other infraspecific taxa, whose names extend it (so "Junco hyemalis"
+// matches "Junco hyemalis oreganus"). Check each taxon's Rank to choose
+// among them. Results are cached for the lifetime of the client.
+func (c *Client) MatchTaxon(scientificName string) ([]Taxon, error) {
+	taxa, err := c.searchTaxa(TaxonSearch{Query: scientificName})
+	if err != nil {
+		return nil, fmt.Errorf("MatchTaxon(%q): %w", scientificName, err)
+	}
+	var exact, infra []Taxon
+	for _, t := range taxa {
+		switch {
+		case strings.EqualFold(t.Name, scientificName):
+			exact = append(exact, t)
+		case len(t.Name) > len(scientificName) &&
+			strings.EqualFold(t.Name[:len(scientificName)+1], scientificName+" "):
+			infra = append(infra, t)
+		}
+	}
+	return append(exact, infra...), nil
+}
+
+// TaxonSearch is a search for taxa with SearchTaxa.
+type TaxonSearch struct {
+	Query string // a scientific or common name, or the start of one
+
+	// Autocomplete uses iNaturalist's autocomplete search, which matches
+	// the starts of names and returns the best matches first, instead of
+	// its full search.
+	Autocomplete bool
+
+	Ranks      []string // if set, only these ranks, like "species" and "subspecies"
+	IconicTaxa []string // if set, only taxa in these iconic taxa, like "Aves"
+}
+
+// key returns the key of the search in the client's cache. It's the query
+// alone for plain searches, like those by LookupTaxon and MatchTaxon.
+func (s TaxonSearch) key() string {
+	if !s.Autocomplete && len(s.Ranks) == 0 && len(s.IconicTaxa) == 0 {
+		return s.Query
+	}
+	q := url.Values{"q": {s.Query}}
+	if len(s.Ranks) > 0 {
+		q.Set("rank", strings.Join(s.Ranks, ","))
+	}
+	if len(s.IconicTaxa) > 0 {
+		q.Set("iconic_taxa", strings.Join(s.IconicTaxa, ","))
+	}
+	if s.Autocomplete {
+		return "autocomplete?" + q.Encode()
+	}
+	return "?" + q.Encode()
+}
+
+// SearchTaxa returns the taxa that match s, in iNaturalist's order.
+// The taxa include their IDs, names, ranks, common names, and iconic taxa.
+// Results are cached for the lifetime of the client, and saved by SaveCache.
+func (c *Client) SearchTaxa(s TaxonSearch) ([]Taxon, error) {
+	if strings.TrimSpace(s.Query) == "" {
+		return nil, fmt.Errorf("SearchTaxa: empty query")
+	}
+	taxa, err := c.searchTaxa(s)
+	if err != nil {
+		return nil, fmt.Errorf("SearchTaxa(%q): %w", s.Query, err)
+	}
+	return taxa, nil
+}
+
+// searchTaxa returns iNaturalist's taxon search results for s.
+func (c *Client) searchTaxa(s TaxonSearch) ([]Taxon, error) {
+	key := s.key()
+	c.mu.Lock()
+	entry, ok := c.taxa[key]
+	c.mu.Unlock()
+	if ok {
+		return entry.Value, nil
+	}
+
+	path := "/taxa"
+	if s.Autocomplete {
+		path = "/taxa/autocomplete"
+	}
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	query.Set("q", s.Query)
+	if len(s.Ranks) > 0 {
+		query.Set("rank", strings.Join(s.Ranks, ","))
+	}
+	query.Set("fields", "id,name,rank,preferred_common_name,iconic_taxon_name")
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.roundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	var results Taxa
+	if err := json.Unmarshal([]byte(body), &results); err != nil {
+		return nil, err
+	}
+	taxa := results.Results
+	// The taxa endpoints can't filter by iconic taxon, so filter here.
+	if len(s.IconicTaxa) > 0 {
+		taxa = slices.DeleteFunc(taxa, func(t Taxon) bool {
+			return !slices.ContainsFunc(s.IconicTaxa, func(name string) bool {
+				return strings.EqualFold(name, t.IconicTaxonName)
+			})
+		})
+	}
+
+	c.mu.Lock()
+	if c.taxa == nil {
+		c.taxa = map[string]cached[[]Taxon]{}
+	}
+	c.taxa[key] = cached[[]Taxon]{taxa, c.now()}
+	c.mu.Unlock()
+	return taxa, nil
+}
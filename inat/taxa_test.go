@@ -0,0 +1,146 @@
+package inat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestClient_TaxonAncestry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/taxa/12727" {
+			t.Errorf("Expected path /taxa/12727, got %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(Taxa{
+			TotalResults: 1,
+			Results: []Taxon{{
+				ID:   12727,
+				Name: "Turdus migratorius",
+				Rank: "species",
+				Ancestors: []Taxon{
+					{ID: 3, Name: "Aves", Rank: "class"},
+					{ID: 12716, Name: "Turdus", Rank: "genus"},
+				},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "")
+	for range 2 {
+		ancestors, err := client.TaxonAncestry(context.Background(), 12727)
+		if err != nil {
+			t.Fatalf("TaxonAncestry() error = %v", err)
+		}
+		if len(ancestors) != 2 || ancestors[1].Rank != "genus" {
+			t.Errorf("TaxonAncestry() = %+v, want Aves and Turdus", ancestors)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request (cached), got %d", requests)
+	}
+}
+
+func TestClient_LookupTaxon(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/taxa" {
+			t.Errorf("Expected path /taxa, got %s", r.URL.Path)
+		}
+		var results []Taxon
+		if r.URL.Query().Get("q") == "Turdus migratorius" {
+			results = []Taxon{
+				{ID: 12727, Name: "Turdus migratorius", Rank: "species", PreferredCommonName: "American Robin"},
+				{ID: 555, Name: "Turdus migratorius achrusterus", Rank: "subspecies"},
+			}
+		}
+		json.NewEncoder(w).Encode(Taxa{TotalResults: len(results), Results: results})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+	for range 2 {
+		taxon, err := client.LookupTaxon("Turdus migratorius")
+		if err != nil {
+			t.Fatalf("LookupTaxon() error = %v", err)
+		}
+		if taxon.ID != 12727 || taxon.PreferredCommonName != "American Robin" {
+			t.Errorf("LookupTaxon() = %+v, want American Robin", taxon)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request (cached), got %d", requests)
+	}
+	taxon, err := client.LookupTaxon("Aythya marila/affinis")
+	if err != nil {
+		t.Fatalf("LookupTaxon() error = %v", err)
+	}
+	if taxon.ID != 0 {
+		t.Errorf("LookupTaxon(slash) = %+v, want zero Taxon", taxon)
+	}
+}
+
+func TestClient_MatchTaxon(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Taxa{Results: []Taxon{
+			{ID: 1, Name: "Junco hyemalis oreganus", Rank: "subspecies"},
+			{ID: 2, Name: "Junco hyemalis", Rank: "species"},
+			{ID: 3, Name: "Junco hyemalisoides", Rank: "species"},
+			{ID: 4, Name: "Junco", Rank: "genus"},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+	taxa, err := client.MatchTaxon("Junco hyemalis")
+	if err != nil {
+		t.Fatalf("MatchTaxon() error = %v", err)
+	}
+	if len(taxa) != 2 || taxa[0].ID != 2 || taxa[1].ID != 1 {
+		t.Errorf("MatchTaxon() = %+v, want the species then the subspecies", taxa)
+	}
+}
+
+func TestClient_SearchTaxa(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+" rank="+r.URL.Query().Get("rank"))
+		json.NewEncoder(w).Encode(Taxa{Results: []Taxon{
+			{ID: 12727, Name: "Turdus migratorius", Rank: "species", IconicTaxonName: "Aves"},
+			{ID: 99, Name: "Turdus migratorius", Rank: "species", IconicTaxonName: "Plantae"}, // made up
+		}})
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "", "")
+	client.limiter = newRateLimiter(0, 0, 0, time.Now) // don't slow down the test
+
+	search := TaxonSearch{Query: "Turdus mig", Autocomplete: true, Ranks: []string{"species"}, IconicTaxa: []string{"aves"}}
+	for range 2 {
+		taxa, err := client.SearchTaxa(search)
+		if err != nil {
+			t.Fatalf("SearchTaxa() error = %v", err)
+		}
+		if len(taxa) != 1 || taxa[0].ID != 12727 {
+			t.Errorf("SearchTaxa() = %+v, want just the bird", taxa)
+		}
+	}
+	if _, err := client.SearchTaxa(TaxonSearch{Query: "Turdus mig"}); err != nil {
+		t.Fatalf("SearchTaxa() error = %v", err)
+	}
+	want := []string{"/taxa/autocomplete rank=species", "/taxa rank="}
+	if !slices.Equal(requests, want) {
+		t.Errorf("SearchTaxa() made requests %q, want %q (the repeat cached)", requests, want)
+	}
+	if _, err := client.SearchTaxa(TaxonSearch{Query: " "}); err == nil {
+		t.Error("SearchTaxa() with an empty query succeeded, want error")
+	}
+}
@@ -95,24 +95,52 @@ type Observations struct {
 }
 
 type Result struct {
-	CreatedAt            string    `json:"created_at,omitempty"`
-	Description          string    `json:"description,omitempty"`
-	IdentificationsCount int       `json:"identifications_count,omitempty"`
-	ObservedOn           string    `json:"observed_on,omitempty"`
-	Ofvs                 []Ofv     `json:"ofvs,omitempty"`
-	Photos               []Photo   `json:"photos,omitempty"`
-	PositionalAccuracy   int       `json:"positional_accuracy,omitempty"`
-	PreferredCommonName  string    `json:"preferred_common_name,omitempty"`
-	QualityGrade         string    `json:"quality_grade,omitempty"`
-	Sounds               []Sound   `json:"sounds,omitempty"`
-	Taxon                Taxon     `json:"taxon,omitempty"`
-	UUID                 uuid.UUID `json:"uuid,omitempty"`
+	Annotations          []Annotation     `json:"annotations,omitempty"`
+	Captive              bool             `json:"captive,omitempty"`
+	Comments             []Comment        `json:"comments,omitempty"`
+	CommunityTaxon       Taxon            `json:"community_taxon,omitempty"`
+	CreatedAt            string           `json:"created_at,omitempty"`
+	Description          string           `json:"description,omitempty"`
+	Geoprivacy           string           `json:"geoprivacy,omitempty"` // "", "obscured", or "private"
+	ID                   int              `json:"id,omitempty"`
+	Identifications      []Identification `json:"identifications,omitempty"`
+	IdentificationsCount int              `json:"identifications_count,omitempty"`
+	Location             string           `json:"location,omitempty"` // "latitude,longitude"; maybe obscured
+	Obscured             bool             `json:"obscured,omitempty"` // Location isn't exact
+	ObservedOn           string           `json:"observed_on,omitempty"`
+	Ofvs                 []Ofv            `json:"ofvs,omitempty"`
+	Photos               []Photo          `json:"photos,omitempty"`
+	PositionalAccuracy   int              `json:"positional_accuracy,omitempty"`
+	PrivateGeojson       *GeoJSON         `json:"private_geojson,omitempty"`
+	PrivateLocation      string           `json:"private_location,omitempty"` // "latitude,longitude"
+	PreferredCommonName  string           `json:"preferred_common_name,omitempty"`
+	QualityGrade         string           `json:"quality_grade,omitempty"`
+	Sounds               []Sound          `json:"sounds,omitempty"`
+	Taxon                Taxon            `json:"taxon,omitempty"`
+	TimeObservedAt       string           `json:"time_observed_at,omitempty"` // RFC 3339
+	UpdatedAt            string           `json:"updated_at,omitempty"`       // RFC 3339
+	UUID                 uuid.UUID        `json:"uuid,omitempty"`
 }
 
 func (r Result) URL() string {
 	return ObservationURL(r.UUID)
 }
 
+// TrueLocation returns the "latitude,longitude" where r was observed.
+// iNaturalist obscures or hides Location for observations with geoprivacy
+// and for threatened taxa, but it returns the exact PrivateLocation to
+// the observer, when the client is authenticated as them. TrueLocation
+// returns PrivateLocation if it's set, and otherwise Location.
+func (r Result) TrueLocation() string {
+	if r.PrivateLocation != "" {
+		return r.PrivateLocation
+	}
+	if g := r.PrivateGeojson; g != nil && len(g.Coordinates) == 2 {
+		return fmt.Sprintf("%v,%v", g.Coordinates[1], g.Coordinates[0])
+	}
+	return r.Location
+}
+
 func (r Result) URLWithSpecies() string {
 	return fmt.Sprintf("%s [%s] (%s)", r.URL(), r.Taxon.Name, r.PreferredCommonName)
 }
@@ -129,6 +157,39 @@ func (r Result) ObservationFieldValue(fieldID int) string {
 	return ""
 }
 
+// Identification is someone's identification of an observation.
+type Identification struct {
+	Body      string      `json:"body,omitempty"`     // the identifier's remarks
+	Category  string      `json:"category,omitempty"` // like "improving", "supporting", or "maverick"
+	CreatedAt string      `json:"created_at,omitempty"`
+	Current   bool        `json:"current,omitempty"` // false if withdrawn or replaced
+	ID        int         `json:"id,omitempty"`
+	Taxon     Taxon       `json:"taxon,omitempty"`
+	User      Participant `json:"user,omitempty"`
+	UUID      uuid.UUID   `json:"uuid,omitempty"`
+}
+
+// Comment is a comment on an observation.
+type Comment struct {
+	Body      string      `json:"body,omitempty"`
+	CreatedAt string      `json:"created_at,omitempty"`
+	ID        int         `json:"id,omitempty"`
+	User      Participant `json:"user,omitempty"`
+	UUID      uuid.UUID   `json:"uuid,omitempty"`
+}
+
+// Participant is the user who made an identification or comment.
+type Participant struct {
+	ID    int    `json:"id,omitempty"`
+	Login string `json:"login,omitempty"`
+}
+
+// GeoJSON is a GeoJSON point.
+type GeoJSON struct {
+	Type        string    `json:"type,omitempty"`        // "Point"
+	Coordinates []float64 `json:"coordinates,omitempty"` // longitude, latitude
+}
+
 type Ofv struct {
 	FieldID int    `json:"field_id,omitempty"`
 	ID      int    `json:"id,omitempty"`
@@ -153,8 +214,11 @@ type Sound struct {
 }
 
 type Taxon struct {
-	IconicTaxonName     string `json:"iconic_taxon_name,omitempty"`
-	ID                  int    `json:"id,omitempty"`
-	Name                string `json:"name,omitempty"`
-	PreferredCommonName string `json:"preferred_common_name,omitempty"`
+	AncestorIDs         []int   `json:"ancestor_ids,omitempty"`
+	Ancestors           []Taxon `json:"ancestors,omitempty"`
+	IconicTaxonName     string  `json:"iconic_taxon_name,omitempty"`
+	ID                  int     `json:"id,omitempty"`
+	Name                string  `json:"name,omitempty"`
+	PreferredCommonName string  `json:"preferred_common_name,omitempty"`
+	Rank                string  `json:"rank,omitempty"` // "species", "genus", etc.
 }
@@ -0,0 +1,52 @@
+package inat
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidObservation is returned (wrapped) by CreateObservation and
+// UpdateObservation for observations that fail Validate. They don't
+// send invalid observations to iNaturalist, which would reject them
+// with a less helpful error or, worse, store nonsense.
+var ErrInvalidObservation = errors.New("invalid observation")
+
+// geoprivacies are the values of Observation.Geoprivacy that iNaturalist accepts.
+var geoprivacies = []string{"", "open", "obscured", "private"}
+
+// Validate checks the fields of o that are set. Every observation needs
+// a UUID, which birdsync uses to find it again; coordinates must be on
+// Earth; positional accuracy can't be negative; geoprivacy must be open,
+// obscured, or private; and observation field values need a field ID.
+// The error wraps ErrInvalidObservation and lists every problem.
+func (o Observation) Validate() error {
+	var problems []string
+	if o.UUID == uuid.Nil {
+		problems = append(problems, "missing UUID")
+	}
+	if o.Latitude < -90 || o.Latitude > 90 {
+		problems = append(problems, fmt.Sprintf("latitude %v is beyond ±90", o.Latitude))
+	}
+	if o.Longitude < -180 || o.Longitude > 180 {
+		problems = append(problems, fmt.Sprintf("longitude %v is beyond ±180", o.Longitude))
+	}
+	if o.PositionalAccuracy < 0 {
+		problems = append(problems, fmt.Sprintf("negative positional accuracy %v", o.PositionalAccuracy))
+	}
+	if !slices.Contains(geoprivacies, o.Geoprivacy) {
+		problems = append(problems, fmt.Sprintf("unknown geoprivacy %q", o.Geoprivacy))
+	}
+	for _, ofv := range o.ObservationFieldValuesAttributes {
+		if ofv.ObservationFieldID <= 0 {
+			problems = append(problems, fmt.Sprintf("bad observation field ID %d for value %v", ofv.ObservationFieldID, ofv.Value))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w %s: %s", ErrInvalidObservation, o.UUID, strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/Sajmani/birdsync/ebird"
@@ -67,13 +69,13 @@ func (set *mlAssetSet) Add(id string) {
 	}
 }
 
+// eBirdMLAssets returns the valid ML asset IDs in an eBird record's
+// ML Catalog Numbers. See ebird.Record.MLAssetIDs.
 func eBirdMLAssets(mlAssets string) mlAssetSet {
 	var set mlAssetSet
-	if mlAssets == "" {
-		return set
-	}
-	for _, id := range strings.Split(mlAssets, " ") {
-		set.Add(strings.TrimSpace(id))
+	ids, _ := ebird.Record{MLCatalogNumbers: mlAssets}.MLAssetIDs()
+	for _, id := range ids {
+		set.Add(string(id))
 	}
 	return set
 }
@@ -102,3 +104,64 @@ func mlAssetDiff(a, b mlAssetSet) mlAssetSet {
 	}
 	return diff
 }
+
+// Media orders for --media_order. iNaturalist shows an observation's first
+// photo as its cover photo, and birdsync uploads photos in this order.
+// The eBird export doesn't include Macaulay Library ratings,
+// so birdsync can't put the highest-rated photo first.
+const (
+	mediaOrderEBird   = "ebird"   // the order of the ML Catalog Numbers in eBird
+	mediaOrderReverse = "reverse" // the reverse of the eBird order
+	mediaOrderID      = "id"      // ascending ML asset ID, which is the order they were uploaded to eBird
+)
+
+func validMediaOrder(order string) bool {
+	return order == mediaOrderEBird || order == mediaOrderReverse || order == mediaOrderID
+}
+
+// orderMLAssets returns the assets in set in the given media order.
+func orderMLAssets(set mlAssetSet, order string) mlAssetSet {
+	ids := slices.Clone(set.ids)
+	switch order {
+	case mediaOrderReverse:
+		slices.Reverse(ids)
+	case mediaOrderID:
+		slices.SortStableFunc(ids, func(a, b string) int {
+			ai, aerr := strconv.Atoi(a)
+			bi, berr := strconv.Atoi(b)
+			if aerr != nil || berr != nil {
+				return strings.Compare(a, b)
+			}
+			return ai - bi
+		})
+	}
+	return mlAssetSet{ids: ids}
+}
+
+// defaultMaxMedia is the default --max_media. iNaturalist doesn't document
+// a limit, but uploads to observations with many more media than this fail.
+const defaultMaxMedia = 20
+
+// defaultMLWorkers is the default for --ml_workers. It's small to be
+// polite to the Macaulay Library, which serves large files.
+const defaultMLWorkers = 4
+
+// capMLAssets splits set into its first n assets and the rest.
+func capMLAssets(set mlAssetSet, n int) (kept, omitted mlAssetSet) {
+	n = max(n, 0)
+	if n >= set.Len() {
+		return set, mlAssetSet{}
+	}
+	return mlAssetSet{ids: set.ids[:n]}, mlAssetSet{ids: set.ids[n:]}
+}
+
+// omittedNote returns a description line listing the omitted ML assets.
+// It names them "ML123" rather than by URL so that iNatMLAssets doesn't
+// mistake them for uploaded assets.
+func omittedNote(omitted mlAssetSet) string {
+	var names []string
+	for _, id := range omitted.ids {
+		names = append(names, "ML"+id)
+	}
+	return fmt.Sprintf("Macaulay Library Assets not uploaded (too many media): %s\n", strings.Join(names, " "))
+}
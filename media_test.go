@@ -94,3 +94,25 @@ func TestMediaChange(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderMLAssets(t *testing.T) {
+	set := eBirdMLAssets("300 100 20")
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{mediaOrderEBird, []string{"300", "100", "20"}},
+		{mediaOrderReverse, []string{"20", "100", "300"}},
+		{mediaOrderID, []string{"20", "100", "300"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			if got := orderMLAssets(set, tt.order); !slices.Equal(got.ids, tt.want) {
+				t.Errorf("orderMLAssets(%q) = %v, want %v", tt.order, got.ids, tt.want)
+			}
+		})
+	}
+	if !slices.Equal(set.ids, []string{"300", "100", "20"}) {
+		t.Errorf("orderMLAssets modified its input: %v", set.ids)
+	}
+}
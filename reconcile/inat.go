@@ -0,0 +1,65 @@
+package reconcile
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Sajmani/birdsync/ebird"
+	"github.com/Sajmani/birdsync/inat"
+)
+
+// FromINatResult returns the iNaturalist observation r in the shape of an
+// ebird.Record, so that reconciliation and export tools can work on eBird
+// and iNaturalist observations uniformly.
+//
+// SubmissionID, ScientificName, CommonName, Count, Location, County,
+// StateProvince, and NumberOfObservers come from the observation fields
+// that birdsync sets. If those are missing, as they are for observations
+// not created by birdsync, ScientificName and CommonName come from
+// the observation's taxon. Date and Time come from when the bird was
+// observed, Latitude and Longitude from the observation's TrueLocation,
+// and ObservationDetails from its description.
+//
+// iNaturalist has no equivalent of Line, TaxonomicOrder, LocationID,
+// Protocol, the effort fields, BreedingCode, ChecklistComments, or
+// MLCatalogNumbers, so those are left blank.
+func FromINatResult(r inat.Result) ebird.Record {
+	rec := ebird.Record{
+		SubmissionID:       r.ObservationFieldValue(inat.EBirdField),
+		ScientificName:     r.ObservationFieldValue(inat.EBirdScientificNameField),
+		CommonName:         r.ObservationFieldValue(inat.CommonNameField),
+		Count:              r.ObservationFieldValue(inat.CountField),
+		StateProvince:      r.ObservationFieldValue(inat.StateOrProvinceField),
+		County:             r.ObservationFieldValue(inat.CountyField),
+		Location:           r.ObservationFieldValue(inat.LocationField),
+		NumberOfObservers:  r.ObservationFieldValue(inat.NumObserversField),
+		Date:               r.ObservedOn,
+		ObservationDetails: r.Description,
+	}
+	if rec.ScientificName == "" {
+		rec.ScientificName = r.Taxon.Name
+	}
+	if rec.CommonName == "" {
+		rec.CommonName = r.Taxon.PreferredCommonName
+	}
+	if lat, lng, ok := strings.Cut(r.TrueLocation(), ","); ok {
+		rec.Latitude, rec.Longitude = lat, lng
+	}
+	// time_observed_at includes the observation's time zone offset,
+	// so formatting it gives the local time like eBird does.
+	if t, err := time.Parse(time.RFC3339, r.TimeObservedAt); err == nil {
+		rec.Time = t.Format("03:04 PM")
+	}
+	return rec
+}
+
+// ResultObservationID returns the ID of the eBird observation that
+// birdsync synced to the iNaturalist observation r, from the eBird
+// observation fields birdsync sets. The ID isn't Valid if r wasn't
+// created by birdsync.
+func ResultObservationID(r inat.Result) ebird.ObservationID {
+	return ebird.ObservationID{
+		SubmissionID:   ebird.CanonicalSubmissionID(r.ObservationFieldValue(inat.EBirdField)),
+		ScientificName: r.ObservationFieldValue(inat.EBirdScientificNameField),
+	}
+}
@@ -0,0 +1,83 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/Sajmani/birdsync/ebird"
+	"github.com/Sajmani/birdsync/inat"
+)
+
+func TestFromINatResult(t *testing.T) {
+	testCases := []struct {
+		name string
+		r    inat.Result
+		want ebird.Record
+	}{
+		{
+			name: "birdsync observation",
+			r: inat.Result{
+				ObservedOn:     "2023-01-02",
+				TimeObservedAt: "2023-01-02T15:04:00-08:00",
+				Location:       "37.123,-122.123",
+				Description:    "Perched on a wire",
+				Taxon:          inat.Taxon{Name: "Turdus migratorius", PreferredCommonName: "American Robin"},
+				Ofvs: []inat.Ofv{
+					{FieldID: inat.EBirdField, Value: "S123"},
+					{FieldID: inat.EBirdScientificNameField, Value: "Turdus migratorius/rufopalliatus"},
+					{FieldID: inat.CommonNameField, Value: "American/Rufous-backed Robin"},
+					{FieldID: inat.CountField, Value: "X"},
+					{FieldID: inat.StateOrProvinceField, Value: "US-CA"},
+				},
+			},
+			want: ebird.Record{
+				SubmissionID:       "S123",
+				ScientificName:     "Turdus migratorius/rufopalliatus",
+				CommonName:         "American/Rufous-backed Robin",
+				Count:              "X",
+				StateProvince:      "US-CA",
+				Latitude:           "37.123",
+				Longitude:          "-122.123",
+				Date:               "2023-01-02",
+				Time:               "03:04 PM",
+				ObservationDetails: "Perched on a wire",
+			},
+		},
+		{
+			name: "obscured observation",
+			r: inat.Result{
+				ObservedOn:      "2023-01-02",
+				Geoprivacy:      "obscured",
+				Obscured:        true,
+				Location:        "37.2,-122.2",
+				PrivateLocation: "37.123,-122.123",
+				Taxon:           inat.Taxon{Name: "Turdus migratorius", PreferredCommonName: "American Robin"},
+			},
+			want: ebird.Record{
+				ScientificName: "Turdus migratorius",
+				CommonName:     "American Robin",
+				Latitude:       "37.123",
+				Longitude:      "-122.123",
+				Date:           "2023-01-02",
+			},
+		},
+		{
+			name: "manual observation",
+			r: inat.Result{
+				ObservedOn: "2023-01-02",
+				Taxon:      inat.Taxon{Name: "Turdus migratorius", PreferredCommonName: "American Robin"},
+			},
+			want: ebird.Record{
+				ScientificName: "Turdus migratorius",
+				CommonName:     "American Robin",
+				Date:           "2023-01-02",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := FromINatResult(tc.r); got != tc.want {
+				t.Errorf("FromINatResult() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
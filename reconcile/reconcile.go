@@ -0,0 +1,142 @@
+// Package reconcile relates eBird records to the iNaturalist observations
+// that birdsync created from them, using the eBird observation fields that
+// birdsync sets. It's separate from the ebird and inat packages so that
+// neither depends on the other.
+package reconcile
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+
+	"github.com/Sajmani/birdsync/ebird"
+	"github.com/Sajmani/birdsync/inat"
+)
+
+// Orphans returns the iNaturalist observations in existing that don't
+// correspond to any of records: observations created by birdsync from
+// checklists or species since deleted from eBird, and observations not
+// created by birdsync at all, such as ones added by hand. Observations
+// are matched to records by their eBird observation fields, as in
+// ResultObservationID. Orphans aren't necessarily mistakes; they're for
+// users to review.
+func Orphans(records iter.Seq[ebird.Record], existing []inat.Result) []inat.Result {
+	ids := map[ebird.ObservationID]bool{}
+	for rec := range records {
+		ids[rec.ObservationID()] = true
+	}
+	var orphans []inat.Result
+	for _, r := range existing {
+		if id := ResultObservationID(r); !id.Valid() || !ids[id] {
+			orphans = append(orphans, r)
+		}
+	}
+	return orphans
+}
+
+// MatchKind describes how an eBird record and an iNaturalist
+// observation correspond.
+type MatchKind int
+
+const (
+	MatchSynced MatchKind = iota // the record was synced to the observation
+	MatchNew                     // the record hasn't been synced
+	MatchOrphan                  // no record corresponds to the observation
+)
+
+func (k MatchKind) String() string {
+	switch k {
+	case MatchSynced:
+		return "synced"
+	case MatchNew:
+		return "new"
+	case MatchOrphan:
+		return "orphan"
+	}
+	return "unknown"
+}
+
+// A Match is an eBird record, an iNaturalist observation, or both,
+// as produced by Join.
+type Match struct {
+	Kind   MatchKind
+	Record ebird.Record // zero for MatchOrphan
+	Result inat.Result  // zero for MatchNew
+}
+
+// compareIDs orders observation IDs by submission ID, then scientific name.
+func compareIDs(a, b ebird.ObservationID) int {
+	return cmp.Or(
+		cmp.Compare(a.SubmissionID, b.SubmissionID),
+		cmp.Compare(a.ScientificName, b.ScientificName),
+	)
+}
+
+// SortRecords sorts records into the order Join requires: by
+// ObservationID, comparing submission IDs and then scientific names
+// as strings.
+func SortRecords(records []ebird.Record) {
+	slices.SortStableFunc(records, func(a, b ebird.Record) int {
+		return compareIDs(a.ObservationID(), b.ObservationID())
+	})
+}
+
+// SortResults sorts results into the order Join requires: by
+// ResultObservationID, like SortRecords. Observations not created by
+// birdsync sort first.
+func SortResults(results []inat.Result) {
+	slices.SortStableFunc(results, func(a, b inat.Result) int {
+		return compareIDs(ResultObservationID(a), ResultObservationID(b))
+	})
+}
+
+// Join matches eBird records to the existing iNaturalist observations
+// that birdsync created from them, reading each sequence once and holding
+// only one element of each in memory. It yields a MatchSynced value for
+// each record that has an observation, MatchNew for each record that
+// doesn't, and MatchOrphan for each observation that has no record (see
+// Orphans).
+//
+// Both sequences must be sorted by observation ID, as by SortRecords and
+// SortResults. Each observation matches at most one record; duplicate
+// records after the first are MatchNew, and duplicate observations after
+// the first are MatchOrphan.
+// Join's output is meaningless if the inputs aren't sorted.
+func Join(records iter.Seq[ebird.Record], existing iter.Seq[inat.Result]) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		nextRec, stopRec := iter.Pull(records)
+		defer stopRec()
+		nextRes, stopRes := iter.Pull(existing)
+		defer stopRes()
+
+		rec, recOK := nextRec()
+		res, resOK := nextRes()
+		for recOK || resOK {
+			c := 0
+			switch {
+			case !resOK:
+				c = -1
+			case !recOK:
+				c = 1
+			default:
+				c = compareIDs(rec.ObservationID(), ResultObservationID(res))
+			}
+			var m Match
+			switch {
+			case c < 0:
+				m = Match{Kind: MatchNew, Record: rec}
+				rec, recOK = nextRec()
+			case c > 0:
+				m = Match{Kind: MatchOrphan, Result: res}
+				res, resOK = nextRes()
+			default:
+				m = Match{Kind: MatchSynced, Record: rec, Result: res}
+				rec, recOK = nextRec()
+				res, resOK = nextRes()
+			}
+			if !yield(m) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package reconcile
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Sajmani/birdsync/ebird"
+	"github.com/Sajmani/birdsync/inat"
+)
+
+func TestOrphans(t *testing.T) {
+	synced := func(id int, submissionID, name string) inat.Result {
+		return inat.Result{ID: id, Ofvs: []inat.Ofv{
+			{FieldID: inat.EBirdField, Value: submissionID},
+			{FieldID: inat.EBirdScientificNameField, Value: name},
+		}}
+	}
+	records := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius"},
+		{SubmissionID: "S1", ScientificName: "Corvus brachyrhynchos"},
+	}
+	existing := []inat.Result{
+		synced(1, "S1", "Turdus migratorius"),
+		synced(2, "S1.2", "Corvus brachyrhynchos"),             // revised checklist
+		synced(3, "S1", "Zenaida macroura"),                    // species removed from checklist
+		synced(4, "S2", "Turdus migratorius"),                  // checklist deleted
+		{ID: 5, Taxon: inat.Taxon{Name: "Turdus migratorius"}}, // added by hand
+	}
+	var got []int
+	for _, r := range Orphans(slices.Values(records), existing) {
+		got = append(got, r.ID)
+	}
+	if want := []int{3, 4, 5}; !slices.Equal(got, want) {
+		t.Errorf("Orphans() = %v, want %v", got, want)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	synced := func(id int, submissionID, name string) inat.Result {
+		return inat.Result{ID: id, Ofvs: []inat.Ofv{
+			{FieldID: inat.EBirdField, Value: submissionID},
+			{FieldID: inat.EBirdScientificNameField, Value: name},
+		}}
+	}
+	records := []ebird.Record{
+		{Line: 4, SubmissionID: "S2", ScientificName: "Turdus migratorius"},
+		{Line: 2, SubmissionID: "S1", ScientificName: "Turdus migratorius"},
+		{Line: 3, SubmissionID: "S1", ScientificName: "Corvus brachyrhynchos"},
+	}
+	existing := []inat.Result{
+		synced(10, "S1", "Turdus migratorius"),
+		synced(11, "S3", "Turdus migratorius"),
+		{ID: 12}, // added by hand
+		synced(13, "S1", "Corvus brachyrhynchos"),
+	}
+	SortRecords(records)
+	SortResults(existing)
+
+	var got []string
+	for m := range Join(slices.Values(records), slices.Values(existing)) {
+		got = append(got, fmt.Sprintf("%s:%d:%d", m.Kind, m.Record.Line, m.Result.ID))
+	}
+	want := []string{
+		"orphan:0:12",
+		"synced:3:13",
+		"synced:2:10",
+		"new:4:0",
+		"orphan:0:11",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Join() = %v, want %v", got, want)
+	}
+
+	// Stopping early is fine.
+	for range Join(slices.Values(records), slices.Values(existing)) {
+		break
+	}
+}
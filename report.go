@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"os"
+	"strings"
+
+	"github.com/Sajmani/birdsync/ebird"
+)
+
+// stats summarizes the outcome of a birdsync run.
+type stats struct {
+	afterSkips, beforeSkips, verifiableSkips, previouslySkips, fuzzySkips int
+	incompleteSkips, sharedSkips                                          int
+	totalRecords, createdObservations, updatedObservations                int
+	uploadedPhotos, uploadedSounds, skippedMedia                          int
+	linkedVideos                                                          int
+	failures                                                              []failure
+	unresolved                                                            []string // eBird scientific names
+	nameMismatches                                                        []nameMismatch
+	divergedIDs                                                           []divergedID
+}
+
+// divergedID records a synced iNaturalist observation whose community
+// taxon differs from the observation's own taxon.
+type divergedID struct {
+	id            ebird.ObservationID
+	url           string // of the iNaturalist observation
+	taxonName     string // the observation's scientific name
+	communityName string // scientific name
+}
+
+// nameMismatch records an eBird observation whose common name
+// doesn't match iNaturalist's name for its scientific name.
+type nameMismatch struct {
+	id                  ebird.ObservationID
+	eBirdName, iNatName string
+}
+
+// failure records an eBird observation that birdsync failed to sync.
+type failure struct {
+	id  ebird.ObservationID
+	err error
+}
+
+func (s *stats) fail(id ebird.ObservationID, err error) {
+	s.failures = append(s.failures, failure{id, err})
+}
+
+// skips returns the number of skipped eBird observations for each reason.
+// The order is fixed so that reports are stable across runs.
+func (s stats) skips() []skipCount {
+	return []skipCount{
+		{"previously uploaded by birdsync", "previously_uploaded", s.previouslySkips},
+		{"matched with --fuzzy", "fuzzy", s.fuzzySkips},
+		{"copies of shared checklists", "shared", s.sharedSkips},
+		{"observed before --after", "after", s.afterSkips},
+		{"observed after --before", "before", s.beforeSkips},
+		{"unverifiable (no photos or sounds)", "unverifiable", s.verifiableSkips},
+		{"from incomplete checklists", "incomplete", s.incompleteSkips},
+	}
+}
+
+type skipCount struct {
+	reason string // human-readable
+	key    string // machine-readable
+	count  int
+}
+
+// report returns a human-readable summary of s suitable for a terminal or log.
+func (s stats) report() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Processed %d eBird observations\n", s.totalRecords)
+	fmt.Fprintf(&b, "Created %d new iNaturalist observations\n", s.createdObservations)
+	fmt.Fprintf(&b, "Updated %d iNaturalist observations\n", s.updatedObservations)
+	skipped := 0
+	for _, sc := range s.skips() {
+		skipped += sc.count
+	}
+	fmt.Fprintf(&b, "Skipped %d eBird observations\n", skipped)
+	for _, sc := range s.skips() {
+		if sc.count > 0 {
+			fmt.Fprintf(&b, "  %d %s\n", sc.count, sc.reason)
+		}
+	}
+	fmt.Fprintf(&b, "Failed %d eBird observations\n", len(s.failures))
+	for _, f := range s.failures {
+		fmt.Fprintf(&b, "  %s: %v\n", f.id, f.err)
+	}
+	fmt.Fprintf(&b, "Uploaded %d photos and %d sounds to iNaturalist\n", s.uploadedPhotos, s.uploadedSounds)
+	if len(s.nameMismatches) > 0 {
+		fmt.Fprintf(&b, "Found %d eBird observations with unexpected common names (--check_names)\n", len(s.nameMismatches))
+		for _, m := range s.nameMismatches {
+			fmt.Fprintf(&b, "  %s: eBird says %q, iNaturalist says %q\n", m.id, m.eBirdName, m.iNatName)
+		}
+	}
+	if len(s.divergedIDs) > 0 {
+		fmt.Fprintf(&b, "Found %d iNaturalist observations whose community ID differs from the observation's (--check_ids)\n", len(s.divergedIDs))
+		for _, d := range s.divergedIDs {
+			fmt.Fprintf(&b, "  %s: observed as %q, iNaturalist community says %q at %s\n", d.id, d.taxonName, d.communityName, d.url)
+		}
+	}
+	if s.skippedMedia > 0 {
+		fmt.Fprintf(&b, "Didn't upload %d photos and sounds over --max_media\n", s.skippedMedia)
+	}
+	if s.linkedVideos > 0 {
+		fmt.Fprintf(&b, "Linked %d videos in descriptions, since iNaturalist doesn't accept videos\n", s.linkedVideos)
+	}
+	if len(s.unresolved) > 0 {
+		fmt.Fprintf(&b, "%d eBird species have no iNaturalist taxon; fix them on iNaturalist (see --unresolved)\n", len(s.unresolved))
+	}
+	return b.String()
+}
+
+// statsJSON is the machine-readable form of stats.
+// Keep the field names stable: other tools read these reports.
+type statsJSON struct {
+	TotalRecords        int            `json:"total_records"`
+	CreatedObservations int            `json:"created_observations"`
+	UpdatedObservations int            `json:"updated_observations"`
+	Skipped             map[string]int `json:"skipped"`
+	Failed              []failureJSON  `json:"failed"`
+	UploadedPhotos      int            `json:"uploaded_photos"`
+	UploadedSounds      int            `json:"uploaded_sounds"`
+	SkippedMedia        int            `json:"skipped_media"`
+	LinkedVideos        int            `json:"linked_videos"`
+}
+
+type failureJSON struct {
+	SubmissionID   string `json:"submission_id"`
+	ScientificName string `json:"scientific_name"`
+	Error          string `json:"error"`
+}
+
+func (s stats) MarshalJSON() ([]byte, error) {
+	j := statsJSON{
+		TotalRecords:        s.totalRecords,
+		CreatedObservations: s.createdObservations,
+		UpdatedObservations: s.updatedObservations,
+		Skipped:             map[string]int{},
+		Failed:              []failureJSON{},
+		UploadedPhotos:      s.uploadedPhotos,
+		UploadedSounds:      s.uploadedSounds,
+		SkippedMedia:        s.skippedMedia,
+		LinkedVideos:        s.linkedVideos,
+	}
+	for _, sc := range s.skips() {
+		j.Skipped[sc.key] = sc.count
+	}
+	for _, f := range s.failures {
+		j.Failed = append(j.Failed, failureJSON{
+			SubmissionID:   f.id.SubmissionID,
+			ScientificName: f.id.ScientificName,
+			Error:          f.err.Error(),
+		})
+	}
+	return json.Marshal(j)
+}
+
+// failedIDs returns the IDs of the failed eBird observations
+// in the JSON report written by --report.
+func failedIDs(filename string) (map[ebird.ObservationID]bool, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var j statsJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	ids := map[ebird.ObservationID]bool{}
+	for _, f := range j.Failed {
+		ids[ebird.ObservationID{SubmissionID: ebird.CanonicalSubmissionID(f.SubmissionID), ScientificName: f.ScientificName}] = true
+	}
+	return ids, nil
+}
+
+// onlyRecords returns the records whose observation IDs are in ids.
+func onlyRecords(records iter.Seq[ebird.Record], ids map[ebird.ObservationID]bool) iter.Seq[ebird.Record] {
+	return ebird.Filter(records, func(rec ebird.Record) bool {
+		return ids[rec.ObservationID()]
+	})
+}
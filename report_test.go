@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sajmani/birdsync/ebird"
+)
+
+func TestStatsReport(t *testing.T) {
+	s := stats{
+		totalRecords:        5,
+		createdObservations: 1,
+		updatedObservations: 2,
+		previouslySkips:     1,
+		fuzzySkips:          2,
+		uploadedPhotos:      3,
+		uploadedSounds:      1,
+	}
+	s.fail(ebird.ObservationID{SubmissionID: "S123", ScientificName: "Turdus migratorius"}, errors.New("bad HTTP status: 500"))
+
+	want := `Processed 5 eBird observations
+Created 1 new iNaturalist observations
+Updated 2 iNaturalist observations
+Skipped 3 eBird observations
+  1 previously uploaded by birdsync
+  2 matched with --fuzzy
+Failed 1 eBird observations
+  S123[Turdus migratorius]: bad HTTP status: 500
+Uploaded 3 photos and 1 sounds to iNaturalist
+`
+	if got := s.report(); got != want {
+		t.Errorf("report() = %q, want %q", got, want)
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	s := stats{totalRecords: 2, verifiableSkips: 1}
+	s.fail(ebird.ObservationID{SubmissionID: "S123", ScientificName: "Turdus migratorius"}, errors.New("oops"))
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got statsJSON
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalRecords != 2 {
+		t.Errorf("TotalRecords = %d, want 2", got.TotalRecords)
+	}
+	if got.Skipped["unverifiable"] != 1 {
+		t.Errorf("Skipped[unverifiable] = %d, want 1", got.Skipped["unverifiable"])
+	}
+	if len(got.Failed) != 1 || got.Failed[0].SubmissionID != "S123" || got.Failed[0].Error != "oops" {
+		t.Errorf("Failed = %+v, want one failure for S123", got.Failed)
+	}
+}
+
+func TestRetryFailed(t *testing.T) {
+	defer func() { retryIDs = nil }()
+	var prev stats
+	prev.fail(ebird.ObservationID{SubmissionID: "S2", ScientificName: "Turdus migratorius"}, errors.New("bad HTTP status: 500"))
+	b, err := json.Marshal(prev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(report, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	retryIDs, err = failedIDs(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ebirdRecords := []ebird.Record{
+		{SubmissionID: "S1", ScientificName: "Turdus migratorius", Date: "2023-01-03"},
+		{SubmissionID: "S2", ScientificName: "Turdus migratorius", Date: "2023-01-04"},
+		{SubmissionID: "S2", ScientificName: "Corvus brachyrhynchos", Date: "2023-01-04"},
+	}
+	after, before = dateTimeFlag{}, dateTimeFlag{}
+	verifiable = false
+	fuzzy = false
+
+	mockInat := &mockINatClient{userID: "testuser"}
+	s := birdsync("MyEBirdData.csv", &mockEBirdClient{records: ebirdRecords}, "myUserID", mockInat)
+	if s.totalRecords != 1 || s.createdObservations != 1 {
+		t.Errorf("Expected 1 record and 1 created observation, got %d and %d", s.totalRecords, s.createdObservations)
+	}
+	if len(mockInat.created) == 1 && mockInat.created[0].SpeciesGuess != "Turdus migratorius" {
+		t.Errorf("Created %s, want Turdus migratorius", mockInat.created[0].SpeciesGuess)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Sajmani/birdsync/ebird"
+	"github.com/Sajmani/birdsync/inat"
+)
+
+// sanityCheckExport guards against syncing someone else's eBird export.
+// It compares the dates and approximate locations of the eBird records
+// with those of the user's existing iNaturalist observations and
+// returns a warning if they have nothing in common. It returns ""
+// if either side is empty, since a new iNaturalist user has nothing
+// to compare against.
+func sanityCheckExport(records iter.Seq[ebird.Record], results []inat.Result) string {
+	inatDates := map[string]bool{}
+	inatPlaces := map[string]bool{}
+	for _, r := range results {
+		if r.ObservedOn != "" {
+			inatDates[r.ObservedOn] = true
+		}
+		if lat, lng, ok := strings.Cut(r.TrueLocation(), ","); ok {
+			if place, ok := roundedPlace(lat, lng); ok {
+				inatPlaces[place] = true
+			}
+		}
+	}
+	if len(inatDates) == 0 && len(inatPlaces) == 0 {
+		return ""
+	}
+	n := 0
+	for rec := range records {
+		n++
+		if observed, err := rec.Observed(); err == nil && inatDates[observed.Format(time.DateOnly)] {
+			return ""
+		}
+		if place, ok := roundedPlace(rec.Latitude, rec.Longitude); ok && inatPlaces[place] {
+			return ""
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("none of the %d eBird observations share a date or location with your %d iNaturalist observations; "+
+		"check that this is your eBird export", n, len(results))
+}
+
+// roundedPlace returns the coordinates rounded to about 10km,
+// which is coarse enough to match nearby observations.
+func roundedPlace(lat, lng string) (string, bool) {
+	latf, err1 := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	lngf, err2 := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	if err1 != nil || err2 != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%.1f,%.1f", latf, lngf), true
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Sajmani/birdsync/ebird"
+	"github.com/Sajmani/birdsync/inat"
+)
+
+func TestSanityCheckExport(t *testing.T) {
+	records := []ebird.Record{
+		{Date: "2023-01-02", Latitude: "37.123", Longitude: "-122.123"},
+		{Date: "1/3/2023", Latitude: "37.5", Longitude: "-122.5"},
+	}
+	testCases := []struct {
+		name        string
+		results     []inat.Result
+		wantWarning bool
+	}{
+		{
+			name:    "no iNaturalist observations",
+			results: nil,
+		},
+		{
+			name:    "same date",
+			results: []inat.Result{{ObservedOn: "2023-01-03", Location: "10,10"}},
+		},
+		{
+			name:    "nearby location",
+			results: []inat.Result{{ObservedOn: "2020-05-05", Location: "37.14,-122.09"}},
+		},
+		{
+			name:    "nearby private location",
+			results: []inat.Result{{ObservedOn: "2020-05-05", Location: "37.2,-122.9", PrivateLocation: "37.14,-122.09"}},
+		},
+		{
+			name: "no overlap",
+			results: []inat.Result{
+				{ObservedOn: "2020-05-05", Location: "51.5,-0.12"},
+				{ObservedOn: "2020-05-06", Location: "51.5,-0.12"},
+			},
+			wantWarning: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := sanityCheckExport(slices.Values(records), tc.results)
+			if (got != "") != tc.wantWarning {
+				t.Errorf("sanityCheckExport() = %q, want warning %v", got, tc.wantWarning)
+			}
+		})
+	}
+}
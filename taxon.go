@@ -0,0 +1,40 @@
+package main
+
+import "github.com/Sajmani/birdsync/inat"
+
+// Subspecies strategies for --subspecies. They apply to species-level
+// eBird records in places where iNaturalist has subspecies taxa.
+const (
+	subspeciesSpecies    = "species"    // use the species taxon
+	subspeciesSubspecies = "subspecies" // use the subspecies taxon if there's only one
+)
+
+func validSubspecies(strategy string) bool {
+	return strategy == subspeciesSpecies || strategy == subspeciesSubspecies
+}
+
+// chooseTaxon chooses the taxon for a species-level eBird record from
+// matches, the result of inat.Client.MatchTaxon for its scientific name,
+// using the given --subspecies strategy. It reports false if the record
+// isn't species-level on iNaturalist, in which case birdsync leaves
+// the choice of taxon to iNaturalist. With the subspecies strategy,
+// chooseTaxon uses the subspecies only if it's unambiguous.
+func chooseTaxon(matches []inat.Taxon, strategy string) (inat.Taxon, bool) {
+	if len(matches) == 0 || matches[0].Rank != "species" {
+		return inat.Taxon{}, false
+	}
+	species := matches[0]
+	if strategy != subspeciesSubspecies {
+		return species, true
+	}
+	var subspecies []inat.Taxon
+	for _, t := range matches[1:] {
+		if t.Rank == "subspecies" {
+			subspecies = append(subspecies, t)
+		}
+	}
+	if len(subspecies) == 1 {
+		return subspecies[0], true
+	}
+	return species, true
+}
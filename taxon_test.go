@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Sajmani/birdsync/inat"
+)
+
+func TestChooseTaxon(t *testing.T) {
+	species := inat.Taxon{ID: 1, Name: "Junco hyemalis", Rank: "species"}
+	oreganus := inat.Taxon{ID: 2, Name: "Junco hyemalis oreganus", Rank: "subspecies"}
+	hyemalis := inat.Taxon{ID: 3, Name: "Junco hyemalis hyemalis", Rank: "subspecies"}
+	genus := inat.Taxon{ID: 4, Name: "Junco", Rank: "genus"}
+	testCases := []struct {
+		name     string
+		matches  []inat.Taxon
+		strategy string
+		wantID   int
+		wantOK   bool
+	}{
+		{"species strategy", []inat.Taxon{species, oreganus}, subspeciesSpecies, 1, true},
+		{"subspecies strategy", []inat.Taxon{species, oreganus}, subspeciesSubspecies, 2, true},
+		{"ambiguous subspecies", []inat.Taxon{species, oreganus, hyemalis}, subspeciesSubspecies, 1, true},
+		{"no subspecies", []inat.Taxon{species}, subspeciesSubspecies, 1, true},
+		{"not a species", []inat.Taxon{genus}, subspeciesSubspecies, 0, false},
+		{"no matches", nil, subspeciesSpecies, 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := chooseTaxon(tc.matches, tc.strategy)
+			if got.ID != tc.wantID || ok != tc.wantOK {
+				t.Errorf("chooseTaxon() = %d, %v; want %d, %v", got.ID, ok, tc.wantID, tc.wantOK)
+			}
+		})
+	}
+}
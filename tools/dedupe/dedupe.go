@@ -20,13 +20,16 @@ func main() {
 	apiToken := inat.GetAPIToken()
 	client := inat.NewClient(inat.BaseURL, apiToken, UserAgent)
 
-	results := client.DownloadObservations(inatUserID, time.Time{}, time.Time{},
+	results, err := client.DownloadObservations(inatUserID, time.Time{}, time.Time{},
 		"created_at", "identifications_count", "ofvs.all")
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	m := map[ebird.ObservationID][]inat.Result{}
 	for _, r := range results {
 		key := ebird.ObservationID{
-			SubmissionID:   r.ObservationFieldValue(inat.EBirdField),
+			SubmissionID:   ebird.CanonicalSubmissionID(r.ObservationFieldValue(inat.EBirdField)),
 			ScientificName: r.ObservationFieldValue(inat.EBirdScientificNameField),
 		}
 		if !key.Valid() {
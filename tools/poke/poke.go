@@ -2,6 +2,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 
@@ -33,11 +34,14 @@ func main() {
 		}
 		mlAssetID := os.Args[2]
 		obsUUID := os.Args[3]
-		filename, isPhoto, err := ebird.DownloadMLAsset(mlAssetID)
+		filename, kind, err := ebird.DownloadMLAsset(context.Background(), mlAssetID)
 		if err != nil {
 			log.Fatal(err)
 		}
-		err = c.UploadMedia(filename, isPhoto, mlAssetID, obsUUID)
+		if kind == ebird.Video {
+			log.Fatalf("ML%s is a video, which iNaturalist doesn't accept", mlAssetID)
+		}
+		err = c.UploadMedia(filename, kind == ebird.Photo, mlAssetID, obsUUID)
 		if err != nil {
 			log.Fatal(err)
 		}
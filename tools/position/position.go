@@ -19,12 +19,15 @@ func main() {
 	apiToken := inat.GetAPIToken()
 	client := inat.NewClient(inat.BaseURL, apiToken, UserAgent)
 
-	results := client.DownloadObservations(inatUserID, time.Time{}, time.Time{},
+	results, err := client.DownloadObservations(inatUserID, time.Time{}, time.Time{},
 		"ofvs.all", "positional_accuracy")
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for _, r := range results {
 		key := ebird.ObservationID{
-			SubmissionID:   r.ObservationFieldValue(inat.EBirdField),
+			SubmissionID:   ebird.CanonicalSubmissionID(r.ObservationFieldValue(inat.EBirdField)),
 			ScientificName: r.ObservationFieldValue(inat.EBirdScientificNameField),
 		}
 		if !key.Valid() {
@@ -36,7 +39,7 @@ func main() {
 		}
 		fmt.Println(r.UUID, "update", r.PositionalAccuracy, "to", ebird.PositionalAccuracy)
 		if !debug {
-			err := client.UpdateObservation(inat.Observation{
+			_, err := client.UpdateObservation(inat.Observation{
 				UUID:               r.UUID,
 				PositionalAccuracy: ebird.PositionalAccuracy,
 			})
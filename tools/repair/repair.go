@@ -12,6 +12,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"time"
@@ -33,12 +34,15 @@ func main() {
 	client := inat.NewClient(inat.BaseURL, apiToken, UserAgent)
 
 	log.Println("Reading eBird observations from", eBirdCSVFilename)
-	records, err := ebird.Records(eBirdCSVFilename)
+	records, err := ebird.Records(context.Background(), eBirdCSVFilename)
 	if err != nil {
 		log.Fatal(err)
 	}
 	checklistScientificNames := map[string]map[string]bool{}
-	for rec := range records {
+	for rec, err := range records {
+		if err != nil {
+			log.Fatal(err)
+		}
 		checklist := rec.SubmissionID
 		scientificName := rec.ScientificName
 		if checklistScientificNames[checklist] == nil {
@@ -53,8 +57,11 @@ func main() {
 	}
 
 	log.Println("Downloading observations for", inatUserID)
-	results := client.DownloadObservations(inatUserID, time.Time{}, time.Time{},
+	results, err := client.DownloadObservations(inatUserID, time.Time{}, time.Time{},
 		"taxon.name", "ofvs.all")
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	for _, r := range results {
 		ebirdChecklist := r.ObservationFieldValue(inat.EBirdField)
@@ -71,7 +78,7 @@ func main() {
 		// Check whether the observation taxon name matches any in the checklist.
 		if checklistScientificNames[ebirdChecklist][r.Taxon.Name] {
 			log.Printf("Set %s eBird sci name to obs taxon name %s", r.UUID, r.Taxon.Name)
-			err := client.UpdateObservation(inat.Observation{
+			_, err := client.UpdateObservation(inat.Observation{
 				UUID: r.UUID,
 				ObservationFieldValuesAttributes: []inat.ObservationFieldValue{{
 					ObservationFieldID: inat.EBirdScientificNameField,
@@ -87,7 +94,7 @@ func main() {
 		if checklistScientificNames[ebirdChecklist][mappedName] {
 			log.Printf("Set %s eBird sci name to mapped name %s", r.UUID, mappedName)
 
-			err := client.UpdateObservation(inat.Observation{
+			_, err := client.UpdateObservation(inat.Observation{
 				UUID: r.UUID,
 				ObservationFieldValuesAttributes: []inat.ObservationFieldValue{{
 					ObservationFieldID: inat.EBirdScientificNameField,